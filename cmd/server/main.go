@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"net/http"
 
@@ -19,6 +24,10 @@ import (
 	"github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/skycoin/skycoin-exchange/src/coin/suncoin"
 	"github.com/skycoin/skycoin-exchange/src/server"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/server/router"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
 	"github.com/skycoin/skycoin/src/cipher"
 )
 
@@ -30,6 +39,8 @@ var (
 		"exchange.main",
 		"exchange.server",
 		"exchange.account",
+		"exchange.order",
+		"exchange.coin",
 		"exchange.api",
 		"exchange.bitcoin",
 		"exchange.skycoin",
@@ -37,14 +48,23 @@ var (
 	}
 )
 
+// shutdownTimeout is the max duration of draining the requests in flight on shutdown.
+var shutdownTimeout = 30 * time.Second
+
 func registerFlags(cfg *server.Config) {
 	flag.StringVar(&cfg.Server, "server", "127.0.0.1", "server ip")
 	flag.IntVar(&cfg.Port, "port", 8080, "server listen port")
 	flag.IntVar(&cfg.BtcFee, "btc-fee", 10000, "transaction fee in satoish")
 	flag.StringVar(&cfg.DataDir, "data-dir", ".skycoin-exchange", "data directory")
 	flag.StringVar(&cfg.Seed, "seed", "", "wallet's seed")
-	flag.IntVar(&cfg.UtxoPoolSize, "poolsize", 1000, "utxo pool size")
+	flag.Int("poolsize", 1000, "deprecated, the utxo pool is not limited")
 	flag.StringVar(&cfg.Admins, "admins", "", "admin pubkey list")
+	var (
+		btcConfirms uint64
+		skyConfirms uint64
+	)
+	flag.Uint64Var(&btcConfirms, "btc-confirms", 6, "confirmations required for crediting bitcoin deposits")
+	flag.Uint64Var(&skyConfirms, "sky-confirms", 1, "confirmations required for crediting skycoin deposits")
 	var (
 		skyNodeAddr        string
 		mzNodeAddr         string
@@ -63,11 +83,55 @@ func registerFlags(cfg *server.Config) {
 	flag.StringVar(&metaliNodeAddr, "metalicoin-node-addr", "127.0.0.1:7820", "metalicoin node address")
 	flag.StringVar(&lifecoinNodeAddr, "lifecoin-node-addr", "127.0.0.1:8420", "lifecoin node address")
 	flag.StringVar(&fishercoinNodeAddr, "fishercoin-node-addr", "127.0.0.1:8520", "fishercoin node address")
+	flag.Uint64Var(&cfg.SnapInterval, "snapshot-interval", 1000, "number of order book events between snapshots")
+	flag.BoolVar(&cfg.CompactLog, "compact-log", true, "delete the order book events included in snapshot")
+	flag.BoolVar(&cfg.RebuildCandles, "rebuild-candles", false, "rebuild the candles from trade history on start")
+	var pairsFile string
+	flag.StringVar(&pairsFile, "pairs", "", "json file of trading pairs, only bitcoin/skycoin is traded if not set")
+	var policyFile string
+	flag.StringVar(&policyFile, "withdraw-policy", "", "json file of withdraw fee and limits of coins, only bitcoin fee is charged if not set")
+	var quotaFile string
+	flag.StringVar(&quotaFile, "rate-limit", "", "json file of request quotas per path, the orders, accounts, deposit addresses and withdrawals are limited if not set")
+	var minVersion uint
+	flag.UintVar(&minVersion, "min-version", uint(sknet.MinVersion), "oldest protocol version accepted, set it to 2 once all clients are upgraded")
+	flag.IntVar(&cfg.Net.Workers, "workers", sknet.DefaultOptions.Workers, "max number of api connections served at the same time")
+	flag.DurationVar(&cfg.Net.IdleTimeout, "idle-timeout", sknet.DefaultOptions.IdleTimeout, "close the api connections idle for the duration, 0 means no limit")
+	flag.DurationVar(&cfg.Net.ReadTimeout, "read-timeout", sknet.DefaultOptions.ReadTimeout, "max duration of reading a request, 0 means no limit")
+	flag.DurationVar(&cfg.Net.WriteTimeout, "write-timeout", sknet.DefaultOptions.WriteTimeout, "max duration of writing a response, 0 means no limit")
+	flag.IntVar(&cfg.Net.MaxConnsPerIP, "max-conns-per-ip", sknet.DefaultOptions.MaxConnsPerIP, "max number of api connections from one ip, 0 means no limit")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", shutdownTimeout, "max duration of waiting for the requests in flight on shutdown")
 	flag.BoolVar(&cfg.HTTPProf, "http-prof", false, "enable http profiling")
 	flag.StringVar(&cfg.Seckey, "seckey", "38d010a84c7b9374352468b41b076fa585d7dfac67ac34adabe2bbba4f4f6257", "private key used for encrypting and decryping messages")
 
 	flag.Set("logtostderr", "true")
 	flag.Parse()
+	if pairsFile != "" {
+		pairs, err := order.LoadPairs(pairsFile)
+		if err != nil {
+			panic(err)
+		}
+		cfg.Pairs = pairs
+	}
+	if policyFile != "" {
+		ps, err := account.LoadWithdrawPolicies(policyFile)
+		if err != nil {
+			panic(err)
+		}
+		cfg.WithdrawPolicies = ps
+	}
+	if quotaFile != "" {
+		qs, err := router.LoadQuotas(quotaFile)
+		if err != nil {
+			panic(err)
+		}
+		cfg.Quotas = qs
+	}
+	if minVersion < uint(sknet.Version1) || minVersion > uint(sknet.Version) {
+		panic(fmt.Sprintf("min-version must be in %d to %d", sknet.Version1, sknet.Version))
+	}
+	sknet.MinVersion = uint32(minVersion)
+	cfg.Confirms[bitcoin.Type] = btcConfirms
+	cfg.Confirms[skycoin.Type] = skyConfirms
 	cfg.NodeAddresses[skycoin.Type] = skyNodeAddr
 	cfg.NodeAddresses[mzcoin.Type] = mzNodeAddr
 	cfg.NodeAddresses[shellcoin.Type] = shellNodeAddr
@@ -99,7 +163,27 @@ func main() {
 		lifecoin.New(cfg.NodeAddresses[lifecoin.Type]),
 		fishercoin.New(cfg.NodeAddresses[fishercoin.Type]),
 		metalicoin.New(cfg.NodeAddresses[metalicoin.Type]))
-	s.Run()
+
+	errC := make(chan error, 1)
+	go func() {
+		errC <- s.Run()
+	}()
+
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, os.Interrupt, syscall.SIGTERM)
+	select {
+	case sig := <-sigC:
+		logger.Info("%v received, shutting down", sig)
+	case err := <-errC:
+		logger.Error("server stopped: %v", err)
+	}
+
+	// drain the requests in flight, and flush the accounts and books.
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		logger.Error("shutdown: %v", err)
+	}
 }
 
 func initConfig() *server.Config {
@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/skycoin/skycoin-exchange/src/client/account"
@@ -147,3 +148,60 @@ func ActiveAccount(se Servicer) httprouter.Handle {
 		sendJSON(w, rlt)
 	}
 }
+
+// GetAccountHistory get the balance changes of the active account.
+// mode: GET
+// url: /api/v1/account/history?coin_type=[:coin_type]&start=[:start]&end=[:end]
+// params:
+// 		coin_type: optional, coin type, like bitcoin, skycoin, all coins if not set.
+// 		start: optional, unix time, changes before it will be ignored.
+// 		end: optional, unix time, changes after it will be ignored.
+func GetAccountHistory(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.GetAccountHistoryReq{
+				Pubkey:   pp.PtrString(a.Pubkey),
+				CoinType: pp.PtrString(r.FormValue("coin_type")),
+			}
+
+			if st := r.FormValue("start"); st != "" {
+				start, err := strconv.ParseInt(st, 10, 64)
+				if err != nil {
+					logger.Error(err.Error())
+					rlt = pp.MakeErrRes(errors.New("invalid start"))
+					break
+				}
+				req.Start = &start
+			}
+
+			if ed := r.FormValue("end"); ed != "" {
+				end, err := strconv.ParseInt(ed, 10, 64)
+				if err != nil {
+					logger.Error(err.Error())
+					rlt = pp.MakeErrRes(errors.New("invalid end"))
+					break
+				}
+				req.End = &end
+			}
+
+			var res pp.GetAccountHistoryRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/account/history", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
@@ -89,3 +89,35 @@ func AdminUpdateBalance(se Servicer) httprouter.Handle {
 		sendJSON(w, rlt)
 	}
 }
+
+// AdminGetFees get the accrued trading fees of each coin, the active account must be admin.
+// mode: GET
+// url: /api/v1/admin/fees
+func AdminGetFees(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		var rlt *pp.EmptyRes
+		for {
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.GetFeesReq{
+				Pubkey: pp.PtrString(a.Pubkey),
+			}
+
+			res := pp.GetFeesRes{}
+			if err := sknet.EncryGet(se.GetServAddr(), "/admin/get/fees", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
@@ -37,3 +37,32 @@ func GetCoins(se Servicer) httprouter.Handle {
 		sendJSON(w, rlt)
 	}
 }
+
+// GetPairs get trading pairs from exchange server.
+func GetPairs(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+			req := pp.GetPairsReq{
+				Pubkey: pp.PtrString(a.Pubkey),
+			}
+
+			var res pp.PairsRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/pairs", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
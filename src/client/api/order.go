@@ -13,12 +13,15 @@ import (
 
 // CreateOrder create order through exchange server.
 // mode: POST
-// url: /api/v1/account/order?coin_pair=[:coin_pair]&type=[:type]&price=[:price]&amt=[:amt]
+// url: /api/v1/account/order?coin_pair=[:coin_pair]&type=[:type]&price=[:price]&amt=[:amt]&kind=[:kind]&time_in_force=[:time_in_force]&post_only=[:post_only]
 // params:
 // 		coin_pair: order coin pair.
 // 		type: order type, can be bid or ask.
-// 		price: price.
+// 		price: price, market order has no price.
 // 		amt: amount.
+// 		kind: optional, limit or market, default is limit.
+// 		time_in_force: optional, GTC, IOC or FOK, default is GTC.
+// 		post_only: optional, true or false.
 func CreateOrder(se Servicer) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		rlt := &pp.EmptyRes{}
@@ -52,6 +55,56 @@ func CreateOrder(se Servicer) httprouter.Handle {
 	}
 }
 
+// CancelOrder cancel order through exchange server.
+// mode: DELETE
+// url: /api/v1/account/order/:id?coin_pair=[:coin_pair]
+// params:
+// 		id: order id.
+// 		coin_pair: order coin pair.
+func CancelOrder(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			if cp == "" {
+				rlt = pp.MakeErrRes(errors.New("coin_pair is empty"))
+				break
+			}
+
+			id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(errors.New("invalid order id"))
+				break
+			}
+
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.CancelOrderReq{
+				Pubkey:   pp.PtrString(a.Pubkey),
+				CoinPair: pp.PtrString(cp),
+				OrderId:  pp.PtrUint64(id),
+			}
+
+			var res pp.CancelOrderRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/cancel/order", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
 func makeOrderReq(r *http.Request) (*pp.OrderReq, error) {
 	// get coin_pair
 	cp := r.FormValue("coin_pair")
@@ -65,14 +118,19 @@ func makeOrderReq(r *http.Request) (*pp.OrderReq, error) {
 		return nil, errors.New("type is empty")
 	}
 
-	// get price
-	pc := r.FormValue("price")
-	if pc == "" {
-		return nil, errors.New("price is empty")
-	}
-	price, err := strconv.ParseUint(pc, 10, 64)
-	if err != nil {
-		return nil, err
+	// market order has no price.
+	kind := r.FormValue("kind")
+	var price uint64
+	if kind != "market" {
+		pc := r.FormValue("price")
+		if pc == "" {
+			return nil, errors.New("price is empty")
+		}
+		var err error
+		price, err = strconv.ParseUint(pc, 10, 64)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// get amount
@@ -85,11 +143,190 @@ func makeOrderReq(r *http.Request) (*pp.OrderReq, error) {
 		return nil, err
 	}
 
+	var postOnly bool
+	if po := r.FormValue("post_only"); po != "" {
+		postOnly, err = strconv.ParseBool(po)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &pp.OrderReq{
-		CoinPair: pp.PtrString(cp),
-		Type:     pp.PtrString(tp),
-		Price:    pp.PtrUint64(price),
-		Amount:   pp.PtrUint64(amount),
+		CoinPair:    pp.PtrString(cp),
+		Type:        pp.PtrString(tp),
+		Price:       pp.PtrUint64(price),
+		Amount:      pp.PtrUint64(amount),
+		Kind:        pp.PtrString(kind),
+		TimeInForce: pp.PtrString(r.FormValue("time_in_force")),
+		PostOnly:    pp.PtrBool(postOnly),
+	}, nil
+}
+
+// CreateConditionalOrder create stop-loss or take-profit order through exchange server.
+// mode: POST
+// url: /api/v1/account/conditional_order?coin_pair=[:coin_pair]&type=[:type]&price=[:price]&amt=[:amt]&kind=[:kind]&time_in_force=[:time_in_force]&trigger=[:trigger]&trigger_price=[:trigger_price]
+// params:
+// 		coin_pair: order coin pair.
+// 		type: order type, can be bid or ask.
+// 		price: price, market order has no price.
+// 		amt: amount.
+// 		kind: optional, limit or market, default is limit, market bid is not supported.
+// 		time_in_force: optional, GTC, IOC or FOK, default is GTC.
+// 		trigger: stop_loss or take_profit.
+// 		trigger_price: the order is placed when the last trade price crosses it.
+func CreateConditionalOrder(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			req, err := makeConditionalOrderReq(r)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req.Pubkey = pp.PtrString(a.Pubkey)
+			var res pp.OrderRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/create/conditional_order", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// GetConditionalOrders get the conditional orders of the active account through exchange server.
+// mode: GET
+// url: /api/v1/account/conditional_orders?coin_pair=[:coin_pair]
+// params:
+// 		coin_pair: order coin pair.
+func GetConditionalOrders(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			if cp == "" {
+				rlt = pp.MakeErrRes(errors.New("coin_pair is empty"))
+				break
+			}
+
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.GetConditionalOrderReq{
+				Pubkey:   pp.PtrString(a.Pubkey),
+				CoinPair: pp.PtrString(cp),
+			}
+
+			var res pp.GetConditionalOrderRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/account/conditional_orders", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// CancelConditionalOrder cancel conditional order through exchange server.
+// mode: DELETE
+// url: /api/v1/account/conditional_order/:id?coin_pair=[:coin_pair]
+// params:
+// 		id: conditional order id.
+// 		coin_pair: order coin pair.
+func CancelConditionalOrder(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			if cp == "" {
+				rlt = pp.MakeErrRes(errors.New("coin_pair is empty"))
+				break
+			}
+
+			id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(errors.New("invalid order id"))
+				break
+			}
+
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.CancelOrderReq{
+				Pubkey:   pp.PtrString(a.Pubkey),
+				CoinPair: pp.PtrString(cp),
+				OrderId:  pp.PtrUint64(id),
+			}
+
+			var res pp.CancelOrderRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/cancel/conditional_order", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+func makeConditionalOrderReq(r *http.Request) (*pp.ConditionalOrderReq, error) {
+	od, err := makeOrderReq(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tg := r.FormValue("trigger")
+	if tg == "" {
+		return nil, errors.New("trigger is empty")
+	}
+
+	tp := r.FormValue("trigger_price")
+	if tp == "" {
+		return nil, errors.New("trigger_price is empty")
+	}
+	price, err := strconv.ParseUint(tp, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pp.ConditionalOrderReq{
+		CoinPair:     od.CoinPair,
+		Type:         od.Type,
+		Price:        od.Price,
+		Amount:       od.Amount,
+		Kind:         od.Kind,
+		TimeInForce:  od.TimeInForce,
+		Trigger:      pp.PtrString(tg),
+		TriggerPrice: pp.PtrUint64(price),
 	}, nil
 }
 
@@ -149,3 +386,44 @@ func getOrders(se Servicer, tp string) httprouter.Handle {
 		sendJSON(w, rlt)
 	}
 }
+
+// GetDepth get the aggregated price levels of the book through exchange server.
+// mode: GET
+// url: /api/v1/depth?coin_pair=[:coin_pair]&levels=[:levels]
+// params:
+// 		coin_pair: coin pair, like bitcoin/skycoin.
+// 		levels: optional, number of price levels of each side, default is 20.
+func GetDepth(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			if cp == "" {
+				rlt = pp.MakeErrRes(errors.New("coin_pair is empty"))
+				break
+			}
+
+			req := pp.GetDepthReq{CoinPair: &cp}
+			if lv := r.FormValue("levels"); lv != "" {
+				n, err := strconv.ParseInt(lv, 10, 32)
+				if err != nil {
+					logger.Error(err.Error())
+					rlt = pp.MakeErrRes(errors.New("invalid levels"))
+					break
+				}
+				req.Levels = pp.PtrInt32(int32(n))
+			}
+
+			var res pp.GetDepthRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/depth", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/skycoin/skycoin-exchange/src/client/account"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+)
+
+// Subscribe subscribes the topics through exchange server, the events pushed by server are
+// forwarded to the browser as server-sent events, the event name is the topic.
+// mode: GET
+// url: /api/v1/subscribe?topics=[:topics]
+// params:
+// 		topics: topics joined with ',', can be book:[:coin_pair], trades:[:coin_pair] and account,
+// 		the account topic is the fills and balance changes of the active account.
+func Subscribe(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			topics := strings.Split(r.FormValue("topics"), ",")
+			if topics[0] == "" {
+				rlt = pp.MakeErrRes(errors.New("topics empty"))
+				break
+			}
+
+			req := pp.SubscribeReq{Topics: topics}
+			if hasTopic(topics, "account") {
+				a, err := account.GetActive()
+				if err != nil {
+					logger.Error(err.Error())
+					rlt = pp.MakeErrRes(err)
+					break
+				}
+				req.Pubkey = pp.PtrString(a.Pubkey)
+
+				// prove the account key by signing over the replay stamp of the request.
+				tm, nonce, err := sknet.NewStamp()
+				if err != nil {
+					rlt = pp.MakeErrRes(err)
+					break
+				}
+				sig, err := sknet.SignProof("/subscribe", tm, nonce, a.Seckey)
+				if err != nil {
+					logger.Error(err.Error())
+					rlt = pp.MakeErrRes(err)
+					break
+				}
+				req.ReqTime, req.ReqNonce, req.Sig = &tm, &nonce, &sig
+			}
+
+			flusher, ok := w.(http.Flusher)
+			if !ok {
+				rlt = pp.MakeErrRes(errors.New("streaming is not supported"))
+				break
+			}
+
+			var res pp.SubscribeRes
+			sub, err := sknet.Subscribe(se.GetServAddr(), "/subscribe", req, &res)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+			defer sub.Close()
+
+			// the blocked Next returns once the browser is gone.
+			go func() {
+				<-r.Context().Done()
+				sub.Close()
+			}()
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			flusher.Flush()
+
+			for {
+				ev, err := sub.Next()
+				if err != nil {
+					logger.Debug("subscription closed: %v", err)
+					return
+				}
+
+				// the server resolves the account topic into the topic of the pubkey.
+				topic := ev.Topic
+				if strings.HasPrefix(topic, "account:") {
+					topic = "account"
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", topic, ev.Data)
+				flusher.Flush()
+			}
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+func hasTopic(topics []string, topic string) bool {
+	for _, t := range topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
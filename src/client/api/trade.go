@@ -0,0 +1,223 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/skycoin/skycoin-exchange/src/client/account"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+)
+
+// GetTrades get executed trades through exchange server.
+// mode: GET
+// url: /api/v1/trades?coin_pair=[:coin_pair]&start=[:start]&end=[:end]
+// params:
+// 		coin_pair: coin pair, like bitcoin/skycoin.
+// 		start: start index of the trades, the latest trade's index is 0.
+// 		end: end index of the trades.
+func GetTrades(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp, start, end, err := getPairAndRange(r)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.GetTradesReq{
+				CoinPair: &cp,
+				Start:    &start,
+				End:      &end,
+			}
+
+			var res pp.GetTradesRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/trades", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// GetAccountFills get the active account's trades through exchange server.
+// mode: GET
+// url: /api/v1/account/fills?coin_pair=[:coin_pair]&start=[:start]&end=[:end]
+// params:
+// 		coin_pair: coin pair, like bitcoin/skycoin.
+// 		start: start index of the trades, the latest trade's index is 0.
+// 		end: end index of the trades.
+func GetAccountFills(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp, start, end, err := getPairAndRange(r)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.GetAccountFillsReq{
+				Pubkey:   pp.PtrString(a.Pubkey),
+				CoinPair: &cp,
+				Start:    &start,
+				End:      &end,
+			}
+
+			var res pp.GetAccountFillsRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/account/fills", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// getPairAndRange reads coin_pair, start and end from request.
+func getPairAndRange(r *http.Request) (string, int64, int64, error) {
+	cp := r.FormValue("coin_pair")
+	if cp == "" {
+		return "", 0, 0, errors.New("coin_pair is empty")
+	}
+
+	st := r.FormValue("start")
+	if st == "" {
+		return "", 0, 0, errors.New("start is empty")
+	}
+	start, err := strconv.ParseInt(st, 10, 64)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	ed := r.FormValue("end")
+	if ed == "" {
+		return "", 0, 0, errors.New("end is empty")
+	}
+	end, err := strconv.ParseInt(ed, 10, 64)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return cp, start, end, nil
+}
+
+// GetTicker get the ticker of specific coin pair through exchange server.
+// mode: GET
+// url: /api/v1/ticker?coin_pair=[:coin_pair]
+// params:
+// 		coin_pair: coin pair, like bitcoin/skycoin.
+func GetTicker(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			if cp == "" {
+				rlt = pp.MakeErrRes(errors.New("coin_pair is empty"))
+				break
+			}
+
+			req := pp.GetTickerReq{CoinPair: &cp}
+			var res pp.GetTickerRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/ticker", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// GetCandles get the OHLCV candles through exchange server.
+// mode: GET
+// url: /api/v1/candles?pair=[:pair]&interval=[:interval]&start=[:start]&end=[:end]
+// params:
+// 		pair: coin pair, like bitcoin/skycoin.
+// 		interval: candle interval, can be 1m, 5m, 1h or 1d.
+// 		start: unix time, the candles open at or after it are returned.
+// 		end: unix time, the candles open before it are returned.
+func GetCandles(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			req, err := makeCandlesReq(r)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			var res pp.GetCandlesRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/candles", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+func makeCandlesReq(r *http.Request) (*pp.GetCandlesReq, error) {
+	cp := r.FormValue("pair")
+	if cp == "" {
+		return nil, errors.New("pair is empty")
+	}
+
+	iv := r.FormValue("interval")
+	if iv == "" {
+		return nil, errors.New("interval is empty")
+	}
+
+	st := r.FormValue("start")
+	if st == "" {
+		return nil, errors.New("start is empty")
+	}
+	start, err := strconv.ParseInt(st, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	ed := r.FormValue("end")
+	if ed == "" {
+		return nil, errors.New("end is empty")
+	}
+	end, err := strconv.ParseInt(ed, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pp.GetCandlesReq{
+		CoinPair: pp.PtrString(cp),
+		Interval: pp.PtrString(iv),
+		Start:    pp.PtrInt64(start),
+		End:      pp.PtrInt64(end),
+	}, nil
+}
@@ -68,3 +68,30 @@ func Withdraw(se Servicer) httprouter.Handle {
 		sendJSON(w, rlt)
 	}
 }
+
+// GetWithdrawPolicy get the withdrawal fee and limits of coins.
+// mode: GET
+// url: /api/v1/withdraw/policy?coin_type=[:coin_type]
+// params:
+// 		coin_type: optional, all coins' policies will be returned if empty.
+func GetWithdrawPolicy(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetWithdrawPolicyReq{
+				CoinType: pp.PtrString(r.FormValue("coin_type")),
+			}
+
+			var res pp.GetWithdrawPolicyRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/withdraw/policy", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
@@ -20,19 +20,32 @@ func New(se api.Servicer) *httprouter.Router {
 // base handlers.
 func registerBaseHandlers(rt *httprouter.Router, se api.Servicer) {
 	rt.GET("/api/v1/coins", api.GetCoins(se))
+	rt.GET("/api/v1/pairs", api.GetPairs(se))
 	rt.POST("/api/v1/accounts", api.CreateAccount(se))
 	rt.GET("/api/v1/account", api.GetAccount(se))
 	rt.PUT("/api/v1/account/state", api.ActiveAccount(se))
 	rt.POST("/api/v1/account/deposit_address", api.GetDepositAddress(se))
 	rt.GET("/api/v1/account/balance", api.GetBalance(se))
+	rt.GET("/api/v1/account/history", api.GetAccountHistory(se))
 	rt.POST("/api/v1/account/withdrawal", api.Withdraw(se))
+	rt.GET("/api/v1/withdraw/policy", api.GetWithdrawPolicy(se))
 }
 
 // order handlers
 func registerOrderHandlers(rt *httprouter.Router, se api.Servicer) {
 	rt.POST("/api/v1/account/order", api.CreateOrder(se))
+	rt.DELETE("/api/v1/account/order/:id", api.CancelOrder(se))
+	rt.POST("/api/v1/account/conditional_order", api.CreateConditionalOrder(se))
+	rt.GET("/api/v1/account/conditional_orders", api.GetConditionalOrders(se))
+	rt.DELETE("/api/v1/account/conditional_order/:id", api.CancelConditionalOrder(se))
 	rt.GET("/api/v1/orders/bid", api.GetBidOrders(se))
 	rt.GET("/api/v1/orders/ask", api.GetAskOrders(se))
+	rt.GET("/api/v1/depth", api.GetDepth(se))
+	rt.GET("/api/v1/ticker", api.GetTicker(se))
+	rt.GET("/api/v1/candles", api.GetCandles(se))
+	rt.GET("/api/v1/trades", api.GetTrades(se))
+	rt.GET("/api/v1/account/fills", api.GetAccountFills(se))
+	rt.GET("/api/v1/subscribe", api.Subscribe(se))
 }
 
 // utxos handlers
@@ -62,4 +75,5 @@ func registerWalletHandlers(rt *httprouter.Router, se api.Servicer) {
 // admin handlers.
 func registerAdminHandlers(rt *httprouter.Router, se api.Servicer) {
 	rt.PUT("/api/v1/admin/account/balance", api.AdminUpdateBalance(se))
+	rt.GET("/api/v1/admin/fees", api.AdminGetFees(se))
 }
@@ -21,10 +21,10 @@ var (
 
 // Utxo unspent output
 type Utxo interface {
+	coin.Utxo
 	GetTxid() string
 	GetVout() uint32
-	GetAmount() uint64
-	GetAddress() string
+	GetConfirms() uint64
 }
 
 // UtxoWithkey unspent output with privkey.
@@ -31,6 +31,11 @@ type BlkChnUtxoWithkey struct {
 	Privkey string
 }
 
+// GetID returns txid:vout, which is the id of the output.
+func (bo BlkChnUtxo) GetID() string {
+	return fmt.Sprintf("%s:%d", bo.GetTxid(), bo.GetVout())
+}
+
 func (bo BlkChnUtxo) GetTxid() string {
 	return bo.Tx_hash_big_endian
 }
@@ -43,6 +48,10 @@ func (bo BlkChnUtxo) GetAmount() uint64 {
 	return bo.Value
 }
 
+func (bo BlkChnUtxo) GetConfirms() uint64 {
+	return bo.Confirmations
+}
+
 func (bk BlkChnUtxoWithkey) GetPrivKey() string {
 	return bk.Privkey
 }
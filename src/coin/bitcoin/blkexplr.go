@@ -24,6 +24,11 @@ type BlkExplrUtxo struct {
 	Confirms     uint64 `json:"confirmations"`
 }
 
+// GetID returns txid:vout, which is the id of the output.
+func (be BlkExplrUtxo) GetID() string {
+	return fmt.Sprintf("%s:%d", be.Txid, be.Vout)
+}
+
 func (be BlkExplrUtxo) GetTxid() string {
 	return be.Txid
 }
@@ -40,6 +45,10 @@ func (be BlkExplrUtxo) GetAddress() string {
 	return be.Address
 }
 
+func (be BlkExplrUtxo) GetConfirms() uint64 {
+	return be.Confirms
+}
+
 // BlkChnUtxo with private key
 type BlkExplrUtxoWithkey struct {
 	BlkExplrUtxo
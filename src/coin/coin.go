@@ -13,6 +13,9 @@ type Gateway interface {
 	GetBalance(addrs []string) (pp.Balance, error)
 	GetOutput(hash string) (interface{}, error)
 	GetUtxos(addrs []string) (interface{}, error)
+	// NewUtxoManager creates the manager of the utxos of the exchange's addresses, the utxos
+	// with less confirmations will not be taken.
+	NewUtxoManager(confirms uint64, watchAddrs []string) UtxoManager
 }
 
 // TxHandler transaction handler interface for gateway.
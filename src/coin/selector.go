@@ -0,0 +1,150 @@
+package coin
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultSelector is the name of the strategy used if the coin doesn't configure one.
+var DefaultSelector = "min-inputs"
+
+// bnbMaxTries limits the searched branches of BranchAndBound.
+var bnbMaxTries = 100000
+
+// Selector chooses the utxos that cover the amount from the available utxos,
+// which are in arrival order, nil is returned if they're not sufficient.
+type Selector func(utxos []Utxo, amt uint64) []Utxo
+
+var selectors = map[string]Selector{
+	"first":         FirstArrived,
+	"largest-first": LargestFirst,
+	"bnb":           BranchAndBound,
+	"min-inputs":    MinimizeInputs,
+}
+
+// GetSelector returns the coin selection strategy of specific name.
+func GetSelector(name string) (Selector, error) {
+	s, ok := selectors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown coin selection strategy:%s", name)
+	}
+	return s, nil
+}
+
+// FirstArrived takes the utxos in arrival order until the amount is covered.
+func FirstArrived(utxos []Utxo, amt uint64) []Utxo {
+	return takeUntil(utxos, amt)
+}
+
+// LargestFirst takes the largest utxos until the amount is covered.
+func LargestFirst(utxos []Utxo, amt uint64) []Utxo {
+	return takeUntil(sortByAmount(utxos), amt)
+}
+
+// MinimizeInputs takes the fewest utxos that cover the amount, among them each input is
+// the smallest one that still lets the rest cover the amount, so the change is reduced.
+func MinimizeInputs(utxos []Utxo, amt uint64) []Utxo {
+	rest := sortByAmount(utxos)
+	k := len(takeUntil(rest, amt))
+	if k == 0 {
+		return nil
+	}
+
+	picked := make([]Utxo, 0, k)
+	need := amt
+	for m := k; m > 0 && need > 0; m-- {
+		// prefix[i] is the sum of the i largest utxos of the rest.
+		prefix := make([]uint64, len(rest)+1)
+		for i, u := range rest {
+			prefix[i+1] = prefix[i] + u.GetAmount()
+		}
+
+		// from the smallest, the other m-1 inputs are the largest ones except the candidate.
+		for i := len(rest) - 1; i >= 0; i-- {
+			c := rest[i].GetAmount()
+			others := prefix[m-1]
+			if i < m-1 {
+				others = prefix[m] - c
+			}
+
+			if c+others >= need {
+				picked = append(picked, rest[i])
+				rest = append(rest[:i:i], rest[i+1:]...)
+				if c >= need {
+					need = 0
+				} else {
+					need -= c
+				}
+				break
+			}
+		}
+	}
+	return sortByAmount(picked)
+}
+
+// BranchAndBound searches the utxos whose sum is exactly the amount, so no change output is
+// needed, it falls back to MinimizeInputs if there's no exact match in bnbMaxTries branches.
+func BranchAndBound(utxos []Utxo, amt uint64) []Utxo {
+	sorted := sortByAmount(utxos)
+
+	// suffix[i] is the sum of the utxos from i.
+	suffix := make([]uint64, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		suffix[i] = suffix[i+1] + sorted[i].GetAmount()
+	}
+
+	var (
+		picked []Utxo
+		tries  int
+	)
+	var search func(i int, sum uint64) bool
+	search = func(i int, sum uint64) bool {
+		if sum == amt {
+			return true
+		}
+
+		if sum > amt || i == len(sorted) || sum+suffix[i] < amt || tries >= bnbMaxTries {
+			return false
+		}
+		tries++
+
+		// include the utxo.
+		picked = append(picked, sorted[i])
+		if search(i+1, sum+sorted[i].GetAmount()) {
+			return true
+		}
+		picked = picked[:len(picked)-1]
+
+		// exclude the utxo, and the ones of the same amount, which lead to the same branches.
+		j := i + 1
+		for j < len(sorted) && sorted[j].GetAmount() == sorted[i].GetAmount() {
+			j++
+		}
+		return search(j, sum)
+	}
+
+	if amt > 0 && search(0, 0) {
+		return picked
+	}
+	return MinimizeInputs(utxos, amt)
+}
+
+// takeUntil takes the utxos in order until the amount is covered.
+func takeUntil(utxos []Utxo, amt uint64) []Utxo {
+	var total uint64
+	for i, u := range utxos {
+		total += u.GetAmount()
+		if total >= amt {
+			return append([]Utxo{}, utxos[:i+1]...)
+		}
+	}
+	return nil
+}
+
+// sortByAmount returns the utxos sorted by amount in descending order,
+// the utxos of the same amount are kept in arrival order.
+func sortByAmount(utxos []Utxo) []Utxo {
+	sorted := append([]Utxo{}, utxos...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].GetAmount() > sorted[j].GetAmount() })
+	return sorted
+}
@@ -0,0 +1,81 @@
+package coin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func amountsOf(utxos []Utxo) []uint64 {
+	amts := make([]uint64, len(utxos))
+	for i, u := range utxos {
+		amts[i] = u.GetAmount()
+	}
+	return amts
+}
+
+func TestSelectors(t *testing.T) {
+	utxos := makeTestUtxos(1, 5, 30, 2, 20, 12)
+	testCases := []struct {
+		name   string
+		amt    uint64
+		expect []uint64
+	}{
+		{"first", 35, []uint64{1, 5, 30}},
+		{"largest-first", 35, []uint64{30, 20}},
+		{"largest-first", 30, []uint64{30}},
+		// 2 inputs are needed, 30+5 is the smallest pair that covers 35.
+		{"min-inputs", 35, []uint64{30, 5}},
+		{"min-inputs", 15, []uint64{20}},
+		{"min-inputs", 45, []uint64{30, 20}},
+		{"min-inputs", 60, []uint64{30, 20, 12}},
+		// exact match, no change.
+		{"bnb", 33, []uint64{30, 2, 1}},
+		{"bnb", 37, []uint64{30, 5, 2}},
+		// no exact match, falls back to min-inputs.
+		{"bnb", 69, []uint64{30, 20, 12, 5, 2}},
+		{"bnb", 71, nil},
+	}
+
+	for _, tc := range testCases {
+		s, err := GetSelector(tc.name)
+		assert.Nil(t, err)
+		uxs := s(utxos, tc.amt)
+		if tc.expect == nil {
+			assert.Nil(t, uxs, "%s %d", tc.name, tc.amt)
+			continue
+		}
+		assert.Equal(t, tc.expect, amountsOf(uxs), "%s %d", tc.name, tc.amt)
+	}
+
+	// the available utxos are not reordered.
+	assert.Equal(t, []uint64{1, 5, 30, 2, 20, 12}, amountsOf(utxos))
+
+	_, err := GetSelector("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestBranchAndBoundMaxTries(t *testing.T) {
+	utxos := makeTestUtxos(10, 8, 4, 3)
+	assert.Equal(t, []uint64{8, 4, 3}, amountsOf(BranchAndBound(utxos, 15)))
+
+	defer func(n int) { bnbMaxTries = n }(bnbMaxTries)
+	bnbMaxTries = 1
+
+	// the search stops before the exact match is found.
+	assert.Equal(t, []uint64{10, 8}, amountsOf(BranchAndBound(utxos, 15)))
+}
+
+func TestPoolSelector(t *testing.T) {
+	p := NewUtxoManager(func(addrs []string) ([]Utxo, error) {
+		return makeTestUtxos(10, 20, 30), nil
+	}, nil).(*UtxoPool)
+	_, err := p.update()
+	assert.Nil(t, err)
+
+	p.SetSelector(LargestFirst)
+	uxs, err := p.ChooseUtxos(25, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{30}, amountsOf(uxs))
+}
@@ -0,0 +1,59 @@
+package skycoin
+
+import "github.com/skycoin/skycoin-exchange/src/coin"
+
+// TxHoursFactor is the divisor of the input hours that each output gets, a quarter
+// of the input hours are kept, and split between the receiver and the change.
+var TxHoursFactor uint64 = 8
+
+// HoursSelector wraps the selector to respect the coin hours, if the chosen utxos don't have
+// enough hours to give each output one hour, the unchosen utxo with the most hours is added.
+func HoursSelector(s coin.Selector) coin.Selector {
+	return func(utxos []coin.Utxo, amt uint64) []coin.Utxo {
+		chosen := s(utxos, amt)
+		if chosen == nil || totalHours(chosen) >= TxHoursFactor {
+			return chosen
+		}
+
+		ids := make(map[string]bool, len(chosen))
+		for _, u := range chosen {
+			ids[u.GetID()] = true
+		}
+
+		var best Utxo
+		for _, u := range utxos {
+			su, ok := u.(Utxo)
+			if !ok || ids[u.GetID()] {
+				continue
+			}
+			if best == nil || su.GetHours() > best.GetHours() {
+				best = su
+			}
+		}
+
+		if best == nil || best.GetHours() == 0 {
+			return chosen
+		}
+		return append(chosen, best)
+	}
+}
+
+func totalHours(utxos []coin.Utxo) uint64 {
+	var n uint64
+	for _, u := range utxos {
+		if su, ok := u.(Utxo); ok {
+			n += su.GetHours()
+		}
+	}
+	return n
+}
+
+// utxoManager chooses the utxos with the hours respected.
+type utxoManager struct {
+	coin.UtxoManager
+}
+
+// SetSelector sets the strategy wrapped by the HoursSelector.
+func (um utxoManager) SetSelector(s coin.Selector) {
+	um.UtxoManager.SetSelector(HoursSelector(s))
+}
@@ -36,11 +36,23 @@ type Skycoin struct {
 	NodeAddress string // skycoin node address
 }
 
+// Gateway is implemented by skycoin and the coins forked from it, which embed Skycoin,
+// and share its transaction format and node api.
+type Gateway interface {
+	coin.Gateway
+	GetNodeAddress() string
+}
+
 // New creates a skycoin instance.
 func New(nodeAddr string) *Skycoin {
 	return &Skycoin{NodeAddress: nodeAddr}
 }
 
+// GetNodeAddress returns the address of the node api.
+func (sky Skycoin) GetNodeAddress() string {
+	return sky.NodeAddress
+}
+
 // GetTx get skycoin verbose transaction.
 func (sky *Skycoin) GetTx(txid string) (*pp.Tx, error) {
 	url := fmt.Sprintf("http://%s/transaction?txid=%s", sky.NodeAddress, txid)
@@ -271,6 +283,7 @@ func (sky *Skycoin) GetOutput(hash string) (interface{}, error) {
 
 // Utxo unspent outputs interface
 type Utxo interface {
+	coin.Utxo
 	GetHash() string
 	GetSrcTx() string
 	GetAddress() string
@@ -293,6 +306,16 @@ func (su SkyUtxo) GetHash() string {
 	return su.Hash
 }
 
+// GetID returns utxo hash, which is the id of the output.
+func (su SkyUtxo) GetID() string {
+	return su.Hash
+}
+
+// GetAmount returns coins in output, in droplets.
+func (su SkyUtxo) GetAmount() uint64 {
+	return su.GetCoins()
+}
+
 // GetSrcTx returns source transaction
 func (su SkyUtxo) GetSrcTx() string {
 	return su.SourceTransaction
@@ -378,6 +401,35 @@ func GetUnspentOutputs(nodeAddr string, addrs []string) ([]Utxo, error) {
 	return ux, nil
 }
 
+// getTxConfirms returns how many blocks deep the transaction is in the chain,
+// 0 means the transaction is not confirmed yet.
+func getTxConfirms(nodeAddr string, txid string) (uint64, error) {
+	url := fmt.Sprintf("http://%s/transaction?txid=%s", nodeAddr, txid)
+	rsp, err := http.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+	d, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if rsp.StatusCode != 200 {
+		return 0, errors.New(string(d))
+	}
+
+	tx := visor.TransactionResult{}
+	if err := json.Unmarshal(d, &tx); err != nil {
+		return 0, err
+	}
+
+	if !tx.Status.Confirmed {
+		return 0, nil
+	}
+	return tx.Status.Height, nil
+}
+
 func getUnspentOutputsByHashes(nodeAddr string, hashes []string) ([]Utxo, error) {
 	if len(hashes) == 0 {
 		return []Utxo{}, nil
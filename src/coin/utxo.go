@@ -0,0 +1,286 @@
+package coin
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/op/go-logging"
+)
+
+var (
+	logger = logging.MustGetLogger("exchange.coin")
+
+	// CheckUtxoTick is the interval of checking the utxos of the watched addresses.
+	CheckUtxoTick = 5 * time.Second
+
+	// UtxoReserveTime is how long the chosen utxos are reserved, they're available again after
+	// it, so the utxos of a withdrawal that was never finished won't leak out of the pool.
+	UtxoReserveTime = 10 * time.Minute
+
+	// ErrChooseUtxoTimeout will be returned if the available utxos are not sufficient in time.
+	ErrChooseUtxoTimeout = errors.New("choose utxos time out")
+)
+
+// Utxo is the unspent output of any coin.
+type Utxo interface {
+	GetID() string // unique id of the output, txid:vout of bitcoin, hash of skycoin.
+	GetAddress() string
+	GetAmount() uint64
+}
+
+// UtxoFetcher returns the confirmed unspent outputs of the addresses.
+type UtxoFetcher func(addrs []string) ([]Utxo, error)
+
+// UtxoManager tracks the utxos of the watched addresses, and chooses the utxos for withdrawals.
+// The chosen utxos are reserved until they're released, marked as spent, or expired.
+type UtxoManager interface {
+	Start(closing chan bool)
+	ChooseUtxos(amt uint64, tm time.Duration) ([]Utxo, error)
+	ReleaseUtxos(utxos []Utxo) // puts the reserved utxos back to the pool.
+	MarkSpent(utxos []Utxo)    // the utxos are spent by a sent tx, and won't be chosen again.
+	WatchAddresses(addrs []string)
+	RegisterDepositChan(c chan Utxo) // new confirmed utxos will also be sent to this channel.
+	SetSelector(s Selector)          // sets the coin selection strategy, it must be called before Start.
+	Holdings() (uint64, error)       // total amount of the unspent outputs of the watched addresses.
+}
+
+type utxoState int
+
+const (
+	utxoAvailable utxoState = iota
+	utxoReserved
+	utxoSpent
+)
+
+type utxoEntry struct {
+	utxo   Utxo
+	state  utxoState
+	expire time.Time // end of the reservation.
+	seq    uint64    // arrival order of the utxo.
+}
+
+// UtxoPool is the UtxoManager shared by all coins, the coins only differ in fetching the utxos.
+type UtxoPool struct {
+	fetch     UtxoFetcher
+	addrs     []string
+	utxos     map[string]*utxoEntry
+	seq       uint64
+	depositCh chan Utxo
+	selector  Selector
+	changed   chan struct{} // closed once utxos may become available.
+	mtx       sync.Mutex
+}
+
+// NewUtxoManager creates the utxo pool, the utxos of the watched addresses are fetched every CheckUtxoTick.
+// The utxos are chosen by the DefaultSelector.
+func NewUtxoManager(fetch UtxoFetcher, watchAddrs []string) UtxoManager {
+	s, err := GetSelector(DefaultSelector)
+	if err != nil {
+		panic(err)
+	}
+
+	return &UtxoPool{
+		fetch:    fetch,
+		addrs:    append([]string{}, watchAddrs...),
+		utxos:    make(map[string]*utxoEntry),
+		selector: s,
+		changed:  make(chan struct{}),
+	}
+}
+
+// Start checks the utxos of watched addresses until closing.
+func (p *UtxoPool) Start(closing chan bool) {
+	t := time.NewTicker(CheckUtxoTick)
+	defer t.Stop()
+	for {
+		select {
+		case <-closing:
+			return
+		case <-t.C:
+			newUtxos, err := p.update()
+			if err != nil {
+				logger.Error(err.Error())
+				break
+			}
+
+			p.mtx.Lock()
+			c := p.depositCh
+			p.mtx.Unlock()
+			if c == nil {
+				break
+			}
+
+			for _, u := range newUtxos {
+				select {
+				case c <- u:
+				case <-closing:
+					return
+				}
+			}
+		}
+	}
+}
+
+// update syncs the pool with the latest utxos, and returns the new ones. The utxos that
+// are not unspent any more are removed, including the reserved and the spent ones.
+func (p *UtxoPool) update() ([]Utxo, error) {
+	p.mtx.Lock()
+	addrs := append([]string{}, p.addrs...)
+	p.mtx.Unlock()
+
+	latest, err := p.fetch(addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	ids := make(map[string]bool, len(latest))
+	newUtxos := []Utxo{}
+	for _, u := range latest {
+		id := u.GetID()
+		ids[id] = true
+		if _, ok := p.utxos[id]; ok {
+			continue
+		}
+
+		logger.Debug("new utxo:%s addr:%s amount:%d", id, u.GetAddress(), u.GetAmount())
+		p.seq++
+		p.utxos[id] = &utxoEntry{utxo: u, seq: p.seq}
+		newUtxos = append(newUtxos, u)
+	}
+
+	for id := range p.utxos {
+		if !ids[id] {
+			delete(p.utxos, id)
+		}
+	}
+
+	// wake up the waiters, the expired reservations are checked again too.
+	p.notify()
+	return newUtxos, nil
+}
+
+// ChooseUtxos reserves the utxos that cover the amount, it waits for the new or released
+// utxos until time out, ErrChooseUtxoTimeout will be returned if they're still not sufficient.
+func (p *UtxoPool) ChooseUtxos(amt uint64, tm time.Duration) ([]Utxo, error) {
+	timeout := time.After(tm)
+	for {
+		utxos, changed := p.reserve(amt)
+		if utxos != nil {
+			return utxos, nil
+		}
+
+		select {
+		case <-changed:
+		case <-timeout:
+			return nil, ErrChooseUtxoTimeout
+		}
+	}
+}
+
+// reserve reserves the utxos chosen by the selector from the available ones, nil is
+// returned with the channel that will be closed on changes if they're not sufficient.
+func (p *UtxoPool) reserve(amt uint64) ([]Utxo, chan struct{}) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	now := time.Now()
+	es := []*utxoEntry{}
+	for _, e := range p.utxos {
+		if e.state == utxoAvailable || (e.state == utxoReserved && now.After(e.expire)) {
+			es = append(es, e)
+		}
+	}
+	sort.Slice(es, func(i, j int) bool { return es[i].seq < es[j].seq })
+
+	available := make([]Utxo, len(es))
+	for i, e := range es {
+		available[i] = e.utxo
+	}
+
+	utxos := p.selector(available, amt)
+	if utxos == nil {
+		return nil, p.changed
+	}
+
+	for _, u := range utxos {
+		e := p.utxos[u.GetID()]
+		e.state = utxoReserved
+		e.expire = now.Add(UtxoReserveTime)
+	}
+	return utxos, nil
+}
+
+// ReleaseUtxos puts the reserved utxos back to the pool.
+func (p *UtxoPool) ReleaseUtxos(utxos []Utxo) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, u := range utxos {
+		if e, ok := p.utxos[u.GetID()]; ok && e.state == utxoReserved {
+			logger.Debug("utxo put back: %s", u.GetID())
+			e.state = utxoAvailable
+		}
+	}
+	p.notify()
+}
+
+// MarkSpent marks the utxos as spent, they're kept until the chain doesn't report them as unspent.
+func (p *UtxoPool) MarkSpent(utxos []Utxo) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, u := range utxos {
+		if e, ok := p.utxos[u.GetID()]; ok {
+			e.state = utxoSpent
+		}
+	}
+}
+
+// WatchAddresses adds the addresses whose utxos will be tracked.
+func (p *UtxoPool) WatchAddresses(addrs []string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, addr := range addrs {
+		logger.Debug("watch address:%s", addr)
+	}
+	p.addrs = append(p.addrs, addrs...)
+}
+
+// RegisterDepositChan registers the channel that new utxos will be sent to.
+func (p *UtxoPool) RegisterDepositChan(c chan Utxo) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.depositCh = c
+}
+
+// Holdings fetches the unspent outputs of the watched addresses, and returns their total amount.
+func (p *UtxoPool) Holdings() (uint64, error) {
+	p.mtx.Lock()
+	addrs := append([]string{}, p.addrs...)
+	p.mtx.Unlock()
+
+	utxos, err := p.fetch(addrs)
+	if err != nil {
+		return 0, err
+	}
+
+	var total uint64
+	for _, u := range utxos {
+		total += u.GetAmount()
+	}
+	return total, nil
+}
+
+// SetSelector sets the coin selection strategy.
+func (p *UtxoPool) SetSelector(s Selector) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.selector = s
+}
+
+// notify wakes up the waiters of changes, the caller must hold the mtx.
+func (p *UtxoPool) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
@@ -0,0 +1,118 @@
+package coin
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUtxo struct {
+	id     string
+	amount uint64
+}
+
+func (u testUtxo) GetID() string      { return u.id }
+func (u testUtxo) GetAddress() string { return "addr" }
+func (u testUtxo) GetAmount() uint64  { return u.amount }
+
+func makeTestUtxos(amts ...uint64) []Utxo {
+	utxos := make([]Utxo, len(amts))
+	for i, amt := range amts {
+		utxos[i] = testUtxo{id: fmt.Sprintf("ux%d", i), amount: amt}
+	}
+	return utxos
+}
+
+func TestUtxoPool(t *testing.T) {
+	latest := makeTestUtxos(10, 20, 30)
+	p := NewUtxoManager(func(addrs []string) ([]Utxo, error) {
+		return latest, nil
+	}, []string{"addr"}).(*UtxoPool)
+	p.SetSelector(FirstArrived)
+
+	newUtxos, err := p.update()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(newUtxos))
+	held, err := p.Holdings()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(60), held)
+
+	// the utxos are chosen in arrival order.
+	uxs, err := p.ChooseUtxos(25, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, latest[:2], uxs)
+
+	// the reserved utxos can't be chosen again.
+	_, err = p.ChooseUtxos(40, 10*time.Millisecond)
+	assert.Equal(t, ErrChooseUtxoTimeout, err)
+
+	// the released utxos wake up the waiter.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		p.ReleaseUtxos(uxs[1:])
+	}()
+	uxs2, err := p.ChooseUtxos(40, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, []Utxo{latest[1], latest[2]}, uxs2)
+
+	// the spent utxos are not chosen, even if released, until the chain removes them.
+	p.MarkSpent(uxs[:1])
+	p.ReleaseUtxos(uxs[:1])
+	p.ReleaseUtxos(uxs2)
+	_, err = p.ChooseUtxos(60, 10*time.Millisecond)
+	assert.Equal(t, ErrChooseUtxoTimeout, err)
+
+	// ux0 is spent on chain, and ux3 is the new change output.
+	latest = makeTestUtxos(0, 20, 30, 15)[1:]
+	newUtxos, err = p.update()
+	assert.Nil(t, err)
+	assert.Equal(t, []Utxo{latest[2]}, newUtxos)
+	uxs, err = p.ChooseUtxos(65, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(uxs))
+}
+
+func TestUtxoReserveExpire(t *testing.T) {
+	defer func(tm time.Duration) { UtxoReserveTime = tm }(UtxoReserveTime)
+	UtxoReserveTime = 10 * time.Millisecond
+
+	p := NewUtxoManager(func(addrs []string) ([]Utxo, error) {
+		return makeTestUtxos(10), nil
+	}, nil).(*UtxoPool)
+	_, err := p.update()
+	assert.Nil(t, err)
+
+	_, err = p.ChooseUtxos(10, time.Second)
+	assert.Nil(t, err)
+
+	// the reservation of a withdrawal that never finished expires.
+	time.Sleep(20 * time.Millisecond)
+	uxs, err := p.ChooseUtxos(10, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(uxs))
+}
+
+func TestUtxoPoolDeposits(t *testing.T) {
+	defer func(tm time.Duration) { CheckUtxoTick = tm }(CheckUtxoTick)
+	CheckUtxoTick = 10 * time.Millisecond
+
+	p := NewUtxoManager(func(addrs []string) ([]Utxo, error) {
+		return makeTestUtxos(10, 20), nil
+	}, nil)
+	c := make(chan Utxo, 10)
+	p.RegisterDepositChan(c)
+	closing := make(chan bool)
+	defer close(closing)
+	go p.Start(closing)
+
+	// the new utxos are sent only once.
+	assert.Equal(t, "ux0", (<-c).GetID())
+	assert.Equal(t, "ux1", (<-c).GetID())
+	select {
+	case u := <-c:
+		t.Fatalf("utxo %s sent again", u.GetID())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -10,4 +10,6 @@ protoc --go_out=./ pp.common.proto \
   pp.utxo.proto \
   pp.transaction.proto \
   pp.admin.proto \
-  pp.output.proto
+  pp.output.proto \
+  pp.trade.proto \
+  pp.subscribe.proto
@@ -63,22 +63,156 @@ func (m *CreateAccountRes) GetCreatedAt() int64 {
 	return 0
 }
 
+// AccountHistory is one balance change of the account.
+type AccountHistory struct {
+	Type             *string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	CoinType         *string `protobuf:"bytes,2,opt,name=coin_type" json:"coin_type,omitempty"`
+	Amount           *int64  `protobuf:"varint,3,opt,name=amount" json:"amount,omitempty"`
+	Ref              *string `protobuf:"bytes,4,opt,name=ref" json:"ref,omitempty"`
+	CreatedAt        *int64  `protobuf:"varint,5,opt,name=created_at" json:"created_at,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AccountHistory) Reset()                    { *m = AccountHistory{} }
+func (m *AccountHistory) String() string            { return proto.CompactTextString(m) }
+func (*AccountHistory) ProtoMessage()               {}
+func (*AccountHistory) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
+
+func (m *AccountHistory) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+func (m *AccountHistory) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *AccountHistory) GetAmount() int64 {
+	if m != nil && m.Amount != nil {
+		return *m.Amount
+	}
+	return 0
+}
+
+func (m *AccountHistory) GetRef() string {
+	if m != nil && m.Ref != nil {
+		return *m.Ref
+	}
+	return ""
+}
+
+func (m *AccountHistory) GetCreatedAt() int64 {
+	if m != nil && m.CreatedAt != nil {
+		return *m.CreatedAt
+	}
+	return 0
+}
+
+type GetAccountHistoryReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinType         *string `protobuf:"bytes,11,opt,name=coin_type" json:"coin_type,omitempty"`
+	Start            *int64  `protobuf:"varint,12,opt,name=start" json:"start,omitempty"`
+	End              *int64  `protobuf:"varint,13,opt,name=end" json:"end,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAccountHistoryReq) Reset()                    { *m = GetAccountHistoryReq{} }
+func (m *GetAccountHistoryReq) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountHistoryReq) ProtoMessage()               {}
+func (*GetAccountHistoryReq) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+
+func (m *GetAccountHistoryReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *GetAccountHistoryReq) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *GetAccountHistoryReq) GetStart() int64 {
+	if m != nil && m.Start != nil {
+		return *m.Start
+	}
+	return 0
+}
+
+func (m *GetAccountHistoryReq) GetEnd() int64 {
+	if m != nil && m.End != nil {
+		return *m.End
+	}
+	return 0
+}
+
+type GetAccountHistoryRes struct {
+	Result           *Result           `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Pubkey           *string           `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	History          []*AccountHistory `protobuf:"bytes,11,rep,name=history" json:"history,omitempty"`
+	XXX_unrecognized []byte            `json:"-"`
+}
+
+func (m *GetAccountHistoryRes) Reset()                    { *m = GetAccountHistoryRes{} }
+func (m *GetAccountHistoryRes) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountHistoryRes) ProtoMessage()               {}
+func (*GetAccountHistoryRes) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
+
+func (m *GetAccountHistoryRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetAccountHistoryRes) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *GetAccountHistoryRes) GetHistory() []*AccountHistory {
+	if m != nil {
+		return m.History
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*CreateAccountReq)(nil), "pp.CreateAccountReq")
 	proto.RegisterType((*CreateAccountRes)(nil), "pp.CreateAccountRes")
+	proto.RegisterType((*AccountHistory)(nil), "pp.AccountHistory")
+	proto.RegisterType((*GetAccountHistoryReq)(nil), "pp.GetAccountHistoryReq")
+	proto.RegisterType((*GetAccountHistoryRes)(nil), "pp.GetAccountHistoryRes")
 }
 
 func init() { proto.RegisterFile("pp.account.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 140 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x28, 0x28, 0xd0, 0x4b,
-	0x4c, 0x4e, 0xce, 0x2f, 0xcd, 0x2b, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x28,
-	0x90, 0xe2, 0x2f, 0x28, 0xd0, 0x4b, 0xce, 0xcf, 0xcd, 0xcd, 0xcf, 0x83, 0x08, 0x2a, 0x29, 0x71,
-	0x09, 0x38, 0x17, 0xa5, 0x26, 0x96, 0xa4, 0x3a, 0x42, 0xd4, 0x06, 0xa5, 0x16, 0x0a, 0xf1, 0x71,
-	0xb1, 0x15, 0x94, 0x26, 0x65, 0xa7, 0x56, 0x4a, 0x70, 0x29, 0x30, 0x6a, 0x70, 0x2a, 0x05, 0x61,
-	0xa8, 0x29, 0x16, 0x92, 0xe2, 0x62, 0x2b, 0x4a, 0x2d, 0x2e, 0xcd, 0x29, 0x91, 0x60, 0x54, 0x60,
-	0xd2, 0xe0, 0x36, 0xe2, 0xd2, 0x2b, 0x28, 0xd0, 0x0b, 0x02, 0x8b, 0xa0, 0xeb, 0x17, 0x12, 0xe2,
-	0xe2, 0x4a, 0x06, 0xeb, 0x4f, 0x89, 0x4f, 0x2c, 0x91, 0x10, 0x51, 0x60, 0xd4, 0x60, 0x06, 0x04,
-	0x00, 0x00, 0xff, 0xff, 0xaf, 0xad, 0x53, 0x4e, 0x9f, 0x00, 0x00, 0x00,
+	// 244 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x90, 0x31, 0x4f, 0xc3, 0x30,
+	0x10, 0x46, 0x95, 0xa4, 0x0d, 0xea, 0xb9, 0x0d, 0xc5, 0xea, 0x60, 0x75, 0x8a, 0xc2, 0x92, 0xc9,
+	0x43, 0xff, 0x01, 0x62, 0x80, 0x39, 0x12, 0x73, 0xe5, 0xba, 0x07, 0x54, 0x90, 0xf8, 0x70, 0x2e,
+	0x43, 0xfe, 0x3d, 0x8a, 0x03, 0x43, 0x20, 0x4b, 0xd7, 0x4f, 0xf7, 0xde, 0x93, 0x0d, 0x5b, 0x22,
+	0x6d, 0xac, 0x75, 0x5d, 0xc3, 0x9a, 0xbc, 0x63, 0x27, 0x63, 0xa2, 0xfd, 0x2d, 0x91, 0xb6, 0xae,
+	0xae, 0x5d, 0x33, 0x8e, 0x45, 0x01, 0xdb, 0x47, 0x8f, 0x86, 0xf1, 0x61, 0xbc, 0xad, 0xf0, 0x4b,
+	0x66, 0x90, 0x52, 0x77, 0xfa, 0xc0, 0x5e, 0x41, 0x1e, 0x95, 0xab, 0xa2, 0xfa, 0x77, 0xd3, 0xca,
+	0x3d, 0xa4, 0x1e, 0xdb, 0xee, 0x93, 0x55, 0x94, 0xc7, 0xa5, 0x38, 0x80, 0x26, 0xd2, 0x55, 0x58,
+	0xfe, 0xf2, 0x52, 0x02, 0xd8, 0xc0, 0x9f, 0x8f, 0x86, 0xd5, 0x2e, 0x8f, 0xca, 0xa4, 0x38, 0x41,
+	0xf6, 0x63, 0x7b, 0xbe, 0xb4, 0xec, 0x7c, 0x2f, 0xd7, 0xb0, 0xe0, 0x9e, 0x50, 0x45, 0x81, 0xb9,
+	0x83, 0x95, 0x75, 0x97, 0xe6, 0x18, 0xa6, 0x38, 0x4c, 0x19, 0xa4, 0xa6, 0x1e, 0x08, 0x95, 0x0c,
+	0x0a, 0x29, 0x20, 0xf1, 0xf8, 0xaa, 0x16, 0x33, 0x8d, 0x65, 0x68, 0xbc, 0xc0, 0xee, 0x09, 0x79,
+	0x9a, 0x99, 0x79, 0xdf, 0xb4, 0x25, 0xc2, 0xb4, 0x81, 0x65, 0xcb, 0xc6, 0xb3, 0x5a, 0xff, 0xa6,
+	0xb0, 0x39, 0xab, 0x4d, 0xd0, 0xbe, 0xcd, 0x6a, 0xaf, 0xfb, 0x92, 0x7b, 0xb8, 0x79, 0x1f, 0x49,
+	0x25, 0xf2, 0xa4, 0x14, 0x07, 0x39, 0x1c, 0x4f, 0x9d, 0xdf, 0x03, 0x00, 0x64, 0x00, 0xb3, 0x3c,
+	0xc3, 0x01, 0x00, 0x00,
 }
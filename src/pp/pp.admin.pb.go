@@ -71,24 +71,98 @@ func (m *UpdateCreditRes) GetResult() *Result {
 	return nil
 }
 
+type GetFeesReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetFeesReq) Reset()                    { *m = GetFeesReq{} }
+func (m *GetFeesReq) String() string            { return proto.CompactTextString(m) }
+func (*GetFeesReq) ProtoMessage()               {}
+func (*GetFeesReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{2} }
+
+func (m *GetFeesReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+// FeeRevenue is the accrued trading fees of one coin.
+type FeeRevenue struct {
+	CoinType         *string `protobuf:"bytes,1,opt,name=coin_type" json:"coin_type,omitempty"`
+	Amount           *uint64 `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *FeeRevenue) Reset()                    { *m = FeeRevenue{} }
+func (m *FeeRevenue) String() string            { return proto.CompactTextString(m) }
+func (*FeeRevenue) ProtoMessage()               {}
+func (*FeeRevenue) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{3} }
+
+func (m *FeeRevenue) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *FeeRevenue) GetAmount() uint64 {
+	if m != nil && m.Amount != nil {
+		return *m.Amount
+	}
+	return 0
+}
+
+type GetFeesRes struct {
+	Result           *Result       `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Fees             []*FeeRevenue `protobuf:"bytes,10,rep,name=fees" json:"fees,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
+}
+
+func (m *GetFeesRes) Reset()                    { *m = GetFeesRes{} }
+func (m *GetFeesRes) String() string            { return proto.CompactTextString(m) }
+func (*GetFeesRes) ProtoMessage()               {}
+func (*GetFeesRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{4} }
+
+func (m *GetFeesRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetFeesRes) GetFees() []*FeeRevenue {
+	if m != nil {
+		return m.Fees
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*UpdateCreditReq)(nil), "pp.UpdateCreditReq")
 	proto.RegisterType((*UpdateCreditRes)(nil), "pp.UpdateCreditRes")
+	proto.RegisterType((*GetFeesReq)(nil), "pp.GetFeesReq")
+	proto.RegisterType((*FeeRevenue)(nil), "pp.FeeRevenue")
+	proto.RegisterType((*GetFeesRes)(nil), "pp.GetFeesRes")
 }
 
 func init() { proto.RegisterFile("pp.admin.proto", fileDescriptor11) }
 
 var fileDescriptor11 = []byte{
-	// 162 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x5c, 0xcc, 0xb1, 0xaa, 0x83, 0x30,
-	0x14, 0x87, 0x71, 0xf4, 0x5e, 0x04, 0x8f, 0xa0, 0x34, 0x74, 0x08, 0x0e, 0x45, 0x9c, 0xb2, 0x34,
-	0x43, 0x5f, 0xa1, 0x4f, 0x50, 0xa1, 0x73, 0xb1, 0x26, 0x83, 0xb4, 0xf1, 0xfc, 0xab, 0x27, 0x83,
-	0x6f, 0x5f, 0x2a, 0x9d, 0xba, 0xfe, 0x3e, 0xf8, 0xa8, 0x04, 0x6c, 0xef, 0xc2, 0x38, 0x59, 0xcc,
-	0x2c, 0xac, 0x52, 0xa0, 0xae, 0x00, 0x3b, 0x70, 0x08, 0xfc, 0xc5, 0xf6, 0x42, 0xd5, 0x15, 0xae,
-	0x17, 0x7f, 0x9e, 0xbd, 0x1b, 0xa5, 0xf3, 0x2f, 0x55, 0x52, 0x86, 0x78, 0x7f, 0xf8, 0x55, 0x53,
-	0x93, 0x98, 0x5c, 0xed, 0x28, 0x1f, 0x78, 0x9c, 0x6e, 0xb2, 0xc2, 0xeb, 0xfd, 0x46, 0x25, 0x65,
-	0x7d, 0xe0, 0x38, 0x89, 0x3e, 0x34, 0x89, 0xf9, 0x57, 0x05, 0xfd, 0xb9, 0x45, 0xb4, 0xf9, 0xc4,
-	0xf6, 0xf8, 0xbb, 0x5c, 0x54, 0x4d, 0xd9, 0xec, 0x97, 0xf8, 0x14, 0x9d, 0x34, 0xa9, 0x29, 0x4e,
-	0x64, 0x01, 0xdb, 0x6d, 0xf2, 0x0e, 0x00, 0x00, 0xff, 0xff, 0xb5, 0x78, 0x04, 0x24, 0xa7, 0x00,
-	0x00, 0x00,
+	// 211 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x8e, 0xc1, 0x4a, 0xc3, 0x40,
+	0x14, 0x45, 0x49, 0x5a, 0x02, 0x7d, 0x81, 0x14, 0x07, 0x17, 0x43, 0x29, 0x12, 0x66, 0x35, 0x1b,
+	0x47, 0xe8, 0x2f, 0x08, 0x71, 0x6d, 0xc0, 0xb5, 0xc4, 0xe6, 0x0a, 0x41, 0x33, 0xf3, 0xcc, 0xbc,
+	0x11, 0xfa, 0xf7, 0x92, 0x22, 0xa8, 0x41, 0xba, 0x3d, 0x17, 0xee, 0x39, 0x54, 0x31, 0xbb, 0xae,
+	0x1f, 0x07, 0xef, 0x78, 0x0a, 0x12, 0x54, 0xce, 0xbc, 0xdb, 0x32, 0xbb, 0x63, 0x18, 0xc7, 0xf0,
+	0x0d, 0xcd, 0x23, 0x6d, 0x9f, 0xb8, 0xef, 0x04, 0xf7, 0x13, 0xfa, 0x41, 0x5a, 0x7c, 0xa8, 0x8a,
+	0x0a, 0x4e, 0x2f, 0x6f, 0x38, 0x69, 0xaa, 0x33, 0xbb, 0x51, 0x57, 0xb4, 0x39, 0x86, 0xc1, 0x3f,
+	0xcb, 0x89, 0xa1, 0xaf, 0xcf, 0xa8, 0xa2, 0xa2, 0x1b, 0x43, 0xf2, 0xa2, 0x6f, 0xea, 0xcc, 0xae,
+	0x55, 0x49, 0xab, 0x3e, 0x8a, 0xb6, 0xf3, 0x68, 0x6e, 0x97, 0x97, 0x51, 0xed, 0xa8, 0x98, 0x10,
+	0xd3, 0xbb, 0xe8, 0xac, 0xce, 0x6d, 0x79, 0x20, 0xc7, 0xec, 0xda, 0x33, 0x31, 0x7b, 0xa2, 0x07,
+	0x48, 0x03, 0xc4, 0x7f, 0xe4, 0xe6, 0x8e, 0xa8, 0x01, 0x5a, 0x7c, 0xc2, 0x27, 0xfc, 0x4d, 0xc9,
+	0x16, 0x29, 0xf9, 0x9c, 0x62, 0x9a, 0x5f, 0x77, 0x17, 0xc5, 0x6a, 0x4f, 0xeb, 0x57, 0x20, 0x6a,
+	0xaa, 0x57, 0xb6, 0x3c, 0x54, 0xf3, 0xf2, 0xa3, 0xfa, 0x1a, 0x00, 0x25, 0xc9, 0x81, 0xf4, 0x3e,
+	0x01, 0x00, 0x00,
 }
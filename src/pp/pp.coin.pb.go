@@ -55,21 +55,121 @@ func (m *CoinsRes) GetCoins() []string {
 	return nil
 }
 
+type GetPairsReq struct {
+	Pubkey           *string `protobuf:"bytes,1,opt,name=pubkey" json:"pubkey,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetPairsReq) Reset()                    { *m = GetPairsReq{} }
+func (m *GetPairsReq) String() string            { return proto.CompactTextString(m) }
+func (*GetPairsReq) ProtoMessage()               {}
+func (*GetPairsReq) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{2} }
+
+func (m *GetPairsReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+type Pair struct {
+	Name             *string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	TickSize         *uint64 `protobuf:"varint,2,opt,name=tick_size" json:"tick_size,omitempty"`
+	LotSize          *uint64 `protobuf:"varint,3,opt,name=lot_size" json:"lot_size,omitempty"`
+	MinOrder         *uint64 `protobuf:"varint,4,opt,name=min_order" json:"min_order,omitempty"`
+	Enabled          *bool   `protobuf:"varint,5,opt,name=enabled" json:"enabled,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *Pair) Reset()                    { *m = Pair{} }
+func (m *Pair) String() string            { return proto.CompactTextString(m) }
+func (*Pair) ProtoMessage()               {}
+func (*Pair) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{3} }
+
+func (m *Pair) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *Pair) GetTickSize() uint64 {
+	if m != nil && m.TickSize != nil {
+		return *m.TickSize
+	}
+	return 0
+}
+
+func (m *Pair) GetLotSize() uint64 {
+	if m != nil && m.LotSize != nil {
+		return *m.LotSize
+	}
+	return 0
+}
+
+func (m *Pair) GetMinOrder() uint64 {
+	if m != nil && m.MinOrder != nil {
+		return *m.MinOrder
+	}
+	return 0
+}
+
+func (m *Pair) GetEnabled() bool {
+	if m != nil && m.Enabled != nil {
+		return *m.Enabled
+	}
+	return false
+}
+
+type PairsRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Pairs            []*Pair `protobuf:"bytes,10,rep,name=pairs" json:"pairs,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *PairsRes) Reset()                    { *m = PairsRes{} }
+func (m *PairsRes) String() string            { return proto.CompactTextString(m) }
+func (*PairsRes) ProtoMessage()               {}
+func (*PairsRes) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{4} }
+
+func (m *PairsRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *PairsRes) GetPairs() []*Pair {
+	if m != nil {
+		return m.Pairs
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*GetCoinsReq)(nil), "pp.GetCoinsReq")
 	proto.RegisterType((*CoinsRes)(nil), "pp.CoinsRes")
+	proto.RegisterType((*GetPairsReq)(nil), "pp.GetPairsReq")
+	proto.RegisterType((*Pair)(nil), "pp.Pair")
+	proto.RegisterType((*PairsRes)(nil), "pp.PairsRes")
 }
 
 func init() { proto.RegisterFile("pp.coin.proto", fileDescriptor7) }
 
 var fileDescriptor7 = []byte{
-	// 128 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0x2d, 0x28, 0xd0, 0x4b,
-	0xce, 0xcf, 0xcc, 0xd3, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x28, 0x90, 0xe2, 0x07,
-	0x0b, 0xe5, 0xe6, 0xe6, 0x43, 0x05, 0x95, 0x64, 0xb9, 0xb8, 0xdd, 0x53, 0x4b, 0x9c, 0xf3, 0x33,
-	0xf3, 0x8a, 0x83, 0x52, 0x0b, 0x85, 0xf8, 0xb8, 0xd8, 0x0a, 0x4a, 0x93, 0xb2, 0x53, 0x2b, 0x25,
-	0x18, 0x15, 0x18, 0x35, 0x38, 0x95, 0x4c, 0xb9, 0x38, 0xa0, 0x72, 0xc5, 0x42, 0x52, 0x5c, 0x6c,
-	0x45, 0xa9, 0xc5, 0xa5, 0x39, 0x25, 0x12, 0x8c, 0x0a, 0x4c, 0x1a, 0xdc, 0x46, 0x5c, 0x7a, 0x05,
-	0x05, 0x7a, 0x41, 0x60, 0x11, 0x21, 0x5e, 0x2e, 0x56, 0x90, 0x4d, 0xc5, 0x12, 0x5c, 0x0a, 0xcc,
-	0x1a, 0x9c, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x06, 0xd6, 0x8f, 0x33, 0x7a, 0x00, 0x00, 0x00,
+	// 222 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0xce, 0x31, 0x4f, 0xc3, 0x30,
+	0x10, 0x05, 0x60, 0x39, 0x4d, 0x8a, 0x73, 0xa1, 0x94, 0x7a, 0xc1, 0xaa, 0x84, 0x64, 0x65, 0xf2,
+	0x94, 0xa1, 0x12, 0x33, 0x03, 0x03, 0x2b, 0xea, 0x8e, 0xaa, 0xb4, 0xbd, 0xc1, 0x6a, 0x62, 0x1f,
+	0xb6, 0x3b, 0xc0, 0xaf, 0x47, 0x76, 0x3d, 0x22, 0xd6, 0xef, 0xd9, 0xf7, 0x1e, 0xac, 0x88, 0x86,
+	0x93, 0x33, 0x76, 0x20, 0xef, 0xa2, 0x13, 0x15, 0xd1, 0x76, 0x9d, 0x69, 0x9e, 0x5d, 0xc1, 0xfe,
+	0x19, 0xba, 0x77, 0x8c, 0x6f, 0xce, 0xd8, 0xb0, 0xc7, 0x2f, 0xf1, 0x00, 0x4b, 0xba, 0x1e, 0x2f,
+	0xf8, 0x2d, 0x99, 0x62, 0xba, 0xed, 0x5f, 0x80, 0x97, 0x2c, 0x88, 0x2d, 0x2c, 0x3d, 0x86, 0xeb,
+	0x14, 0x25, 0x53, 0x95, 0xee, 0x76, 0x30, 0x10, 0x0d, 0xfb, 0x2c, 0x62, 0x05, 0x4d, 0x6a, 0x0a,
+	0x12, 0xd4, 0x42, 0xb7, 0xe5, 0xea, 0xc7, 0x68, 0xfc, 0x9f, 0x57, 0x3f, 0xa1, 0x4e, 0x99, 0xb8,
+	0x87, 0xda, 0x8e, 0x33, 0xde, 0x54, 0x6c, 0xa0, 0x8d, 0xe6, 0x74, 0x39, 0x04, 0xf3, 0x83, 0xb2,
+	0x52, 0x4c, 0xd7, 0xe2, 0x11, 0xf8, 0xe4, 0xe2, 0x4d, 0x16, 0x59, 0x36, 0xd0, 0xce, 0xc6, 0x1e,
+	0x9c, 0x3f, 0xa3, 0x97, 0x75, 0xa6, 0x35, 0xdc, 0xa1, 0x1d, 0x8f, 0x13, 0x9e, 0x65, 0xa3, 0x98,
+	0xe6, 0xfd, 0x2b, 0xf0, 0x52, 0xfd, 0xff, 0xe8, 0x27, 0x68, 0x28, 0xbd, 0xcb, 0xa3, 0xbb, 0x1d,
+	0x4f, 0x51, 0xfa, 0xf8, 0x3b, 0x00, 0x12, 0xb0, 0xe7, 0x82, 0x39, 0x01, 0x00, 0x00,
 }
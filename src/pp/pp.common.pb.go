@@ -19,6 +19,8 @@ It is generated from these files:
 	pp.transaction.proto
 	pp.admin.proto
 	pp.output.proto
+	pp.trade.proto
+	pp.subscribe.proto
 
 It has these top-level messages:
 	Result
@@ -27,10 +29,16 @@ It has these top-level messages:
 	EncryptRes
 	CreateAccountReq
 	CreateAccountRes
+	AccountHistory
+	GetAccountHistoryReq
+	GetAccountHistoryRes
 	GetDepositAddrReq
 	GetDepositAddrRes
 	WithdrawalReq
 	WithdrawalRes
+	WithdrawPolicy
+	GetWithdrawPolicyReq
+	GetWithdrawPolicyRes
 	Balance
 	GetAccountBalanceReq
 	GetAccountBalanceRes
@@ -41,9 +49,23 @@ It has these top-level messages:
 	Order
 	GetOrderReq
 	GetOrderRes
+	CancelOrderReq
+	CancelOrderRes
+	ConditionalOrderReq
+	ConditionalOrder
+	GetConditionalOrderReq
+	GetConditionalOrderRes
+	GetDepthReq
+	PriceLevel
+	GetDepthRes
 	GetCoinsReq
 	CoinsRes
+	GetPairsReq
+	Pair
+	PairsRes
 	Request
+	VersionReq
+	VersionRes
 	GetUtxoReq
 	BtcUtxo
 	SkyUtxo
@@ -64,9 +86,28 @@ It has these top-level messages:
 	SkyTxOutput
 	UpdateCreditReq
 	UpdateCreditRes
+	GetFeesReq
+	FeeRevenue
+	GetFeesRes
 	GetOutputReq
 	GetOutputRes
 	Output
+	Trade
+	GetTradesReq
+	GetTradesRes
+	Fill
+	GetAccountFillsReq
+	GetAccountFillsRes
+	GetTickerReq
+	GetTickerRes
+	Candle
+	GetCandlesReq
+	GetCandlesRes
+	SubscribeReq
+	SubscribeRes
+	BookEvent
+	TradesEvent
+	AccountEvent
 */
 package pp
 
@@ -95,7 +136,10 @@ const (
 	ErrCode_UnAuthorized    ErrCode = 31
 	ErrCode_NotExits        ErrCode = 32
 	ErrCode_AlreadyExits    ErrCode = 33
+	ErrCode_ReplayedRequest ErrCode = 34
+	ErrCode_RateLimited     ErrCode = 35
 	ErrCode_ServerError     ErrCode = 40
+	ErrCode_ServerBusy      ErrCode = 41
 	ErrCode_BroadcastTxFail ErrCode = 50
 )
 
@@ -107,7 +151,10 @@ var ErrCode_name = map[int32]string{
 	31: "UnAuthorized",
 	32: "NotExits",
 	33: "AlreadyExits",
+	34: "ReplayedRequest",
+	35: "RateLimited",
 	40: "ServerError",
+	41: "ServerBusy",
 	50: "BroadcastTxFail",
 }
 var ErrCode_value = map[string]int32{
@@ -118,7 +165,10 @@ var ErrCode_value = map[string]int32{
 	"UnAuthorized":    31,
 	"NotExits":        32,
 	"AlreadyExits":    33,
+	"ReplayedRequest": 34,
+	"RateLimited":     35,
 	"ServerError":     40,
+	"ServerBusy":      41,
 	"BroadcastTxFail": 50,
 }
 
@@ -144,6 +194,7 @@ type Result struct {
 	Success          *bool   `protobuf:"varint,1,req,name=success" json:"success,omitempty"`
 	Errcode          *int32  `protobuf:"varint,2,opt,name=errcode" json:"errcode,omitempty"`
 	Reason           *string `protobuf:"bytes,3,opt,name=reason" json:"reason,omitempty"`
+	RetryAfter       *uint64 `protobuf:"varint,4,opt,name=retry_after" json:"retry_after,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -173,6 +224,13 @@ func (m *Result) GetReason() string {
 	return ""
 }
 
+func (m *Result) GetRetryAfter() uint64 {
+	if m != nil && m.RetryAfter != nil {
+		return *m.RetryAfter
+	}
+	return 0
+}
+
 type EmptyRes struct {
 	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
@@ -199,21 +257,24 @@ func init() {
 func init() { proto.RegisterFile("pp.common.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 252 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x3c, 0xcd, 0x31, 0x4f, 0xb4, 0x40,
-	0x10, 0xc6, 0xf1, 0x17, 0xde, 0x78, 0xe0, 0x70, 0x91, 0xcd, 0xaa, 0x09, 0xb1, 0x11, 0xaf, 0x30,
-	0xc4, 0x82, 0xe2, 0x4a, 0xbb, 0xd3, 0x70, 0xa5, 0x31, 0x9c, 0xc6, 0x7a, 0x85, 0x89, 0x12, 0x81,
-	0x59, 0x67, 0x77, 0xcd, 0xe1, 0xc7, 0xf1, 0x93, 0x9a, 0x3d, 0x12, 0xdb, 0xdf, 0x93, 0xf9, 0x0f,
-	0xa4, 0x5a, 0x97, 0x0d, 0x0d, 0x03, 0x8d, 0xa5, 0x66, 0xb2, 0x24, 0x43, 0xad, 0x57, 0xb7, 0xb0,
-	0xa8, 0xd1, 0xb8, 0xde, 0xca, 0x14, 0x22, 0xe3, 0x9a, 0x06, 0x8d, 0xc9, 0x82, 0x3c, 0x2c, 0x62,
-	0x0f, 0xc8, 0xdc, 0x50, 0x8b, 0x59, 0x98, 0x07, 0xc5, 0x91, 0x3c, 0x81, 0x05, 0xa3, 0x32, 0x34,
-	0x66, 0xff, 0xf3, 0xa0, 0x38, 0x5e, 0x5d, 0x43, 0x5c, 0x0d, 0xda, 0x4e, 0x35, 0x1a, 0x79, 0xe1,
-	0x37, 0xdf, 0x39, 0x1c, 0x27, 0x6b, 0x28, 0xb5, 0x2e, 0xe7, 0xf2, 0xcd, 0x4f, 0x00, 0x51, 0xc5,
-	0x7c, 0x4f, 0x2d, 0xca, 0x04, 0xa2, 0xdd, 0xfc, 0x45, 0xfc, 0x93, 0x29, 0x24, 0x2f, 0x4c, 0xe3,
-	0xdb, 0x96, 0x78, 0x50, 0x56, 0xc0, 0x1f, 0x3c, 0xba, 0xd7, 0x0f, 0x9c, 0xc4, 0x99, 0x14, 0xb0,
-	0x3c, 0x40, 0x8d, 0x9f, 0x0e, 0x8d, 0x15, 0xe7, 0x5e, 0x9e, 0xc7, 0x8d, 0xb3, 0xef, 0xc4, 0xdd,
-	0x37, 0xb6, 0xe2, 0x52, 0x2e, 0x21, 0x7e, 0x20, 0x5b, 0xed, 0x3b, 0x6b, 0x44, 0xee, 0xf7, 0x4d,
-	0xcf, 0xa8, 0xda, 0x69, 0x96, 0x2b, 0x1f, 0xdd, 0x21, 0x7f, 0x21, 0x57, 0xcc, 0xc4, 0xa2, 0x90,
-	0xa7, 0x90, 0xde, 0x31, 0xa9, 0xb6, 0x51, 0xc6, 0x3e, 0xed, 0xb7, 0xaa, 0xeb, 0xc5, 0xfa, 0x37,
-	0x00, 0x00, 0xff, 0xff, 0x94, 0x6d, 0x77, 0xf5, 0x1e, 0x01, 0x00, 0x00,
+	// 294 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x3c, 0xcd, 0xc1, 0x4e, 0x83, 0x40,
+	0x10, 0xc6, 0x71, 0xa9, 0xb5, 0xad, 0x43, 0x53, 0x36, 0x5b, 0x4d, 0x88, 0x17, 0x11, 0x13, 0x83,
+	0x1e, 0x38, 0xf4, 0x0d, 0x5a, 0x43, 0x4f, 0x46, 0x0d, 0xd5, 0x78, 0x34, 0x2b, 0x3b, 0x2a, 0x11,
+	0xd8, 0x75, 0x76, 0x31, 0xc5, 0x27, 0xf5, 0x71, 0xcc, 0x96, 0xe8, 0xf5, 0x37, 0x99, 0xff, 0x07,
+	0x81, 0xd6, 0x69, 0xa1, 0xea, 0x5a, 0x35, 0xa9, 0x26, 0x65, 0x15, 0x1f, 0x68, 0x1d, 0xdf, 0xc1,
+	0x28, 0x47, 0xd3, 0x56, 0x96, 0x07, 0x30, 0x36, 0x6d, 0x51, 0xa0, 0x31, 0xa1, 0x17, 0x0d, 0x92,
+	0x89, 0x03, 0x24, 0x2a, 0x94, 0xc4, 0x70, 0x10, 0x79, 0xc9, 0x01, 0x9f, 0xc1, 0x88, 0x50, 0x18,
+	0xd5, 0x84, 0xfb, 0x91, 0x97, 0x1c, 0xf2, 0x39, 0xf8, 0x84, 0x96, 0xba, 0x67, 0xf1, 0x6a, 0x91,
+	0xc2, 0x61, 0xe4, 0x25, 0xc3, 0xf8, 0x02, 0x26, 0x59, 0xad, 0x6d, 0x97, 0xa3, 0xe1, 0x27, 0xee,
+	0xc1, 0xc5, 0x77, 0x45, 0x7f, 0x01, 0xa9, 0xd6, 0x69, 0x3f, 0x77, 0xf5, 0xe3, 0xc1, 0x38, 0x23,
+	0xba, 0x56, 0x12, 0xb9, 0x0f, 0xe3, 0x4d, 0x3f, 0xcd, 0xf6, 0x78, 0x00, 0xfe, 0x13, 0xa9, 0xe6,
+	0x6d, 0xad, 0xa8, 0x16, 0x96, 0xc1, 0x3f, 0xdc, 0xb7, 0x2f, 0x1f, 0xd8, 0xb1, 0x23, 0xce, 0x60,
+	0xba, 0x83, 0x1c, 0x3f, 0x5b, 0x34, 0x96, 0x1d, 0x3b, 0x79, 0x6c, 0x96, 0xad, 0x7d, 0x57, 0x54,
+	0x7e, 0xa3, 0x64, 0xa7, 0x7c, 0x0a, 0x93, 0x5b, 0x65, 0xb3, 0x6d, 0x69, 0x0d, 0x8b, 0xdc, 0x7d,
+	0x59, 0x11, 0x0a, 0xd9, 0xf5, 0x72, 0xc6, 0xe7, 0x10, 0xe4, 0xa8, 0x2b, 0xd1, 0xa1, 0xfc, 0xcb,
+	0xc4, 0x6e, 0x29, 0x17, 0x16, 0x6f, 0xca, 0xba, 0xb4, 0x28, 0xd9, 0xb9, 0x83, 0x0d, 0xd2, 0x17,
+	0x52, 0x46, 0xa4, 0x88, 0x25, 0x7c, 0x06, 0xd0, 0xc3, 0xaa, 0x35, 0x1d, 0xbb, 0x74, 0x99, 0x15,
+	0x29, 0x21, 0x0b, 0x61, 0xec, 0xc3, 0x76, 0x2d, 0xca, 0x8a, 0x2d, 0x7e, 0x07, 0x00, 0x5d, 0x73,
+	0x38, 0x86, 0x69, 0x01, 0x00, 0x00,
 }
@@ -19,6 +19,9 @@ type OrderReq struct {
 	Type             *string `protobuf:"bytes,12,opt,name=type" json:"type,omitempty"`
 	Amount           *uint64 `protobuf:"varint,13,opt,name=amount" json:"amount,omitempty"`
 	Price            *uint64 `protobuf:"varint,14,opt,name=price" json:"price,omitempty"`
+	Kind             *string `protobuf:"bytes,15,opt,name=kind" json:"kind,omitempty"`
+	TimeInForce      *string `protobuf:"bytes,16,opt,name=time_in_force" json:"time_in_force,omitempty"`
+	PostOnly         *bool   `protobuf:"varint,17,opt,name=post_only" json:"post_only,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -62,6 +65,27 @@ func (m *OrderReq) GetPrice() uint64 {
 	return 0
 }
 
+func (m *OrderReq) GetKind() string {
+	if m != nil && m.Kind != nil {
+		return *m.Kind
+	}
+	return ""
+}
+
+func (m *OrderReq) GetTimeInForce() string {
+	if m != nil && m.TimeInForce != nil {
+		return *m.TimeInForce
+	}
+	return ""
+}
+
+func (m *OrderReq) GetPostOnly() bool {
+	if m != nil && m.PostOnly != nil {
+		return *m.PostOnly
+	}
+	return false
+}
+
 type OrderRes struct {
 	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
 	OrderId          *uint64 `protobuf:"varint,11,opt,name=order_id" json:"order_id,omitempty"`
@@ -234,34 +258,436 @@ func (m *GetOrderRes) GetOrders() []*Order {
 	return nil
 }
 
+type CancelOrderReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinPair         *string `protobuf:"bytes,11,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	OrderId          *uint64 `protobuf:"varint,12,opt,name=order_id" json:"order_id,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *CancelOrderReq) Reset()                    { *m = CancelOrderReq{} }
+func (m *CancelOrderReq) String() string            { return proto.CompactTextString(m) }
+func (*CancelOrderReq) ProtoMessage()               {}
+func (*CancelOrderReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{5} }
+
+func (m *CancelOrderReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *CancelOrderReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *CancelOrderReq) GetOrderId() uint64 {
+	if m != nil && m.OrderId != nil {
+		return *m.OrderId
+	}
+	return 0
+}
+
+type CancelOrderRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	OrderId          *uint64 `protobuf:"varint,10,opt,name=order_id" json:"order_id,omitempty"`
+	Refund           *uint64 `protobuf:"varint,11,opt,name=refund" json:"refund,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *CancelOrderRes) Reset()                    { *m = CancelOrderRes{} }
+func (m *CancelOrderRes) String() string            { return proto.CompactTextString(m) }
+func (*CancelOrderRes) ProtoMessage()               {}
+func (*CancelOrderRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{6} }
+
+func (m *CancelOrderRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *CancelOrderRes) GetOrderId() uint64 {
+	if m != nil && m.OrderId != nil {
+		return *m.OrderId
+	}
+	return 0
+}
+
+func (m *CancelOrderRes) GetRefund() uint64 {
+	if m != nil && m.Refund != nil {
+		return *m.Refund
+	}
+	return 0
+}
+
+type ConditionalOrderReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinPair         *string `protobuf:"bytes,11,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Type             *string `protobuf:"bytes,12,opt,name=type" json:"type,omitempty"`
+	Amount           *uint64 `protobuf:"varint,13,opt,name=amount" json:"amount,omitempty"`
+	Price            *uint64 `protobuf:"varint,14,opt,name=price" json:"price,omitempty"`
+	Kind             *string `protobuf:"bytes,15,opt,name=kind" json:"kind,omitempty"`
+	TimeInForce      *string `protobuf:"bytes,16,opt,name=time_in_force" json:"time_in_force,omitempty"`
+	Trigger          *string `protobuf:"bytes,17,opt,name=trigger" json:"trigger,omitempty"`
+	TriggerPrice     *uint64 `protobuf:"varint,18,opt,name=trigger_price" json:"trigger_price,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *ConditionalOrderReq) Reset()                    { *m = ConditionalOrderReq{} }
+func (m *ConditionalOrderReq) String() string            { return proto.CompactTextString(m) }
+func (*ConditionalOrderReq) ProtoMessage()               {}
+func (*ConditionalOrderReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{7} }
+
+func (m *ConditionalOrderReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *ConditionalOrderReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *ConditionalOrderReq) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+func (m *ConditionalOrderReq) GetAmount() uint64 {
+	if m != nil && m.Amount != nil {
+		return *m.Amount
+	}
+	return 0
+}
+
+func (m *ConditionalOrderReq) GetPrice() uint64 {
+	if m != nil && m.Price != nil {
+		return *m.Price
+	}
+	return 0
+}
+
+func (m *ConditionalOrderReq) GetKind() string {
+	if m != nil && m.Kind != nil {
+		return *m.Kind
+	}
+	return ""
+}
+
+func (m *ConditionalOrderReq) GetTimeInForce() string {
+	if m != nil && m.TimeInForce != nil {
+		return *m.TimeInForce
+	}
+	return ""
+}
+
+func (m *ConditionalOrderReq) GetTrigger() string {
+	if m != nil && m.Trigger != nil {
+		return *m.Trigger
+	}
+	return ""
+}
+
+func (m *ConditionalOrderReq) GetTriggerPrice() uint64 {
+	if m != nil && m.TriggerPrice != nil {
+		return *m.TriggerPrice
+	}
+	return 0
+}
+
+type ConditionalOrder struct {
+	Id               *uint64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Type             *string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	Kind             *string `protobuf:"bytes,3,opt,name=kind" json:"kind,omitempty"`
+	TimeInForce      *string `protobuf:"bytes,4,opt,name=time_in_force" json:"time_in_force,omitempty"`
+	Price            *uint64 `protobuf:"varint,5,opt,name=price" json:"price,omitempty"`
+	Amount           *uint64 `protobuf:"varint,6,opt,name=amount" json:"amount,omitempty"`
+	Trigger          *string `protobuf:"bytes,7,opt,name=trigger" json:"trigger,omitempty"`
+	TriggerPrice     *uint64 `protobuf:"varint,8,opt,name=trigger_price" json:"trigger_price,omitempty"`
+	CreatedAt        *int64  `protobuf:"varint,9,opt,name=created_at" json:"created_at,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *ConditionalOrder) Reset()                    { *m = ConditionalOrder{} }
+func (m *ConditionalOrder) String() string            { return proto.CompactTextString(m) }
+func (*ConditionalOrder) ProtoMessage()               {}
+func (*ConditionalOrder) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{8} }
+
+func (m *ConditionalOrder) GetId() uint64 {
+	if m != nil && m.Id != nil {
+		return *m.Id
+	}
+	return 0
+}
+
+func (m *ConditionalOrder) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+func (m *ConditionalOrder) GetKind() string {
+	if m != nil && m.Kind != nil {
+		return *m.Kind
+	}
+	return ""
+}
+
+func (m *ConditionalOrder) GetTimeInForce() string {
+	if m != nil && m.TimeInForce != nil {
+		return *m.TimeInForce
+	}
+	return ""
+}
+
+func (m *ConditionalOrder) GetPrice() uint64 {
+	if m != nil && m.Price != nil {
+		return *m.Price
+	}
+	return 0
+}
+
+func (m *ConditionalOrder) GetAmount() uint64 {
+	if m != nil && m.Amount != nil {
+		return *m.Amount
+	}
+	return 0
+}
+
+func (m *ConditionalOrder) GetTrigger() string {
+	if m != nil && m.Trigger != nil {
+		return *m.Trigger
+	}
+	return ""
+}
+
+func (m *ConditionalOrder) GetTriggerPrice() uint64 {
+	if m != nil && m.TriggerPrice != nil {
+		return *m.TriggerPrice
+	}
+	return 0
+}
+
+func (m *ConditionalOrder) GetCreatedAt() int64 {
+	if m != nil && m.CreatedAt != nil {
+		return *m.CreatedAt
+	}
+	return 0
+}
+
+type GetConditionalOrderReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinPair         *string `protobuf:"bytes,11,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetConditionalOrderReq) Reset()                    { *m = GetConditionalOrderReq{} }
+func (m *GetConditionalOrderReq) String() string            { return proto.CompactTextString(m) }
+func (*GetConditionalOrderReq) ProtoMessage()               {}
+func (*GetConditionalOrderReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{9} }
+
+func (m *GetConditionalOrderReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *GetConditionalOrderReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+type GetConditionalOrderRes struct {
+	Result           *Result             `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string             `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Orders           []*ConditionalOrder `protobuf:"bytes,11,rep,name=orders" json:"orders,omitempty"`
+	XXX_unrecognized []byte              `json:"-"`
+}
+
+func (m *GetConditionalOrderRes) Reset()                    { *m = GetConditionalOrderRes{} }
+func (m *GetConditionalOrderRes) String() string            { return proto.CompactTextString(m) }
+func (*GetConditionalOrderRes) ProtoMessage()               {}
+func (*GetConditionalOrderRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{10} }
+
+func (m *GetConditionalOrderRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetConditionalOrderRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetConditionalOrderRes) GetOrders() []*ConditionalOrder {
+	if m != nil {
+		return m.Orders
+	}
+	return nil
+}
+
+type GetDepthReq struct {
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Levels           *int32  `protobuf:"varint,11,opt,name=levels" json:"levels,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetDepthReq) Reset()                    { *m = GetDepthReq{} }
+func (m *GetDepthReq) String() string            { return proto.CompactTextString(m) }
+func (*GetDepthReq) ProtoMessage()               {}
+func (*GetDepthReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{11} }
+
+func (m *GetDepthReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetDepthReq) GetLevels() int32 {
+	if m != nil && m.Levels != nil {
+		return *m.Levels
+	}
+	return 0
+}
+
+// PriceLevel is the total rest amount of the orders at one price.
+type PriceLevel struct {
+	Price            *uint64 `protobuf:"varint,1,opt,name=price" json:"price,omitempty"`
+	Amount           *uint64 `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *PriceLevel) Reset()                    { *m = PriceLevel{} }
+func (m *PriceLevel) String() string            { return proto.CompactTextString(m) }
+func (*PriceLevel) ProtoMessage()               {}
+func (*PriceLevel) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{12} }
+
+func (m *PriceLevel) GetPrice() uint64 {
+	if m != nil && m.Price != nil {
+		return *m.Price
+	}
+	return 0
+}
+
+func (m *PriceLevel) GetAmount() uint64 {
+	if m != nil && m.Amount != nil {
+		return *m.Amount
+	}
+	return 0
+}
+
+type GetDepthRes struct {
+	Result           *Result       `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string       `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Bids             []*PriceLevel `protobuf:"bytes,11,rep,name=bids" json:"bids,omitempty"`
+	Asks             []*PriceLevel `protobuf:"bytes,12,rep,name=asks" json:"asks,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
+}
+
+func (m *GetDepthRes) Reset()                    { *m = GetDepthRes{} }
+func (m *GetDepthRes) String() string            { return proto.CompactTextString(m) }
+func (*GetDepthRes) ProtoMessage()               {}
+func (*GetDepthRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{13} }
+
+func (m *GetDepthRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetDepthRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetDepthRes) GetBids() []*PriceLevel {
+	if m != nil {
+		return m.Bids
+	}
+	return nil
+}
+
+func (m *GetDepthRes) GetAsks() []*PriceLevel {
+	if m != nil {
+		return m.Asks
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*OrderReq)(nil), "pp.OrderReq")
 	proto.RegisterType((*OrderRes)(nil), "pp.OrderRes")
 	proto.RegisterType((*Order)(nil), "pp.Order")
 	proto.RegisterType((*GetOrderReq)(nil), "pp.GetOrderReq")
 	proto.RegisterType((*GetOrderRes)(nil), "pp.GetOrderRes")
+	proto.RegisterType((*CancelOrderReq)(nil), "pp.CancelOrderReq")
+	proto.RegisterType((*CancelOrderRes)(nil), "pp.CancelOrderRes")
+	proto.RegisterType((*ConditionalOrderReq)(nil), "pp.ConditionalOrderReq")
+	proto.RegisterType((*ConditionalOrder)(nil), "pp.ConditionalOrder")
+	proto.RegisterType((*GetConditionalOrderReq)(nil), "pp.GetConditionalOrderReq")
+	proto.RegisterType((*GetConditionalOrderRes)(nil), "pp.GetConditionalOrderRes")
+	proto.RegisterType((*GetDepthReq)(nil), "pp.GetDepthReq")
+	proto.RegisterType((*PriceLevel)(nil), "pp.PriceLevel")
+	proto.RegisterType((*GetDepthRes)(nil), "pp.GetDepthRes")
 }
 
 func init() { proto.RegisterFile("pp.order.proto", fileDescriptor6) }
 
 var fileDescriptor6 = []byte{
-	// 285 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x90, 0xb1, 0x4e, 0xc3, 0x30,
-	0x10, 0x86, 0x95, 0x3a, 0x0d, 0xcd, 0xb9, 0x0d, 0xc5, 0x12, 0x92, 0xe9, 0x14, 0x65, 0xca, 0x94,
-	0xa1, 0x13, 0x6f, 0xc0, 0x88, 0xd4, 0xb1, 0x4b, 0x64, 0x92, 0x43, 0x8a, 0x20, 0xf1, 0xe1, 0x5c,
-	0x86, 0xbe, 0x3d, 0xca, 0xa1, 0x96, 0xc2, 0x00, 0xe3, 0xfd, 0xf6, 0xfd, 0xdf, 0xe9, 0x83, 0x8c,
-	0xa8, 0xf2, 0xa1, 0xc5, 0x50, 0x51, 0xf0, 0xec, 0xcd, 0x82, 0x68, 0x77, 0x4b, 0x54, 0x35, 0xbe,
-	0xef, 0xfd, 0xf0, 0x15, 0x16, 0x47, 0x58, 0x3d, 0xcf, 0x7f, 0x0e, 0xf8, 0x61, 0x32, 0x48, 0x68,
-	0x7a, 0x79, 0xc3, 0x93, 0x85, 0x3c, 0x2a, 0x53, 0x73, 0x07, 0x69, 0xe3, 0xbb, 0xa1, 0x26, 0xd7,
-	0x05, 0xab, 0x25, 0x5a, 0x43, 0xcc, 0x27, 0x42, 0xbb, 0x96, 0x29, 0x83, 0xc4, 0xf5, 0x7e, 0x1a,
-	0xd8, 0x6e, 0xf2, 0xa8, 0x8c, 0xcd, 0x06, 0x96, 0x14, 0xba, 0x06, 0x6d, 0x36, 0x8f, 0xc5, 0xe3,
-	0xa5, 0x7b, 0x34, 0x3b, 0x48, 0x02, 0x8e, 0xd3, 0x3b, 0xdb, 0x28, 0x5f, 0x94, 0x7a, 0x0f, 0x15,
-	0x51, 0x75, 0x90, 0xc4, 0x6c, 0x61, 0x25, 0x77, 0xd6, 0x5d, 0x2b, 0x98, 0xb8, 0x78, 0x85, 0xa5,
-	0x6c, 0x1a, 0x80, 0x45, 0xd7, 0xda, 0x48, 0xda, 0xcf, 0x6c, 0x25, 0xec, 0x0b, 0x2b, 0x96, 0xc7,
-	0xef, 0x53, 0x96, 0x32, 0x6f, 0x61, 0x15, 0x70, 0xe4, 0xda, 0xf5, 0x6c, 0x13, 0x49, 0x0c, 0x40,
-	0x13, 0xd0, 0x31, 0xb6, 0xb5, 0x63, 0x7b, 0x93, 0x47, 0xa5, 0x2a, 0x8e, 0xa0, 0x9f, 0x90, 0xaf,
-	0x05, 0x04, 0x3f, 0x31, 0x06, 0x21, 0xfe, 0x12, 0x00, 0x3f, 0x04, 0xe8, 0xf3, 0x11, 0x23, 0xbb,
-	0xc0, 0xe2, 0x43, 0x19, 0x0d, 0x0a, 0x87, 0x56, 0x64, 0xa8, 0x02, 0xaf, 0xbb, 0xff, 0x16, 0xf0,
-	0x2f, 0xe7, 0x01, 0x12, 0x31, 0x34, 0xda, 0xfb, 0x5c, 0x95, 0x7a, 0x9f, 0xce, 0xcb, 0x52, 0xfd,
-	0x19, 0x00, 0x00, 0xff, 0xff, 0xf7, 0xe4, 0xde, 0xc2, 0xe6, 0x01, 0x00, 0x00,
+	// 526 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xc4, 0x93, 0xcf, 0x6e, 0xd3, 0x40,
+	0x10, 0xc6, 0xb5, 0xb1, 0xe3, 0x26, 0xe3, 0xc4, 0x49, 0x0c, 0x45, 0x4b, 0xd5, 0x83, 0x65, 0x71,
+	0xb0, 0x84, 0x14, 0xa1, 0x9e, 0x90, 0x38, 0x16, 0xd4, 0x0b, 0x02, 0xd4, 0x23, 0x17, 0xcb, 0xb5,
+	0x27, 0x65, 0x15, 0x7b, 0x77, 0x59, 0x6f, 0x10, 0x79, 0x0d, 0x9e, 0x83, 0x13, 0x4f, 0x88, 0x3c,
+	0x91, 0x13, 0x37, 0x32, 0x7f, 0xc2, 0x85, 0xe3, 0x8e, 0x67, 0xbf, 0xf9, 0xe6, 0xb7, 0x9f, 0x21,
+	0xd0, 0x7a, 0xa9, 0x4c, 0x81, 0x66, 0xa9, 0x8d, 0xb2, 0x2a, 0x1c, 0x68, 0x7d, 0x31, 0xd3, 0x7a,
+	0x99, 0xab, 0xaa, 0x52, 0x72, 0x57, 0x8c, 0xbf, 0x31, 0x18, 0xbd, 0x6f, 0x9a, 0x6e, 0xf1, 0x73,
+	0x18, 0x80, 0xa7, 0x37, 0x77, 0x6b, 0xdc, 0x72, 0x88, 0x58, 0x32, 0x0e, 0x17, 0x30, 0xce, 0x95,
+	0x90, 0xa9, 0xce, 0x84, 0xe1, 0x3e, 0x95, 0x26, 0xe0, 0xda, 0xad, 0x46, 0x3e, 0xa1, 0x53, 0x00,
+	0x5e, 0x56, 0xa9, 0x8d, 0xb4, 0x7c, 0x1a, 0xb1, 0xc4, 0x0d, 0xa7, 0x30, 0xd4, 0x46, 0xe4, 0xc8,
+	0x03, 0x3a, 0x4e, 0xc0, 0x5d, 0x0b, 0x59, 0xf0, 0x19, 0x35, 0x9f, 0xc3, 0xd4, 0x8a, 0x0a, 0x53,
+	0x21, 0xd3, 0x95, 0x32, 0x39, 0xf2, 0x79, 0x3b, 0x44, 0xab, 0xda, 0xa6, 0x4a, 0x96, 0x5b, 0xbe,
+	0x88, 0x58, 0x32, 0x8a, 0x5f, 0xee, 0x3d, 0xd5, 0xe1, 0x05, 0x78, 0x06, 0xeb, 0x4d, 0x69, 0x39,
+	0x8b, 0x06, 0x89, 0x7f, 0x05, 0x4b, 0xad, 0x97, 0xb7, 0x54, 0x09, 0xe7, 0x30, 0xa2, 0x05, 0x53,
+	0x51, 0x90, 0x3d, 0x37, 0x5e, 0xc1, 0x90, 0x6e, 0x86, 0x00, 0x03, 0x51, 0x70, 0xd6, 0xda, 0x20,
+	0xcf, 0x0e, 0xcd, 0xdb, 0x7b, 0x74, 0xe9, 0xe3, 0x61, 0x85, 0x21, 0x9d, 0xe7, 0x30, 0x32, 0x58,
+	0xdb, 0x34, 0xab, 0x2c, 0xf7, 0xa8, 0x12, 0x02, 0xe4, 0x06, 0x33, 0x8b, 0x45, 0x9a, 0x59, 0x7e,
+	0x16, 0xb1, 0xc4, 0x89, 0x3f, 0x82, 0x7f, 0x83, 0xb6, 0x0b, 0xce, 0xa8, 0x8d, 0x45, 0xc3, 0x59,
+	0xbb, 0xd3, 0x01, 0x1c, 0x3c, 0x00, 0xe7, 0xb7, 0x26, 0x6a, 0x9b, 0x19, 0x4b, 0x1c, 0x9d, 0xd0,
+	0x07, 0x07, 0x65, 0x41, 0x10, 0x9d, 0x18, 0xbb, 0xda, 0xbf, 0x07, 0xf0, 0xc7, 0x39, 0x4f, 0xc1,
+	0x23, 0x42, 0x35, 0x3f, 0x8f, 0x9c, 0xc4, 0xbf, 0x1a, 0x37, 0x97, 0x49, 0x3a, 0x7e, 0x03, 0xc1,
+	0x75, 0x26, 0x73, 0x2c, 0x4f, 0x79, 0xfe, 0x2e, 0xf1, 0x09, 0x11, 0x7f, 0x77, 0x24, 0xf3, 0xf7,
+	0x2f, 0x06, 0x2d, 0x7f, 0x83, 0xab, 0x8d, 0x6c, 0x5f, 0xf0, 0x07, 0x83, 0x47, 0xd7, 0x4a, 0x16,
+	0xc2, 0x0a, 0x25, 0xb3, 0xf2, 0xff, 0x66, 0x73, 0x06, 0x67, 0xd6, 0x88, 0xfb, 0x7b, 0x34, 0x7c,
+	0xb1, 0xef, 0xdb, 0x15, 0xd2, 0x9d, 0x58, 0x48, 0xa6, 0xbf, 0x33, 0x98, 0x1f, 0x9b, 0xee, 0x8d,
+	0xe0, 0xa0, 0x35, 0x4a, 0xb3, 0x9d, 0xfe, 0xd9, 0xee, 0xc3, 0x9c, 0x0e, 0x8f, 0x72, 0xba, 0x4b,
+	0x65, 0xc7, 0xda, 0x59, 0xbf, 0xb5, 0x51, 0x4f, 0x7a, 0xc7, 0x94, 0xb0, 0x57, 0xf0, 0xe4, 0x06,
+	0xed, 0xbf, 0x51, 0x8e, 0xab, 0x5f, 0x5c, 0x3e, 0x39, 0xa9, 0xcf, 0xf6, 0xd9, 0xf4, 0x29, 0x9b,
+	0x8f, 0x9b, 0xf6, 0x63, 0xdd, 0xf8, 0x05, 0xfd, 0x0d, 0xaf, 0x51, 0xdb, 0x4f, 0x8d, 0xc1, 0x1e,
+	0x9d, 0x00, 0xbc, 0x12, 0xbf, 0x60, 0x59, 0x93, 0xc1, 0x61, 0xfc, 0x1c, 0xe0, 0x43, 0x03, 0xe0,
+	0x6d, 0x53, 0x3c, 0x60, 0x64, 0x47, 0x18, 0x07, 0xf4, 0x72, 0x5f, 0xbb, 0xf2, 0x27, 0xaf, 0x70,
+	0x09, 0xee, 0x9d, 0x28, 0xda, 0x05, 0x82, 0xa6, 0xb9, 0x33, 0xfa, 0x12, 0xdc, 0xac, 0x5e, 0xd7,
+	0x7c, 0xd2, 0xf7, 0xf5, 0xe7, 0x00, 0x83, 0x58, 0x42, 0xa6, 0x9f, 0x05, 0x00, 0x00,
 }
@@ -38,17 +38,66 @@ func (m *Request) GetData() []byte {
 	return nil
 }
 
+type VersionReq struct {
+	Versions         []uint32 `protobuf:"varint,10,rep,name=versions" json:"versions,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *VersionReq) Reset()                    { *m = VersionReq{} }
+func (m *VersionReq) String() string            { return proto.CompactTextString(m) }
+func (*VersionReq) ProtoMessage()               {}
+func (*VersionReq) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{1} }
+
+func (m *VersionReq) GetVersions() []uint32 {
+	if m != nil {
+		return m.Versions
+	}
+	return nil
+}
+
+type VersionRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Version          *uint32 `protobuf:"varint,10,opt,name=version" json:"version,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *VersionRes) Reset()                    { *m = VersionRes{} }
+func (m *VersionRes) String() string            { return proto.CompactTextString(m) }
+func (*VersionRes) ProtoMessage()               {}
+func (*VersionRes) Descriptor() ([]byte, []int) { return fileDescriptor8, []int{2} }
+
+func (m *VersionRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *VersionRes) GetVersion() uint32 {
+	if m != nil && m.Version != nil {
+		return *m.Version
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Request)(nil), "pp.Request")
+	proto.RegisterType((*VersionReq)(nil), "pp.VersionReq")
+	proto.RegisterType((*VersionRes)(nil), "pp.VersionRes")
 }
 
 func init() { proto.RegisterFile("pp.request.proto", fileDescriptor8) }
 
 var fileDescriptor8 = []byte{
-	// 79 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x28, 0x28, 0xd0, 0x2b,
-	0x4a, 0x2d, 0x2c, 0x4d, 0x2d, 0x2e, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x28,
-	0x50, 0x52, 0xe5, 0x62, 0x0f, 0x82, 0x08, 0x0a, 0xf1, 0x70, 0xb1, 0x14, 0x24, 0x96, 0x64, 0x48,
-	0x30, 0x2a, 0x30, 0x69, 0x70, 0x82, 0x78, 0x29, 0x89, 0x25, 0x89, 0x12, 0x5c, 0x0a, 0x8c, 0x1a,
-	0x3c, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x78, 0xe2, 0x56, 0xf9, 0x3d, 0x00, 0x00, 0x00,
+	// 152 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x4c, 0x8c, 0xb1, 0xca, 0xc2, 0x30,
+	0x14, 0x46, 0x69, 0xff, 0x1f, 0xab, 0x9f, 0x2d, 0x2d, 0x99, 0x42, 0x07, 0x09, 0x01, 0x21, 0x53,
+	0x06, 0x37, 0x5f, 0x23, 0x83, 0x7b, 0xd0, 0x80, 0x82, 0x6d, 0x6e, 0x93, 0xd4, 0xe7, 0x17, 0x13,
+	0x07, 0xc7, 0x73, 0xee, 0xfd, 0x0e, 0x06, 0x22, 0x1d, 0xdc, 0xb2, 0xba, 0x98, 0x34, 0x05, 0x9f,
+	0x3c, 0xab, 0x89, 0xc6, 0x9e, 0x48, 0x5f, 0xfd, 0x34, 0xf9, 0xb9, 0x48, 0x79, 0x44, 0x63, 0xca,
+	0x17, 0x6b, 0xf1, 0x4f, 0x36, 0xdd, 0x79, 0x25, 0x6a, 0xb5, 0xfb, 0xd0, 0xcd, 0x26, 0xcb, 0x21,
+	0x2a, 0xd5, 0xca, 0x03, 0x70, 0x71, 0x21, 0x3e, 0xfc, 0x6c, 0xdc, 0xc2, 0x06, 0x6c, 0x5f, 0x85,
+	0x22, 0x87, 0xf8, 0x53, 0x9d, 0x3c, 0xff, 0xdc, 0x23, 0x1b, 0xb1, 0x09, 0x2e, 0xae, 0xcf, 0x94,
+	0x5b, 0xfb, 0x13, 0x34, 0x91, 0x36, 0xd9, 0xb0, 0x1e, 0xcd, 0x77, 0x9b, 0xd3, 0xdd, 0x7b, 0x00,
+	0x41, 0x70, 0x09, 0xe2, 0xa9, 0x00, 0x00, 0x00,
 }
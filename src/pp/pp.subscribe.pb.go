@@ -0,0 +1,246 @@
+// Code generated by protoc-gen-go.
+// source: pp.subscribe.proto
+// DO NOT EDIT!
+
+package pp
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// SubscribeReq subscribes the topics: book:<coin_pair>, trades:<coin_pair> and account,
+// the account topic requires the pubkey, and the sig of the account key over the replay
+// stamp of the request, which is req_time and req_nonce.
+type SubscribeReq struct {
+	Pubkey           *string  `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Topics           []string `protobuf:"bytes,11,rep,name=topics" json:"topics,omitempty"`
+	ReqTime          *int64   `protobuf:"varint,12,opt,name=req_time" json:"req_time,omitempty"`
+	ReqNonce         *string  `protobuf:"bytes,13,opt,name=req_nonce" json:"req_nonce,omitempty"`
+	Sig              *string  `protobuf:"bytes,14,opt,name=sig" json:"sig,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *SubscribeReq) Reset()                    { *m = SubscribeReq{} }
+func (m *SubscribeReq) String() string            { return proto.CompactTextString(m) }
+func (*SubscribeReq) ProtoMessage()               {}
+func (*SubscribeReq) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{0} }
+
+func (m *SubscribeReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *SubscribeReq) GetTopics() []string {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+func (m *SubscribeReq) GetReqTime() int64 {
+	if m != nil && m.ReqTime != nil {
+		return *m.ReqTime
+	}
+	return 0
+}
+
+func (m *SubscribeReq) GetReqNonce() string {
+	if m != nil && m.ReqNonce != nil {
+		return *m.ReqNonce
+	}
+	return ""
+}
+
+func (m *SubscribeReq) GetSig() string {
+	if m != nil && m.Sig != nil {
+		return *m.Sig
+	}
+	return ""
+}
+
+type SubscribeRes struct {
+	Result           *Result  `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Topics           []string `protobuf:"bytes,10,rep,name=topics" json:"topics,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *SubscribeRes) Reset()                    { *m = SubscribeRes{} }
+func (m *SubscribeRes) String() string            { return proto.CompactTextString(m) }
+func (*SubscribeRes) ProtoMessage()               {}
+func (*SubscribeRes) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{1} }
+
+func (m *SubscribeRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *SubscribeRes) GetTopics() []string {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+// BookEvent is pushed to the subscribers of book:<coin_pair> when the book is changed.
+type BookEvent struct {
+	CoinPair         *string       `protobuf:"bytes,1,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Bids             []*PriceLevel `protobuf:"bytes,2,rep,name=bids" json:"bids,omitempty"`
+	Asks             []*PriceLevel `protobuf:"bytes,3,rep,name=asks" json:"asks,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
+}
+
+func (m *BookEvent) Reset()                    { *m = BookEvent{} }
+func (m *BookEvent) String() string            { return proto.CompactTextString(m) }
+func (*BookEvent) ProtoMessage()               {}
+func (*BookEvent) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{2} }
+
+func (m *BookEvent) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *BookEvent) GetBids() []*PriceLevel {
+	if m != nil {
+		return m.Bids
+	}
+	return nil
+}
+
+func (m *BookEvent) GetAsks() []*PriceLevel {
+	if m != nil {
+		return m.Asks
+	}
+	return nil
+}
+
+// TradesEvent is pushed to the subscribers of trades:<coin_pair> when trades are executed.
+type TradesEvent struct {
+	CoinPair         *string  `protobuf:"bytes,1,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Trades           []*Trade `protobuf:"bytes,2,rep,name=trades" json:"trades,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *TradesEvent) Reset()                    { *m = TradesEvent{} }
+func (m *TradesEvent) String() string            { return proto.CompactTextString(m) }
+func (*TradesEvent) ProtoMessage()               {}
+func (*TradesEvent) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{3} }
+
+func (m *TradesEvent) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *TradesEvent) GetTrades() []*Trade {
+	if m != nil {
+		return m.Trades
+	}
+	return nil
+}
+
+// AccountEvent is pushed to the subscribers of account when the account's order is filled,
+// or its balance is changed, including the deposits.
+type AccountEvent struct {
+	Type             *string         `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	CoinPair         *string         `protobuf:"bytes,2,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Fill             *Fill           `protobuf:"bytes,3,opt,name=fill" json:"fill,omitempty"`
+	CoinType         *string         `protobuf:"bytes,4,opt,name=coin_type" json:"coin_type,omitempty"`
+	Balance          *uint64         `protobuf:"varint,5,opt,name=balance" json:"balance,omitempty"`
+	History          *AccountHistory `protobuf:"bytes,6,opt,name=history" json:"history,omitempty"`
+	XXX_unrecognized []byte          `json:"-"`
+}
+
+func (m *AccountEvent) Reset()                    { *m = AccountEvent{} }
+func (m *AccountEvent) String() string            { return proto.CompactTextString(m) }
+func (*AccountEvent) ProtoMessage()               {}
+func (*AccountEvent) Descriptor() ([]byte, []int) { return fileDescriptor14, []int{4} }
+
+func (m *AccountEvent) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+func (m *AccountEvent) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *AccountEvent) GetFill() *Fill {
+	if m != nil {
+		return m.Fill
+	}
+	return nil
+}
+
+func (m *AccountEvent) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *AccountEvent) GetBalance() uint64 {
+	if m != nil && m.Balance != nil {
+		return *m.Balance
+	}
+	return 0
+}
+
+func (m *AccountEvent) GetHistory() *AccountHistory {
+	if m != nil {
+		return m.History
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*SubscribeReq)(nil), "pp.SubscribeReq")
+	proto.RegisterType((*SubscribeRes)(nil), "pp.SubscribeRes")
+	proto.RegisterType((*BookEvent)(nil), "pp.BookEvent")
+	proto.RegisterType((*TradesEvent)(nil), "pp.TradesEvent")
+	proto.RegisterType((*AccountEvent)(nil), "pp.AccountEvent")
+}
+
+func init() { proto.RegisterFile("pp.subscribe.proto", fileDescriptor14) }
+
+var fileDescriptor14 = []byte{
+	// 350 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x90, 0x3d, 0x6f, 0xdb, 0x30,
+	0x10, 0x86, 0xa1, 0x8f, 0xca, 0xd6, 0x49, 0x95, 0x5d, 0x0e, 0x05, 0x6b, 0x74, 0x10, 0xd4, 0x45,
+	0x93, 0x06, 0x8f, 0xed, 0xd4, 0x02, 0x0d, 0x32, 0x64, 0x08, 0x9c, 0x4c, 0x59, 0x1c, 0x89, 0x66,
+	0x12, 0xc2, 0xb2, 0x78, 0x26, 0x29, 0x03, 0xfe, 0x17, 0xf9, 0xc9, 0x01, 0x29, 0x39, 0xb0, 0x81,
+	0x64, 0xbc, 0xf7, 0xee, 0x79, 0x5e, 0xe0, 0x80, 0x20, 0x56, 0xba, 0x6f, 0x34, 0x53, 0xa2, 0xe1,
+	0x15, 0x2a, 0x69, 0x24, 0xf1, 0x11, 0x17, 0x33, 0xc4, 0x8a, 0xc9, 0xdd, 0x4e, 0x76, 0x43, 0xb8,
+	0x98, 0x23, 0x56, 0x35, 0x63, 0xb2, 0xef, 0xcc, 0x98, 0x64, 0x88, 0x95, 0x54, 0x1b, 0xae, 0xce,
+	0x66, 0xa3, 0xea, 0xcd, 0xa8, 0x29, 0x1e, 0x21, 0xbd, 0x3b, 0x99, 0x57, 0x7c, 0x4f, 0x32, 0x88,
+	0xb0, 0x6f, 0xb6, 0xfc, 0x48, 0x21, 0xf7, 0xca, 0xd8, 0xce, 0x46, 0xa2, 0x60, 0x9a, 0x26, 0x79,
+	0x50, 0xc6, 0x64, 0x0e, 0x53, 0xc5, 0xf7, 0x6b, 0x23, 0x76, 0x9c, 0xa6, 0xb9, 0x57, 0x06, 0xe4,
+	0x1b, 0xc4, 0x36, 0xe9, 0x64, 0xc7, 0x38, 0xfd, 0xea, 0xa0, 0x04, 0x02, 0x2d, 0x9e, 0x69, 0x66,
+	0x87, 0xe2, 0xf7, 0x45, 0x83, 0x26, 0x0b, 0x88, 0x14, 0xd7, 0x7d, 0x6b, 0xa8, 0x97, 0xfb, 0x65,
+	0xb2, 0x84, 0x0a, 0xb1, 0x5a, 0xb9, 0xe4, 0xac, 0x0d, 0x6c, 0x5b, 0xf1, 0x00, 0xf1, 0x3f, 0x29,
+	0xb7, 0xff, 0x0f, 0xbc, 0x33, 0xb6, 0x88, 0x49, 0xd1, 0xad, 0xb1, 0x16, 0x8a, 0x7a, 0xae, 0xe8,
+	0x27, 0x84, 0x8d, 0xd8, 0x68, 0xea, 0xe7, 0x41, 0x99, 0x2c, 0x33, 0x6b, 0xba, 0x55, 0x82, 0xf1,
+	0x1b, 0x7e, 0xe0, 0xad, 0xdd, 0xd6, 0x7a, 0xab, 0x69, 0xf0, 0xd1, 0xb6, 0xf8, 0x03, 0xc9, 0xbd,
+	0x7d, 0x84, 0xfe, 0xd4, 0xfe, 0x03, 0x22, 0xf7, 0xaa, 0x93, 0x3f, 0xb6, 0x06, 0xc7, 0x14, 0xaf,
+	0x1e, 0xa4, 0x7f, 0x87, 0x47, 0x0f, 0x78, 0x0a, 0xa1, 0x39, 0x22, 0x1f, 0xc9, 0x0b, 0x99, 0xef,
+	0xa2, 0xef, 0x10, 0x3e, 0x89, 0xb6, 0xa5, 0x41, 0xee, 0x95, 0xc9, 0x72, 0x6a, 0x55, 0x57, 0xa2,
+	0x6d, 0xdf, 0x4f, 0x1d, 0x1d, 0xba, 0xd3, 0x19, 0x4c, 0x9a, 0xba, 0xad, 0xed, 0x3f, 0xbf, 0xe4,
+	0x5e, 0x19, 0x92, 0x5f, 0x30, 0x79, 0x11, 0xda, 0x48, 0x75, 0xa4, 0x91, 0xc3, 0x89, 0xc5, 0xc7,
+	0xfe, 0xeb, 0x61, 0xf3, 0x36, 0x00, 0xbb, 0xf0, 0x30, 0x8f, 0x25, 0x02, 0x00, 0x00,
+}
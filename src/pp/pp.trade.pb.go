@@ -0,0 +1,599 @@
+// Code generated by protoc-gen-go.
+// source: pp.trade.proto
+// DO NOT EDIT!
+
+package pp
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type Trade struct {
+	Id               *uint64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	MakerId          *uint64 `protobuf:"varint,2,opt,name=maker_id" json:"maker_id,omitempty"`
+	TakerId          *uint64 `protobuf:"varint,3,opt,name=taker_id" json:"taker_id,omitempty"`
+	TakerType        *string `protobuf:"bytes,4,opt,name=taker_type" json:"taker_type,omitempty"`
+	Price            *uint64 `protobuf:"varint,5,opt,name=price" json:"price,omitempty"`
+	Amount           *uint64 `protobuf:"varint,6,opt,name=amount" json:"amount,omitempty"`
+	CreatedAt        *int64  `protobuf:"varint,7,opt,name=created_at" json:"created_at,omitempty"`
+	BidFee           *uint64 `protobuf:"varint,8,opt,name=bid_fee" json:"bid_fee,omitempty"`
+	AskFee           *uint64 `protobuf:"varint,9,opt,name=ask_fee" json:"ask_fee,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *Trade) Reset()                    { *m = Trade{} }
+func (m *Trade) String() string            { return proto.CompactTextString(m) }
+func (*Trade) ProtoMessage()               {}
+func (*Trade) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{0} }
+
+func (m *Trade) GetId() uint64 {
+	if m != nil && m.Id != nil {
+		return *m.Id
+	}
+	return 0
+}
+
+func (m *Trade) GetMakerId() uint64 {
+	if m != nil && m.MakerId != nil {
+		return *m.MakerId
+	}
+	return 0
+}
+
+func (m *Trade) GetTakerId() uint64 {
+	if m != nil && m.TakerId != nil {
+		return *m.TakerId
+	}
+	return 0
+}
+
+func (m *Trade) GetTakerType() string {
+	if m != nil && m.TakerType != nil {
+		return *m.TakerType
+	}
+	return ""
+}
+
+func (m *Trade) GetPrice() uint64 {
+	if m != nil && m.Price != nil {
+		return *m.Price
+	}
+	return 0
+}
+
+func (m *Trade) GetAmount() uint64 {
+	if m != nil && m.Amount != nil {
+		return *m.Amount
+	}
+	return 0
+}
+
+func (m *Trade) GetCreatedAt() int64 {
+	if m != nil && m.CreatedAt != nil {
+		return *m.CreatedAt
+	}
+	return 0
+}
+
+func (m *Trade) GetBidFee() uint64 {
+	if m != nil && m.BidFee != nil {
+		return *m.BidFee
+	}
+	return 0
+}
+
+func (m *Trade) GetAskFee() uint64 {
+	if m != nil && m.AskFee != nil {
+		return *m.AskFee
+	}
+	return 0
+}
+
+type GetTradesReq struct {
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Start            *int64  `protobuf:"varint,11,opt,name=start" json:"start,omitempty"`
+	End              *int64  `protobuf:"varint,12,opt,name=end" json:"end,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetTradesReq) Reset()                    { *m = GetTradesReq{} }
+func (m *GetTradesReq) String() string            { return proto.CompactTextString(m) }
+func (*GetTradesReq) ProtoMessage()               {}
+func (*GetTradesReq) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{1} }
+
+func (m *GetTradesReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetTradesReq) GetStart() int64 {
+	if m != nil && m.Start != nil {
+		return *m.Start
+	}
+	return 0
+}
+
+func (m *GetTradesReq) GetEnd() int64 {
+	if m != nil && m.End != nil {
+		return *m.End
+	}
+	return 0
+}
+
+type GetTradesRes struct {
+	Result           *Result  `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string  `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Trades           []*Trade `protobuf:"bytes,11,rep,name=trades" json:"trades,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *GetTradesRes) Reset()                    { *m = GetTradesRes{} }
+func (m *GetTradesRes) String() string            { return proto.CompactTextString(m) }
+func (*GetTradesRes) ProtoMessage()               {}
+func (*GetTradesRes) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{2} }
+
+func (m *GetTradesRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetTradesRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetTradesRes) GetTrades() []*Trade {
+	if m != nil {
+		return m.Trades
+	}
+	return nil
+}
+
+// Fill is one trade of the account's order.
+type Fill struct {
+	TradeId          *uint64 `protobuf:"varint,1,opt,name=trade_id" json:"trade_id,omitempty"`
+	OrderId          *uint64 `protobuf:"varint,2,opt,name=order_id" json:"order_id,omitempty"`
+	Type             *string `protobuf:"bytes,3,opt,name=type" json:"type,omitempty"`
+	Maker            *bool   `protobuf:"varint,4,opt,name=maker" json:"maker,omitempty"`
+	Price            *uint64 `protobuf:"varint,5,opt,name=price" json:"price,omitempty"`
+	Amount           *uint64 `protobuf:"varint,6,opt,name=amount" json:"amount,omitempty"`
+	CreatedAt        *int64  `protobuf:"varint,7,opt,name=created_at" json:"created_at,omitempty"`
+	Fee              *uint64 `protobuf:"varint,8,opt,name=fee" json:"fee,omitempty"`
+	FeeCoin          *string `protobuf:"bytes,9,opt,name=fee_coin" json:"fee_coin,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *Fill) Reset()                    { *m = Fill{} }
+func (m *Fill) String() string            { return proto.CompactTextString(m) }
+func (*Fill) ProtoMessage()               {}
+func (*Fill) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{3} }
+
+func (m *Fill) GetTradeId() uint64 {
+	if m != nil && m.TradeId != nil {
+		return *m.TradeId
+	}
+	return 0
+}
+
+func (m *Fill) GetOrderId() uint64 {
+	if m != nil && m.OrderId != nil {
+		return *m.OrderId
+	}
+	return 0
+}
+
+func (m *Fill) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+func (m *Fill) GetMaker() bool {
+	if m != nil && m.Maker != nil {
+		return *m.Maker
+	}
+	return false
+}
+
+func (m *Fill) GetPrice() uint64 {
+	if m != nil && m.Price != nil {
+		return *m.Price
+	}
+	return 0
+}
+
+func (m *Fill) GetAmount() uint64 {
+	if m != nil && m.Amount != nil {
+		return *m.Amount
+	}
+	return 0
+}
+
+func (m *Fill) GetCreatedAt() int64 {
+	if m != nil && m.CreatedAt != nil {
+		return *m.CreatedAt
+	}
+	return 0
+}
+
+func (m *Fill) GetFee() uint64 {
+	if m != nil && m.Fee != nil {
+		return *m.Fee
+	}
+	return 0
+}
+
+func (m *Fill) GetFeeCoin() string {
+	if m != nil && m.FeeCoin != nil {
+		return *m.FeeCoin
+	}
+	return ""
+}
+
+type GetAccountFillsReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinPair         *string `protobuf:"bytes,11,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Start            *int64  `protobuf:"varint,12,opt,name=start" json:"start,omitempty"`
+	End              *int64  `protobuf:"varint,13,opt,name=end" json:"end,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAccountFillsReq) Reset()                    { *m = GetAccountFillsReq{} }
+func (m *GetAccountFillsReq) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountFillsReq) ProtoMessage()               {}
+func (*GetAccountFillsReq) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{4} }
+
+func (m *GetAccountFillsReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *GetAccountFillsReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetAccountFillsReq) GetStart() int64 {
+	if m != nil && m.Start != nil {
+		return *m.Start
+	}
+	return 0
+}
+
+func (m *GetAccountFillsReq) GetEnd() int64 {
+	if m != nil && m.End != nil {
+		return *m.End
+	}
+	return 0
+}
+
+type GetAccountFillsRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Fills            []*Fill `protobuf:"bytes,11,rep,name=fills" json:"fills,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAccountFillsRes) Reset()                    { *m = GetAccountFillsRes{} }
+func (m *GetAccountFillsRes) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountFillsRes) ProtoMessage()               {}
+func (*GetAccountFillsRes) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{5} }
+
+func (m *GetAccountFillsRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetAccountFillsRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetAccountFillsRes) GetFills() []*Fill {
+	if m != nil {
+		return m.Fills
+	}
+	return nil
+}
+
+type GetTickerReq struct {
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetTickerReq) Reset()                    { *m = GetTickerReq{} }
+func (m *GetTickerReq) String() string            { return proto.CompactTextString(m) }
+func (*GetTickerReq) ProtoMessage()               {}
+func (*GetTickerReq) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{6} }
+
+func (m *GetTickerReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+type GetTickerRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Last             *uint64 `protobuf:"varint,11,opt,name=last" json:"last,omitempty"`
+	High             *uint64 `protobuf:"varint,12,opt,name=high" json:"high,omitempty"`
+	Low              *uint64 `protobuf:"varint,13,opt,name=low" json:"low,omitempty"`
+	Volume           *uint64 `protobuf:"varint,14,opt,name=volume" json:"volume,omitempty"`
+	BestBid          *uint64 `protobuf:"varint,15,opt,name=best_bid" json:"best_bid,omitempty"`
+	BestAsk          *uint64 `protobuf:"varint,16,opt,name=best_ask" json:"best_ask,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetTickerRes) Reset()                    { *m = GetTickerRes{} }
+func (m *GetTickerRes) String() string            { return proto.CompactTextString(m) }
+func (*GetTickerRes) ProtoMessage()               {}
+func (*GetTickerRes) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{7} }
+
+func (m *GetTickerRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetTickerRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetTickerRes) GetLast() uint64 {
+	if m != nil && m.Last != nil {
+		return *m.Last
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetHigh() uint64 {
+	if m != nil && m.High != nil {
+		return *m.High
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetLow() uint64 {
+	if m != nil && m.Low != nil {
+		return *m.Low
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetVolume() uint64 {
+	if m != nil && m.Volume != nil {
+		return *m.Volume
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetBestBid() uint64 {
+	if m != nil && m.BestBid != nil {
+		return *m.BestBid
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetBestAsk() uint64 {
+	if m != nil && m.BestAsk != nil {
+		return *m.BestAsk
+	}
+	return 0
+}
+
+// Candle is the OHLCV summary of the trades in one interval.
+type Candle struct {
+	Time             *int64  `protobuf:"varint,1,opt,name=time" json:"time,omitempty"`
+	Open             *uint64 `protobuf:"varint,2,opt,name=open" json:"open,omitempty"`
+	High             *uint64 `protobuf:"varint,3,opt,name=high" json:"high,omitempty"`
+	Low              *uint64 `protobuf:"varint,4,opt,name=low" json:"low,omitempty"`
+	Close            *uint64 `protobuf:"varint,5,opt,name=close" json:"close,omitempty"`
+	Volume           *uint64 `protobuf:"varint,6,opt,name=volume" json:"volume,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *Candle) Reset()                    { *m = Candle{} }
+func (m *Candle) String() string            { return proto.CompactTextString(m) }
+func (*Candle) ProtoMessage()               {}
+func (*Candle) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{8} }
+
+func (m *Candle) GetTime() int64 {
+	if m != nil && m.Time != nil {
+		return *m.Time
+	}
+	return 0
+}
+
+func (m *Candle) GetOpen() uint64 {
+	if m != nil && m.Open != nil {
+		return *m.Open
+	}
+	return 0
+}
+
+func (m *Candle) GetHigh() uint64 {
+	if m != nil && m.High != nil {
+		return *m.High
+	}
+	return 0
+}
+
+func (m *Candle) GetLow() uint64 {
+	if m != nil && m.Low != nil {
+		return *m.Low
+	}
+	return 0
+}
+
+func (m *Candle) GetClose() uint64 {
+	if m != nil && m.Close != nil {
+		return *m.Close
+	}
+	return 0
+}
+
+func (m *Candle) GetVolume() uint64 {
+	if m != nil && m.Volume != nil {
+		return *m.Volume
+	}
+	return 0
+}
+
+type GetCandlesReq struct {
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Interval         *string `protobuf:"bytes,11,opt,name=interval" json:"interval,omitempty"`
+	Start            *int64  `protobuf:"varint,12,opt,name=start" json:"start,omitempty"`
+	End              *int64  `protobuf:"varint,13,opt,name=end" json:"end,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetCandlesReq) Reset()                    { *m = GetCandlesReq{} }
+func (m *GetCandlesReq) String() string            { return proto.CompactTextString(m) }
+func (*GetCandlesReq) ProtoMessage()               {}
+func (*GetCandlesReq) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{9} }
+
+func (m *GetCandlesReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetCandlesReq) GetInterval() string {
+	if m != nil && m.Interval != nil {
+		return *m.Interval
+	}
+	return ""
+}
+
+func (m *GetCandlesReq) GetStart() int64 {
+	if m != nil && m.Start != nil {
+		return *m.Start
+	}
+	return 0
+}
+
+func (m *GetCandlesReq) GetEnd() int64 {
+	if m != nil && m.End != nil {
+		return *m.End
+	}
+	return 0
+}
+
+type GetCandlesRes struct {
+	Result           *Result   `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string   `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Interval         *string   `protobuf:"bytes,11,opt,name=interval" json:"interval,omitempty"`
+	Candles          []*Candle `protobuf:"bytes,12,rep,name=candles" json:"candles,omitempty"`
+	XXX_unrecognized []byte    `json:"-"`
+}
+
+func (m *GetCandlesRes) Reset()                    { *m = GetCandlesRes{} }
+func (m *GetCandlesRes) String() string            { return proto.CompactTextString(m) }
+func (*GetCandlesRes) ProtoMessage()               {}
+func (*GetCandlesRes) Descriptor() ([]byte, []int) { return fileDescriptor13, []int{10} }
+
+func (m *GetCandlesRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetCandlesRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetCandlesRes) GetInterval() string {
+	if m != nil && m.Interval != nil {
+		return *m.Interval
+	}
+	return ""
+}
+
+func (m *GetCandlesRes) GetCandles() []*Candle {
+	if m != nil {
+		return m.Candles
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Trade)(nil), "pp.Trade")
+	proto.RegisterType((*GetTradesReq)(nil), "pp.GetTradesReq")
+	proto.RegisterType((*GetTradesRes)(nil), "pp.GetTradesRes")
+	proto.RegisterType((*Fill)(nil), "pp.Fill")
+	proto.RegisterType((*GetAccountFillsReq)(nil), "pp.GetAccountFillsReq")
+	proto.RegisterType((*GetAccountFillsRes)(nil), "pp.GetAccountFillsRes")
+	proto.RegisterType((*GetTickerReq)(nil), "pp.GetTickerReq")
+	proto.RegisterType((*GetTickerRes)(nil), "pp.GetTickerRes")
+	proto.RegisterType((*Candle)(nil), "pp.Candle")
+	proto.RegisterType((*GetCandlesReq)(nil), "pp.GetCandlesReq")
+	proto.RegisterType((*GetCandlesRes)(nil), "pp.GetCandlesRes")
+}
+
+func init() { proto.RegisterFile("pp.trade.proto", fileDescriptor13) }
+
+var fileDescriptor13 = []byte{
+	// 501 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x93, 0x3f, 0x8f, 0xd3, 0x40,
+	0x10, 0xc5, 0xe5, 0xd8, 0x71, 0x9c, 0xb1, 0xf3, 0x07, 0x37, 0x2c, 0x47, 0x63, 0x5c, 0xa5, 0x4a,
+	0x71, 0x3d, 0x05, 0x42, 0x22, 0x2d, 0x3a, 0x28, 0x28, 0x10, 0xd6, 0xc6, 0x9e, 0x70, 0xab, 0xd8,
+	0xde, 0x65, 0x77, 0x73, 0xe8, 0x3e, 0x0a, 0x54, 0x7c, 0x54, 0xb4, 0xe3, 0x58, 0xd8, 0x22, 0x48,
+	0xe4, 0x4a, 0x3f, 0xcd, 0xbe, 0x37, 0xf3, 0x9b, 0x31, 0x2c, 0x95, 0xda, 0x5a, 0xcd, 0x2b, 0xdc,
+	0x2a, 0x2d, 0xad, 0x4c, 0x27, 0x4a, 0xdd, 0xac, 0x94, 0xda, 0x96, 0xb2, 0x69, 0x64, 0xdb, 0x89,
+	0xf9, 0x2f, 0x0f, 0xa6, 0x1f, 0x5d, 0x51, 0x0a, 0x30, 0x11, 0x15, 0xf3, 0x32, 0x6f, 0x13, 0xa4,
+	0x6b, 0x88, 0x1a, 0x7e, 0x44, 0x5d, 0x88, 0x8a, 0x4d, 0x7a, 0xc5, 0xf6, 0x8a, 0x4f, 0x4a, 0x0a,
+	0xd0, 0x29, 0xf6, 0x51, 0x21, 0x0b, 0x32, 0x6f, 0x33, 0x4f, 0x17, 0x30, 0x55, 0x5a, 0x94, 0xc8,
+	0xa6, 0x54, 0xb2, 0x84, 0x90, 0x37, 0xf2, 0xd4, 0x5a, 0x16, 0xf6, 0x4f, 0x4a, 0x8d, 0xdc, 0x62,
+	0x55, 0x70, 0xcb, 0x66, 0x99, 0xb7, 0xf1, 0xd3, 0x15, 0xcc, 0xf6, 0xa2, 0x2a, 0x0e, 0x88, 0x2c,
+	0xa2, 0xa2, 0x15, 0xcc, 0xb8, 0x39, 0x92, 0x30, 0x77, 0x42, 0xfe, 0x1a, 0x92, 0x1d, 0x5a, 0x6a,
+	0xd2, 0xdc, 0xe1, 0xb7, 0xf4, 0x19, 0xcc, 0x4b, 0x29, 0xda, 0x42, 0x71, 0xa1, 0x19, 0xf4, 0xb9,
+	0xc6, 0x72, 0x6d, 0x59, 0x4c, 0x9e, 0x31, 0xf8, 0xd8, 0x56, 0x2c, 0x71, 0x1f, 0xf9, 0xa7, 0xd1,
+	0x73, 0x93, 0xde, 0x40, 0xa8, 0xd1, 0x9c, 0x6a, 0xcb, 0xbc, 0x6c, 0xb2, 0x89, 0x6f, 0x61, 0xab,
+	0xd4, 0xf6, 0x8e, 0x94, 0x4b, 0xd6, 0x2f, 0x20, 0x24, 0x88, 0x86, 0xc5, 0x99, 0xbf, 0x89, 0x6f,
+	0xe7, 0xae, 0x9c, 0xdc, 0xf2, 0x1f, 0x1e, 0x04, 0xef, 0x44, 0x5d, 0x13, 0x1c, 0xa7, 0x14, 0x43,
+	0x80, 0x52, 0x57, 0x43, 0x80, 0x09, 0x04, 0x04, 0xca, 0xef, 0x1b, 0x26, 0xc0, 0xc4, 0x2d, 0x7a,
+	0x0a, 0xb7, 0x18, 0xfc, 0x3f, 0xcc, 0xd6, 0x10, 0x1d, 0x10, 0x0b, 0xd7, 0x3b, 0x41, 0x9b, 0xe7,
+	0x1f, 0x20, 0xdd, 0xa1, 0x7d, 0x53, 0x96, 0xce, 0xc6, 0x35, 0x49, 0xe8, 0x96, 0x10, 0xaa, 0xd3,
+	0xfe, 0x88, 0x8f, 0xe7, 0xe1, 0x46, 0xf3, 0xc6, 0x63, 0x94, 0xc9, 0x10, 0xe5, 0x82, 0x50, 0x7e,
+	0xbe, 0x60, 0x7a, 0x35, 0xd0, 0xe7, 0x30, 0x3d, 0xb8, 0xa7, 0x67, 0x9e, 0x91, 0xab, 0x76, 0x5e,
+	0xf9, 0xab, 0x6e, 0x51, 0xa2, 0x3c, 0xa2, 0xbe, 0xbc, 0xe7, 0xfc, 0xa7, 0x37, 0xaa, 0xb9, 0x3a,
+	0x3b, 0x81, 0xa0, 0xe6, 0xa6, 0x3b, 0x13, 0x5a, 0xc9, 0xbd, 0xf8, 0x7a, 0x4f, 0x93, 0x06, 0x6e,
+	0xd2, 0x5a, 0x7e, 0x67, 0x8b, 0x7e, 0x03, 0x0f, 0xb2, 0x3e, 0x35, 0xc8, 0x96, 0x3d, 0xe0, 0x3d,
+	0x1a, 0x5b, 0xec, 0x45, 0xc5, 0x56, 0x23, 0x85, 0x9b, 0x23, 0x5b, 0xd3, 0x9d, 0x7e, 0x81, 0xf0,
+	0x2d, 0x6f, 0xab, 0x1a, 0x69, 0xd7, 0xa2, 0x41, 0xba, 0x05, 0xdf, 0x7d, 0x49, 0x85, 0x2d, 0x9b,
+	0x8c, 0x42, 0xfd, 0x61, 0x68, 0x40, 0x1f, 0x0b, 0x98, 0x96, 0xb5, 0x34, 0x83, 0x2b, 0x38, 0xf7,
+	0x40, 0x57, 0x90, 0xbf, 0x87, 0xc5, 0x0e, 0x6d, 0x17, 0xf1, 0xaf, 0x1f, 0x61, 0x0d, 0x91, 0x68,
+	0x2d, 0xea, 0x07, 0x5e, 0xff, 0xc7, 0x3e, 0x9b, 0xb1, 0xe3, 0xd5, 0x38, 0xff, 0x4e, 0x7b, 0x09,
+	0xb3, 0xb2, 0xb3, 0x63, 0x49, 0xe6, 0xf7, 0x0e, 0x5d, 0xc2, 0xef, 0x01, 0x00, 0x04, 0xc1, 0xd8,
+	0xe2, 0x91, 0x04, 0x00, 0x00,
+}
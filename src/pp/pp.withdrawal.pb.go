@@ -79,25 +79,124 @@ func (m *WithdrawalRes) GetNewTxid() string {
 	return ""
 }
 
+type WithdrawPolicy struct {
+	CoinType         *string `protobuf:"bytes,1,opt,name=coin_type" json:"coin_type,omitempty"`
+	Fee              *uint64 `protobuf:"varint,2,opt,name=fee" json:"fee,omitempty"`
+	Min              *uint64 `protobuf:"varint,3,opt,name=min" json:"min,omitempty"`
+	Max              *uint64 `protobuf:"varint,4,opt,name=max" json:"max,omitempty"`
+	DailyCap         *uint64 `protobuf:"varint,5,opt,name=daily_cap" json:"daily_cap,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *WithdrawPolicy) Reset()                    { *m = WithdrawPolicy{} }
+func (m *WithdrawPolicy) String() string            { return proto.CompactTextString(m) }
+func (*WithdrawPolicy) ProtoMessage()               {}
+func (*WithdrawPolicy) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{2} }
+
+func (m *WithdrawPolicy) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *WithdrawPolicy) GetFee() uint64 {
+	if m != nil && m.Fee != nil {
+		return *m.Fee
+	}
+	return 0
+}
+
+func (m *WithdrawPolicy) GetMin() uint64 {
+	if m != nil && m.Min != nil {
+		return *m.Min
+	}
+	return 0
+}
+
+func (m *WithdrawPolicy) GetMax() uint64 {
+	if m != nil && m.Max != nil {
+		return *m.Max
+	}
+	return 0
+}
+
+func (m *WithdrawPolicy) GetDailyCap() uint64 {
+	if m != nil && m.DailyCap != nil {
+		return *m.DailyCap
+	}
+	return 0
+}
+
+type GetWithdrawPolicyReq struct {
+	CoinType         *string `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetWithdrawPolicyReq) Reset()                    { *m = GetWithdrawPolicyReq{} }
+func (m *GetWithdrawPolicyReq) String() string            { return proto.CompactTextString(m) }
+func (*GetWithdrawPolicyReq) ProtoMessage()               {}
+func (*GetWithdrawPolicyReq) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{3} }
+
+func (m *GetWithdrawPolicyReq) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+type GetWithdrawPolicyRes struct {
+	Result           *Result           `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Policies         []*WithdrawPolicy `protobuf:"bytes,10,rep,name=policies" json:"policies,omitempty"`
+	XXX_unrecognized []byte            `json:"-"`
+}
+
+func (m *GetWithdrawPolicyRes) Reset()                    { *m = GetWithdrawPolicyRes{} }
+func (m *GetWithdrawPolicyRes) String() string            { return proto.CompactTextString(m) }
+func (*GetWithdrawPolicyRes) ProtoMessage()               {}
+func (*GetWithdrawPolicyRes) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{4} }
+
+func (m *GetWithdrawPolicyRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetWithdrawPolicyRes) GetPolicies() []*WithdrawPolicy {
+	if m != nil {
+		return m.Policies
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*WithdrawalReq)(nil), "pp.WithdrawalReq")
 	proto.RegisterType((*WithdrawalRes)(nil), "pp.WithdrawalRes")
+	proto.RegisterType((*WithdrawPolicy)(nil), "pp.WithdrawPolicy")
+	proto.RegisterType((*GetWithdrawPolicyReq)(nil), "pp.GetWithdrawPolicyReq")
+	proto.RegisterType((*GetWithdrawPolicyRes)(nil), "pp.GetWithdrawPolicyRes")
 }
 
 func init() { proto.RegisterFile("pp.withdrawal.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 179 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x54, 0xcc, 0xc1, 0xaa, 0x82, 0x40,
-	0x14, 0xc6, 0x71, 0x94, 0x7b, 0x25, 0x8f, 0x69, 0x35, 0x45, 0x0c, 0xae, 0xc4, 0x95, 0xab, 0x59,
-	0xb4, 0xef, 0x25, 0xdc, 0x44, 0x2b, 0x31, 0x67, 0x20, 0x49, 0x9d, 0xd3, 0xcc, 0x19, 0xcc, 0xb7,
-	0x0f, 0x0d, 0x82, 0xb6, 0x3f, 0xfe, 0xdf, 0x07, 0x7b, 0x44, 0x31, 0xb6, 0x74, 0x97, 0xa6, 0x1e,
-	0xeb, 0x4e, 0xa0, 0xd1, 0xa4, 0x99, 0x8f, 0x98, 0x6e, 0x10, 0x45, 0xa3, 0xfb, 0x5e, 0x0f, 0x1f,
-	0xcc, 0xaf, 0x10, 0x5f, 0xbe, 0x61, 0xa9, 0x9e, 0x2c, 0x81, 0x00, 0xdd, 0xed, 0xa1, 0x26, 0x0e,
-	0x99, 0x57, 0x84, 0x6c, 0x07, 0x61, 0xa3, 0xdb, 0xa1, 0xa2, 0x09, 0x15, 0x8f, 0x16, 0x8a, 0xe1,
-	0x7f, 0x26, 0xcb, 0xd7, 0x99, 0x57, 0xfc, 0xb1, 0x23, 0x24, 0xda, 0x11, 0x3a, 0xaa, 0x6a, 0x29,
-	0x8d, 0xb2, 0x96, 0xc7, 0x73, 0x96, 0x9f, 0x7f, 0xaf, 0x2d, 0x4b, 0x21, 0x30, 0xca, 0xba, 0x8e,
-	0xb8, 0x97, 0xf9, 0x45, 0x74, 0x02, 0x81, 0x28, 0xca, 0x45, 0xd8, 0x16, 0x56, 0x83, 0x1a, 0x2b,
-	0x7a, 0xb5, 0x92, 0x1f, 0xe6, 0xf9, 0x3b, 0x00, 0x00, 0xff, 0xff, 0x04, 0xf5, 0x53, 0xc7, 0xc4,
-	0x00, 0x00, 0x00,
+	// 265 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x90, 0x31, 0x4f, 0xc3, 0x30,
+	0x10, 0x46, 0x95, 0xa6, 0xad, 0x9a, 0x0b, 0x09, 0xd4, 0x54, 0xe8, 0xd4, 0x29, 0x8a, 0x18, 0xc2,
+	0x92, 0xa1, 0x3b, 0x33, 0x2b, 0xea, 0x02, 0x0c, 0x28, 0x32, 0x89, 0x11, 0x16, 0x49, 0x7c, 0xc4,
+	0x8e, 0xd2, 0xfc, 0x7b, 0x64, 0x23, 0x2a, 0x59, 0x42, 0x8c, 0xf7, 0xfc, 0xee, 0xee, 0x3b, 0xc3,
+	0x35, 0x51, 0x39, 0x49, 0xf3, 0xd1, 0x0c, 0x7c, 0xe2, 0x6d, 0x49, 0x83, 0x32, 0x8a, 0x2d, 0x88,
+	0xf6, 0x97, 0x44, 0x65, 0xad, 0xba, 0x4e, 0xf5, 0x3f, 0x30, 0x7f, 0x81, 0xe4, 0xe9, 0x2c, 0x1e,
+	0xc5, 0x17, 0x4b, 0x61, 0x4d, 0xe3, 0xdb, 0xa7, 0x98, 0x11, 0xb2, 0xa0, 0x88, 0xd8, 0x16, 0xa2,
+	0x5a, 0xc9, 0xbe, 0x32, 0x33, 0x09, 0x8c, 0x1d, 0x4a, 0x60, 0x65, 0x91, 0xc6, 0x8b, 0x2c, 0x28,
+	0x96, 0xec, 0x06, 0x52, 0x35, 0x1a, 0x1a, 0x4d, 0xc5, 0x9b, 0x66, 0x10, 0x5a, 0x63, 0x62, 0xb5,
+	0xfc, 0xde, 0x1f, 0xad, 0xd9, 0x1e, 0xd6, 0x83, 0xd0, 0x63, 0x6b, 0x30, 0xc8, 0x16, 0x45, 0x7c,
+	0x80, 0x92, 0xa8, 0x3c, 0x3a, 0xc2, 0xae, 0x60, 0xd3, 0x8b, 0xa9, 0x32, 0x27, 0xd9, 0xe0, 0xce,
+	0xb5, 0xbf, 0x42, 0xfa, 0xdb, 0xfe, 0xa8, 0x5a, 0x59, 0xcf, 0x7e, 0x94, 0xc0, 0x45, 0x89, 0x21,
+	0x7c, 0x17, 0x02, 0x17, 0x2e, 0x48, 0x0c, 0x61, 0x27, 0x7b, 0x0c, 0xcf, 0x05, 0x3f, 0xe1, 0xd2,
+	0x15, 0x5b, 0x88, 0x1a, 0x2e, 0xdb, 0xb9, 0xaa, 0x39, 0xe1, 0xca, 0xa2, 0xfc, 0x0e, 0x76, 0x0f,
+	0xc2, 0xf8, 0x1b, 0xec, 0xfd, 0xde, 0x12, 0xf7, 0x05, 0xf9, 0xf3, 0x9f, 0xea, 0xff, 0xf7, 0xdc,
+	0xc2, 0x86, 0xac, 0x28, 0x85, 0x46, 0xc8, 0xc2, 0x22, 0x3e, 0x30, 0xfb, 0xea, 0x0f, 0xf9, 0x1e,
+	0x00, 0x76, 0xfa, 0x5e, 0xda, 0xa8, 0x01, 0x00, 0x00,
 }
@@ -2,42 +2,46 @@ package account
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 
 	logging "github.com/op/go-logging"
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
 	"github.com/skycoin/skycoin/src/util/file"
 )
 
 var (
-	acntDir  = filepath.Join(file.UserHome(), ".skycoin-exchange/account")
-	acntName = "account.data"
-	logger   = logging.MustGetLogger("exchange.account")
+	acntDir    = filepath.Join(file.UserHome(), ".skycoin-exchange/account")
+	acntName   = "account.data" // accounts saved before the store, only for migration.
+	accountBkt = "accounts"
+	logger     = logging.MustGetLogger("exchange.account")
+
+	// ErrDepositCredited will be returned if the deposit was already credited to the account.
+	ErrDepositCredited = errors.New("deposit already credited")
 )
 
 type Accounter interface {
 	GetID() string                            // return the account id.
 	GetBalance(ct string) uint64              // return the account's Balance.
 	AddDepositAddress(ct string, addr string) // add the deposit address to the account.
-	DecreaseBalance(ct string, amt uint64) error
-	IncreaseBalance(ct string, amt uint64) error
-	SetBalance(cp string, amt uint64) error
+	HasAddress(ct string, addr string) bool
+	GetHistory(ct string, start, end int64) []History
+	GetWithdrawn(ct string, since int64) uint64 // coins withdrawn since the time, including the fees.
 }
 
-// ExchangeAccount maintains the account state
+// ExchangeAccount maintains the account state, the balances are derived from the journal.
 type ExchangeAccount struct {
-	ID          string              // account id
-	Balance     map[string]uint64   // the Balance should not be accessed directly.
-	Addresses   map[string][]string // deposit addresses
-	addr_mtx    sync.Mutex
-	balance_mtx sync.RWMutex // mutex used to protect the Balance's concurrent read and write.
+	ID        string              // account id
+	Addresses map[string][]string // deposit addresses
+	journal   *Journal
+	dirty     bool // the account has changes that are not stored yet.
+	addr_mtx  sync.Mutex
 }
 
 type exchgAcntJson struct {
 	ID        string              `json:"id"`
-	Balance   map[string]uint64   `json:"balance"`
+	Balance   map[string]uint64   `json:"balance,omitempty"` // only for loading the accounts saved before the journal.
 	Addresses map[string][]string `json:"addresses"`
 }
 
@@ -57,14 +61,11 @@ func InitDir(path string) {
 }
 
 // newExchangeAccount helper function for generating and initialize ExchangeAccount
-func newExchangeAccount(id string) ExchangeAccount {
+func newExchangeAccount(id string, j *Journal) ExchangeAccount {
 	return ExchangeAccount{
-		ID: id,
-		Balance: map[string]uint64{
-			"skycoin": 0,
-			"bitcoin": 0,
-		},
+		ID:        id,
 		Addresses: make(map[string][]string),
+		journal:   j,
 	}
 }
 
@@ -74,84 +75,64 @@ func (self ExchangeAccount) GetID() string {
 
 // Get the current recored Balance.
 func (self *ExchangeAccount) GetBalance(coinType string) uint64 {
-	self.balance_mtx.RLock()
-	defer self.balance_mtx.RUnlock()
-	return self.Balance[coinType]
+	return uint64(self.journal.Balance(self.ID, coinType))
 }
 
 func (self *ExchangeAccount) AddDepositAddress(coinType string, addr string) {
 	self.addr_mtx.Lock()
 	self.Addresses[coinType] = append(self.Addresses[coinType], addr)
+	self.dirty = true
 	self.addr_mtx.Unlock()
 }
 
-// SetBalance update the balanace of specific coin.
-func (self *ExchangeAccount) SetBalance(cp string, amt uint64) error {
-	self.balance_mtx.Lock()
-	defer self.balance_mtx.Unlock()
-	if _, ok := self.Balance[cp]; !ok {
-		return fmt.Errorf("the account does not have %s", cp)
-	}
-	self.Balance[cp] = amt
-	return nil
-}
-
-func (self *ExchangeAccount) DecreaseBalance(ct string, amt uint64) error {
-	self.balance_mtx.Lock()
-	defer self.balance_mtx.Unlock()
-	if _, ok := self.Balance[ct]; !ok {
-		return errors.New("unknow coin type")
-	}
-	if self.Balance[ct] < amt {
-		logger.Debug("balance:%d require:%d", self.Balance[ct], amt)
-		return errors.New("account balance is not sufficient")
+// HasAddress checks if the deposit address belongs to the account.
+func (self *ExchangeAccount) HasAddress(coinType string, addr string) bool {
+	self.addr_mtx.Lock()
+	defer self.addr_mtx.Unlock()
+	for _, a := range self.Addresses[coinType] {
+		if a == addr {
+			return true
+		}
 	}
-
-	self.Balance[ct] -= amt
-	return nil
+	return false
 }
 
-func (self *ExchangeAccount) IncreaseBalance(ct string, amt uint64) error {
-	self.balance_mtx.Lock()
-	defer self.balance_mtx.Unlock()
-	if _, ok := self.Balance[ct]; !ok {
-		return errors.New("unknow coin type")
-	}
-
-	self.Balance[ct] += amt
-	return nil
+func (self *ExchangeAccount) ToMarshalable() exchgAcntJson {
+	self.addr_mtx.Lock()
+	defer self.addr_mtx.Unlock()
+	return self.toMarshalable()
 }
 
-func (self ExchangeAccount) ToMarshalable() exchgAcntJson {
+func (self *ExchangeAccount) toMarshalable() exchgAcntJson {
 	eaj := exchgAcntJson{
 		ID:        self.ID,
-		Balance:   make(map[string]uint64),
 		Addresses: make(map[string][]string),
 	}
 
-	for ct, bal := range self.Balance {
-		eaj.Balance[ct] = bal
-	}
-
 	for ct, addrs := range self.Addresses {
 		eaj.Addresses[ct] = append(eaj.Addresses[ct], addrs...)
 	}
 	return eaj
 }
 
-func (self exchgAcntJson) ToExchgAcnt() *ExchangeAccount {
-	// pk := cipher.PubKey{}
-	// copy(pk[:], self.ID[0:33])
-	at := ExchangeAccount{
-		ID:        self.ID,
-		Balance:   make(map[string]uint64),
-		Addresses: make(map[string][]string),
+// flush writes the account into store if it has changes.
+func (self *ExchangeAccount) flush(tx storage.Tx) error {
+	self.addr_mtx.Lock()
+	defer self.addr_mtx.Unlock()
+	if !self.dirty {
+		return nil
 	}
-
-	// convert balance.
-	for ct, bal := range self.Balance {
-		at.Balance[ct] = bal
+	if err := storage.PutJSON(tx, accountBkt, []byte(self.ID), self.toMarshalable()); err != nil {
+		return err
 	}
+	self.dirty = false
+	return nil
+}
+
+func (self exchgAcntJson) ToExchgAcnt(j *Journal) *ExchangeAccount {
+	// pk := cipher.PubKey{}
+	// copy(pk[:], self.ID[0:33])
+	at := newExchangeAccount(self.ID, j)
 
 	// convert address
 	for ct, addrs := range self.Addresses {
@@ -5,8 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 
-	"github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
 )
 
 func TestInitDir(t *testing.T) {
@@ -34,19 +34,25 @@ func TestGetID(t *testing.T) {
 }
 
 func TestGetBalance(t *testing.T) {
-	a := account.ExchangeAccount{
-		Balance: map[coin.Type]uint64{
-			coin.Bitcoin: 90000,
-			coin.Skycoin: 450000,
-		},
+	m := account.NewManager(storage.NewMemStore())
+	a, err := m.CreateAccountWithPubkey("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.AdjustBalance("1234", "bitcoin", 90000, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AdjustBalance("1234", "skycoin", 450000, "admin"); err != nil {
+		t.Fatal(err)
 	}
 
-	if a.GetBalance(coin.Bitcoin) != 90000 {
+	if a.GetBalance("bitcoin") != 90000 {
 		t.Error("get bitcoin balance failed")
 		return
 	}
 
-	if a.GetBalance(coin.Skycoin) != 450000 {
+	if a.GetBalance("skycoin") != 450000 {
 		t.Error("get skycoin balance failed")
 		return
 	}
@@ -55,38 +61,33 @@ func TestGetBalance(t *testing.T) {
 func TestIncreaseBalance(t *testing.T) {
 	var btcInit uint64 = 90000
 	var skyInit uint64 = 450000
-	testData := map[coin.Type][]struct {
+	testData := map[string][]struct {
 		V      uint64
 		Expect uint64
 	}{
-		coin.Bitcoin: {
+		"bitcoin": {
 			{10000, 100000},
 			{20000, 110000},
 			{1000, 91000},
 			{100, 90100},
 		},
-		coin.Skycoin: {
+		"skycoin": {
 			{10000, 460000},
 			{30000, 480000},
 			{50000, 500000},
 		},
 	}
 
-	for cp, tds := range testData {
+	for ct, tds := range testData {
 		for _, d := range tds {
-			a := account.ExchangeAccount{
-				Balance: map[coin.Type]uint64{
-					coin.Bitcoin: btcInit,
-					coin.Skycoin: skyInit,
-				},
-			}
-			if err := a.IncreaseBalance(cp, d.V); err != nil {
+			a := newTestAccount(t, btcInit, skyInit)
+			if err := a.m.Post(account.NewPosting(account.HistoryDeposit, account.WalletAccount, a.GetID(), ct, d.V, "")); err != nil {
 				t.Error(err)
 				return
 			}
 
-			if a.GetBalance(cp) != d.Expect {
-				t.Errorf("decrease %s balance failed, v:%d, expect:%d", cp, a.GetBalance(cp), d.Expect)
+			if a.GetBalance(ct) != d.Expect {
+				t.Errorf("increase %s balance failed, v:%d, expect:%d", ct, a.GetBalance(ct), d.Expect)
 				return
 			}
 		}
@@ -96,41 +97,63 @@ func TestIncreaseBalance(t *testing.T) {
 func TestDecreaseBalance(t *testing.T) {
 	var btcInit uint64 = 90000
 	var skyInit uint64 = 450000
-	testData := map[coin.Type][]struct {
+	testData := map[string][]struct {
 		V      uint64
 		Expect uint64
 	}{
-		coin.Bitcoin: {
+		"bitcoin": {
 			{10000, 80000},
 			{20000, 70000},
 			{1000, 89000},
 			{100, 89900},
 		},
-		coin.Skycoin: {
+		"skycoin": {
 			{10000, 440000},
 			{30000, 420000},
 			{50000, 400000},
 		},
 	}
 
-	for cp, tds := range testData {
+	for ct, tds := range testData {
 		for _, d := range tds {
-			a := account.ExchangeAccount{
-				Balance: map[coin.Type]uint64{
-					coin.Bitcoin: btcInit,
-					coin.Skycoin: skyInit,
-				},
-			}
-			if err := a.DecreaseBalance(cp, d.V); err != nil {
+			a := newTestAccount(t, btcInit, skyInit)
+			if err := a.m.Post(account.NewPosting(account.HistoryWithdraw, a.GetID(), account.WalletAccount, ct, d.V, "")); err != nil {
 				t.Error(err)
 				return
 			}
-			b := a.GetBalance(cp)
+			b := a.GetBalance(ct)
 			if b != d.Expect {
-				t.Errorf("decrease %s balance failed, v:%d, expect:%d", cp, b, d.Expect)
+				t.Errorf("decrease %s balance failed, v:%d, expect:%d", ct, b, d.Expect)
 				return
 			}
 		}
 	}
 
+	// the balance can't be negative.
+	a := newTestAccount(t, btcInit, skyInit)
+	if err := a.m.Post(account.NewPosting(account.HistoryWithdraw, a.GetID(), account.WalletAccount, "bitcoin", btcInit+1, "")); err == nil {
+		t.Error("decrease balance below zero")
+	}
+}
+
+// testAccount is the account with its manager.
+type testAccount struct {
+	account.Accounter
+	m account.Manager
+}
+
+// newTestAccount creates account with the bitcoin and skycoin balances.
+func newTestAccount(t *testing.T, btc, sky uint64) testAccount {
+	m := account.NewManager(storage.NewMemStore())
+	a, err := m.CreateAccountWithPubkey("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AdjustBalance(a.GetID(), "bitcoin", btc, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AdjustBalance(a.GetID(), "skycoin", sky, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	return testAccount{Accounter: a, m: m}
 }
@@ -0,0 +1,58 @@
+package account
+
+// HistoryType is the reason of the balance change.
+type HistoryType string
+
+const (
+	HistoryDeposit  HistoryType = "deposit"  // deposit output was credited.
+	HistoryWithdraw HistoryType = "withdraw" // coins were sent out by withdrawal.
+	HistoryHold     HistoryType = "hold"     // coins were locked or given back by withdrawal in progress.
+	HistoryAdmin    HistoryType = "admin"    // balance was set by admin.
+	HistoryOrder    HistoryType = "order"    // coins were locked by new order.
+	HistoryCancel   HistoryType = "cancel"   // locked coins were given back by canceling order.
+	HistoryTrade    HistoryType = "trade"    // order was settled by trade.
+	HistoryFee      HistoryType = "fee"      // trading or withdraw fee was collected.
+)
+
+// History records one balance change of the account.
+type History struct {
+	Type      HistoryType `json:"type"`
+	CoinType  string      `json:"coin_type"`
+	Amount    int64       `json:"amount"`     // negative amount means the balance was decreased.
+	Ref       string      `json:"ref"`        // deposit output id, withdraw txid, order id or trade id.
+	CreatedAt int64       `json:"created_at"` // unix time of the change.
+}
+
+// GetHistory returns the account's balance changes of specific coin type, which happened between
+// start and end time, empty coin type means all coins, and 0 end time means no upper limit.
+// The holds of withdrawals are not included, they are either given back or replaced by the
+// withdraw record once the transaction was sent out.
+func (self *ExchangeAccount) GetHistory(ct string, start, end int64) []History {
+	hs := []History{}
+	for _, p := range self.journal.GetPostings(self.ID) {
+		if p.Type == HistoryHold {
+			continue
+		}
+
+		if ct != "" && p.CoinType != ct {
+			continue
+		}
+
+		if p.CreatedAt < start || (end > 0 && p.CreatedAt > end) {
+			continue
+		}
+
+		h := History{
+			Type:      p.Type,
+			CoinType:  p.CoinType,
+			Amount:    int64(p.Amount),
+			Ref:       p.Ref,
+			CreatedAt: p.CreatedAt,
+		}
+		if p.Debit == self.ID {
+			h.Amount = -h.Amount
+		}
+		hs = append(hs, h)
+	}
+	return hs
+}
@@ -0,0 +1,307 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+)
+
+var postingBkt = "postings"
+
+// System accounts of the exchange, the balance of user accounts are liabilities of the exchange,
+// the coins are held by the wallet account, or locked in the escrow and hold accounts.
+const (
+	WalletAccount = "exchange:wallet" // coins held in the exchange's wallets.
+	EscrowAccount = "exchange:escrow" // coins locked by the orders in book.
+	HoldAccount   = "exchange:hold"   // coins locked by the withdrawals in progress.
+	FeeAccount    = "exchange:fee"    // fee income of the exchange.
+	EquityAccount = "exchange:equity" // coins credited by admin, which are not backed by deposits.
+)
+
+// coinTypes records the coin types that can be posted.
+var coinTypes = map[string]bool{
+	"bitcoin": true,
+	"skycoin": true,
+}
+
+// RegisterCoinType allows the coin type to be posted, the coins of trading pairs
+// must be registered when server starts, before any posting.
+func RegisterCoinType(ct string) {
+	coinTypes[ct] = true
+}
+
+// Posting moves coins from the debit account to the credit account, the debit
+// account's balance decreases and the credit account's balance increases.
+type Posting struct {
+	ID        uint64      `json:"id"`
+	Type      HistoryType `json:"type"`
+	Debit     string      `json:"debit"`
+	Credit    string      `json:"credit"`
+	CoinType  string      `json:"coin_type"`
+	Amount    uint64      `json:"amount"`
+	Ref       string      `json:"ref"` // deposit output id, withdraw txid, order id or trade id.
+	CreatedAt int64       `json:"created_at"`
+}
+
+// NewPosting creates posting of current time.
+func NewPosting(tp HistoryType, debit, credit, ct string, amt uint64, ref string) Posting {
+	return Posting{
+		Type:      tp,
+		Debit:     debit,
+		Credit:    credit,
+		CoinType:  ct,
+		Amount:    amt,
+		Ref:       ref,
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+// Journal records all the postings, the balances are derived from them.
+type Journal struct {
+	postings []Posting
+	balances map[string]map[string]int64 // account id -> coin type -> balance.
+	deposits map[string]bool             // credited deposit outputs.
+	stored   int                         // number of postings that have been committed to store.
+	flushed  int                         // number of postings written by the last flush, stored once it's committed.
+	mtx      sync.RWMutex
+}
+
+// NewJournal creates empty journal.
+func NewJournal() *Journal {
+	return &Journal{
+		balances: make(map[string]map[string]int64),
+		deposits: make(map[string]bool),
+	}
+}
+
+// canOverdraw checks if the account's balance can be negative, the wallet and
+// equity accounts are assets, their negative balance means the coins held.
+func canOverdraw(id string) bool {
+	return id == WalletAccount || id == EquityAccount
+}
+
+func depositKey(ct, outputID string) string {
+	return ct + ":" + outputID
+}
+
+// Post records the postings, either all of them or none will be recorded.
+func (j *Journal) Post(ps ...Posting) error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	return j.post(ps...)
+}
+
+func (j *Journal) post(ps ...Posting) error {
+	// check the postings against the balances they would produce.
+	change := make(map[string]map[string]int64)
+	for _, p := range ps {
+		if !coinTypes[p.CoinType] {
+			return errors.New("unknow coin type")
+		}
+
+		if p.Debit == p.Credit {
+			return fmt.Errorf("posting from %s to itself", p.Debit)
+		}
+
+		// the balances are int64.
+		if p.Amount > math.MaxInt64 {
+			return fmt.Errorf("posting amount overflows: %d", p.Amount)
+		}
+
+		if change[p.Debit] == nil {
+			change[p.Debit] = make(map[string]int64)
+		}
+		change[p.Debit][p.CoinType] -= int64(p.Amount)
+
+		if change[p.Credit] == nil {
+			change[p.Credit] = make(map[string]int64)
+		}
+		change[p.Credit][p.CoinType] += int64(p.Amount)
+	}
+
+	for id, cc := range change {
+		if canOverdraw(id) {
+			continue
+		}
+		for ct, v := range cc {
+			if j.balance(id, ct)+v < 0 {
+				logger.Debug("account:%s balance:%d require:%d", id, j.balance(id, ct), -v)
+				return fmt.Errorf("%s balance is not sufficient", ct)
+			}
+		}
+	}
+
+	for _, p := range ps {
+		p.ID = uint64(len(j.postings) + 1)
+		j.apply(p)
+	}
+	return nil
+}
+
+// postDeposit records the deposit posting, each output can only be credited once.
+func (j *Journal) postDeposit(p Posting) error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	if j.deposits[depositKey(p.CoinType, p.Ref)] {
+		return ErrDepositCredited
+	}
+	return j.post(p)
+}
+
+// adjust records the posting between the account and equity account, which makes the
+// account's balance become the given amount.
+func (j *Journal) adjust(id, ct string, amt uint64, ref string) error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	if !coinTypes[ct] {
+		return errors.New("unknow coin type")
+	}
+
+	bal := uint64(j.balance(id, ct))
+	switch {
+	case amt > bal:
+		return j.post(NewPosting(HistoryAdmin, EquityAccount, id, ct, amt-bal, ref))
+	case amt < bal:
+		return j.post(NewPosting(HistoryAdmin, id, EquityAccount, ct, bal-amt, ref))
+	}
+	return nil
+}
+
+func (j *Journal) apply(p Posting) {
+	j.postings = append(j.postings, p)
+	j.add(p.Debit, p.CoinType, -int64(p.Amount))
+	j.add(p.Credit, p.CoinType, int64(p.Amount))
+	if p.Type == HistoryDeposit {
+		j.deposits[depositKey(p.CoinType, p.Ref)] = true
+	}
+}
+
+func (j *Journal) add(id, ct string, v int64) {
+	if j.balances[id] == nil {
+		j.balances[id] = make(map[string]int64)
+	}
+	j.balances[id][ct] += v
+}
+
+// Balance returns the balance of specific account and coin type.
+func (j *Journal) Balance(id, ct string) int64 {
+	j.mtx.RLock()
+	defer j.mtx.RUnlock()
+	return j.balance(id, ct)
+}
+
+func (j *Journal) balance(id, ct string) int64 {
+	return j.balances[id][ct]
+}
+
+// GetPostings returns the postings of specific account.
+func (j *Journal) GetPostings(id string) []Posting {
+	j.mtx.RLock()
+	defer j.mtx.RUnlock()
+	ps := []Posting{}
+	for _, p := range j.postings {
+		if p.Debit == id || p.Credit == id {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// Check verifies the journal, the balances must be the same as replaying all the postings,
+// the balances of each coin must sum up to zero, that is the total liabilities equal the
+// wallet holdings and the equity, and only the wallet and equity can be negative.
+func (j *Journal) Check() error {
+	j.mtx.RLock()
+	defer j.mtx.RUnlock()
+	replay := NewJournal()
+	for i, p := range j.postings {
+		if p.ID != uint64(i+1) {
+			return fmt.Errorf("posting:%d has wrong id:%d", i+1, p.ID)
+		}
+		replay.apply(p)
+	}
+
+	for id, bals := range replay.balances {
+		for ct, v := range bals {
+			if j.balance(id, ct) != v {
+				return fmt.Errorf("account:%s %s balance:%d, replayed:%d", id, ct, j.balance(id, ct), v)
+			}
+		}
+	}
+
+	total := make(map[string]int64)
+	for id, bals := range j.balances {
+		for ct, v := range bals {
+			if replay.balance(id, ct) != v {
+				return fmt.Errorf("account:%s %s balance:%d, replayed:%d", id, ct, v, replay.balance(id, ct))
+			}
+
+			if v < 0 && !canOverdraw(id) {
+				return fmt.Errorf("account:%s %s balance is negative:%d", id, ct, v)
+			}
+			total[ct] += v
+		}
+	}
+
+	for ct, v := range total {
+		if v != 0 {
+			return fmt.Errorf("%s postings are not balanced:%d", ct, v)
+		}
+	}
+	return nil
+}
+
+// flush writes the postings that are not stored yet, they're written again by next flush
+// until committed is called.
+func (j *Journal) flush(tx storage.Tx) error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	for _, p := range j.postings[j.stored:] {
+		if err := storage.PutJSON(tx, postingBkt, storage.Itob(p.ID), p); err != nil {
+			return err
+		}
+	}
+	j.flushed = len(j.postings)
+	return nil
+}
+
+// committed marks the postings written by the last flush as stored, it must be called after
+// the transaction of the flush is committed.
+func (j *Journal) committed() {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	if j.flushed > j.stored {
+		j.stored = j.flushed
+	}
+}
+
+// unflushed returns the postings that are not committed to store yet.
+func (j *Journal) unflushed() []Posting {
+	j.mtx.RLock()
+	defer j.mtx.RUnlock()
+	return append([]Posting{}, j.postings[j.stored:]...)
+}
+
+// loadJournal loads the postings from store.
+func loadJournal(tx storage.Tx) (*Journal, error) {
+	j := NewJournal()
+	err := tx.ForEach(postingBkt, func(k, v []byte) error {
+		var p Posting
+		if err := json.Unmarshal(v, &p); err != nil {
+			return err
+		}
+		j.apply(p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	j.stored = len(j.postings)
+	j.flushed = j.stored
+	return j, nil
+}
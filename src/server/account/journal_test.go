@@ -0,0 +1,146 @@
+package account
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalPost(t *testing.T) {
+	j := NewJournal()
+	assert.Nil(t, j.Post(NewPosting(HistoryDeposit, WalletAccount, "a", "bitcoin", 100, "out1")))
+	assert.Equal(t, int64(100), j.Balance("a", "bitcoin"))
+	assert.Equal(t, int64(-100), j.Balance(WalletAccount, "bitcoin"))
+
+	// invalid postings.
+	assert.NotNil(t, j.Post(NewPosting(HistoryTrade, "a", "b", "unknown", 1, "")))
+	assert.NotNil(t, j.Post(NewPosting(HistoryTrade, "a", "a", "bitcoin", 1, "")))
+	assert.NotNil(t, j.Post(NewPosting(HistoryTrade, "a", "b", "bitcoin", 101, "")))
+	assert.NotNil(t, j.Post(NewPosting(HistoryTrade, WalletAccount, "b", "bitcoin", math.MaxInt64+1, "")))
+
+	// either all of the postings are recorded or none.
+	assert.NotNil(t, j.Post(
+		NewPosting(HistoryTrade, "a", "b", "bitcoin", 60, ""),
+		NewPosting(HistoryTrade, "a", "c", "bitcoin", 60, "")))
+	assert.Equal(t, int64(100), j.Balance("a", "bitcoin"))
+	assert.Equal(t, int64(0), j.Balance("b", "bitcoin"))
+	assert.Equal(t, 1, len(j.postings))
+
+	// the balance is checked against the sum of the postings.
+	assert.Nil(t, j.Post(
+		NewPosting(HistoryTrade, "a", "b", "bitcoin", 60, ""),
+		NewPosting(HistoryTrade, "b", "c", "bitcoin", 50, "")))
+	assert.Equal(t, int64(40), j.Balance("a", "bitcoin"))
+	assert.Equal(t, int64(10), j.Balance("b", "bitcoin"))
+	assert.Equal(t, int64(50), j.Balance("c", "bitcoin"))
+	assert.Equal(t, uint64(3), j.postings[2].ID)
+
+	// only the wallet and equity accounts can be overdrawn.
+	assert.Nil(t, j.Post(NewPosting(HistoryAdmin, EquityAccount, "a", "skycoin", 10, "admin")))
+	assert.NotNil(t, j.Post(NewPosting(HistoryAdmin, FeeAccount, "a", "skycoin", 10, "admin")))
+	assert.Nil(t, j.Check())
+}
+
+func TestJournalDeposit(t *testing.T) {
+	j := NewJournal()
+	assert.Nil(t, j.postDeposit(NewPosting(HistoryDeposit, WalletAccount, "a", "bitcoin", 100, "out1")))
+	assert.Equal(t, ErrDepositCredited, j.postDeposit(NewPosting(HistoryDeposit, WalletAccount, "a", "bitcoin", 100, "out1")))
+	assert.Equal(t, ErrDepositCredited, j.postDeposit(NewPosting(HistoryDeposit, WalletAccount, "b", "bitcoin", 100, "out1")))
+
+	// the outputs of different coins are independent.
+	assert.Nil(t, j.postDeposit(NewPosting(HistoryDeposit, WalletAccount, "a", "skycoin", 100, "out1")))
+	assert.Equal(t, int64(100), j.Balance("a", "bitcoin"))
+	assert.Equal(t, int64(0), j.Balance("b", "bitcoin"))
+
+	// the credited deposits are remembered after reload.
+	s := storage.NewMemStore()
+	assert.Nil(t, s.Update(j.flush))
+	j.committed()
+	var j1 *Journal
+	assert.Nil(t, s.View(func(tx storage.Tx) error {
+		var err error
+		j1, err = loadJournal(tx)
+		return err
+	}))
+	assert.Equal(t, ErrDepositCredited, j1.postDeposit(NewPosting(HistoryDeposit, WalletAccount, "a", "bitcoin", 100, "out1")))
+	assert.Nil(t, j1.postDeposit(NewPosting(HistoryDeposit, WalletAccount, "a", "bitcoin", 100, "out2")))
+	assert.Equal(t, int64(200), j1.Balance("a", "bitcoin"))
+}
+
+func TestCheckJournal(t *testing.T) {
+	newJournal := func() *Journal {
+		j := NewJournal()
+		assert.Nil(t, j.Post(NewPosting(HistoryDeposit, WalletAccount, "a", "bitcoin", 100, "out1")))
+		assert.Nil(t, j.adjust("b", "bitcoin", 50, "admin"))
+		assert.Nil(t, j.Post(NewPosting(HistoryOrder, "a", EscrowAccount, "bitcoin", 30, "1")))
+		assert.Nil(t, j.Check())
+		return j
+	}
+
+	// the balance differs from the postings.
+	j := newJournal()
+	j.balances["a"]["bitcoin"]++
+	assert.NotNil(t, j.Check())
+
+	// the balance without postings.
+	j = newJournal()
+	j.add("c", "bitcoin", 1)
+	j.add(WalletAccount, "bitcoin", -1)
+	assert.NotNil(t, j.Check())
+
+	// the postings are not continuous.
+	j = newJournal()
+	j.postings[1].ID = 5
+	assert.NotNil(t, j.Check())
+
+	// the user account is negative.
+	j = newJournal()
+	j.apply(Posting{ID: 4, Debit: "b", Credit: FeeAccount, CoinType: "bitcoin", Amount: 60})
+	assert.NotNil(t, j.Check())
+}
+
+// failTx fails the Put after the postings are written, so the transaction is not committed.
+type failTx struct {
+	storage.Tx
+}
+
+func (failTx) Put(bucket string, key []byte, value []byte) error {
+	return errors.New("failed")
+}
+
+func TestJournalFlush(t *testing.T) {
+	s := storage.NewMemStore()
+	j := NewJournal()
+	assert.Nil(t, j.Post(NewPosting(HistoryDeposit, WalletAccount, "a", "bitcoin", 100, "out1")))
+
+	// the postings are written again if the transaction is not committed.
+	assert.NotNil(t, s.Update(func(tx storage.Tx) error {
+		return j.flush(failTx{tx})
+	}))
+	assert.Equal(t, 1, len(j.unflushed()))
+	assert.NotNil(t, s.Update(func(tx storage.Tx) error {
+		if err := j.flush(tx); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	}))
+	assert.Equal(t, 1, len(j.unflushed()))
+
+	assert.Nil(t, j.Post(NewPosting(HistoryTrade, "a", "b", "bitcoin", 40, "")))
+	assert.Nil(t, s.Update(j.flush))
+	j.committed()
+	assert.Equal(t, 0, len(j.unflushed()))
+
+	var j1 *Journal
+	assert.Nil(t, s.View(func(tx storage.Tx) error {
+		var err error
+		j1, err = loadJournal(tx)
+		return err
+	}))
+	assert.Equal(t, j.postings, j1.postings)
+	assert.Equal(t, int64(60), j1.Balance("a", "bitcoin"))
+	assert.Nil(t, j1.Check())
+}
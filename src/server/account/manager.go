@@ -8,38 +8,86 @@ import (
 	"path/filepath"
 	"sync"
 
-	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
 )
 
 type Manager interface {
 	CreateAccountWithPubkey(pk string) (Accounter, error)
 	GetAccount(id string) (Accounter, error)
-	Save() error
+	GetAccountByAddress(ct string, addr string) (Accounter, error)
+	Post(ps ...Posting) error                                              // move coins between accounts.
+	CreditDeposit(id string, ct string, outputID string, amt uint64) error // credit the deposit, only once for each output.
+	AdjustBalance(id string, ct string, amt uint64, ref string) error      // set the balance by admin.
+	Balance(id string, ct string) int64                                    // balance of any account, including the system accounts.
+	CheckJournal() error
+	Flush(tx storage.Tx) error // writes the changes into the transaction, for committing with other changes.
+	Committed()                // marks the changes written by the last Flush as committed.
+	Unflushed() []Posting      // postings that are not committed yet, they will be written by next Flush.
+	Save() error               // commits the changes in a new transaction.
 }
 
 // AccountManager manage all the accounts in the server.
 type ExchangeAccountManager struct {
 	Accounts map[string]*ExchangeAccount `json:"accounts"`
+	journal  *Journal
+	store    storage.Store
 	mtx      sync.RWMutex
 }
 
 type exchgAcntMgrJson struct {
 	Accounts []exchgAcntJson `json:"accounts"`
+	Postings []Posting       `json:"postings"`
 }
 
-// NewAccountManager
-func NewManager() Manager {
+// NewManager creates empty account manager, the accounts will be stored in the store.
+func NewManager(s storage.Store) Manager {
 	return &ExchangeAccountManager{
 		Accounts: make(map[string]*ExchangeAccount),
-		// AcntMgrFileName: fileName,
+		journal:  NewJournal(),
+		store:    s,
 	}
 }
 
-// LoadAccountManager from local disk.
-func LoadManager() (Manager, error) {
+// LoadManager loads the accounts and journal from store, the accounts saved in the
+// account.data file by old version will be moved into the store.
+func LoadManager(s storage.Store) (Manager, error) {
+	m := &ExchangeAccountManager{
+		Accounts: make(map[string]*ExchangeAccount),
+		store:    s,
+	}
+
+	err := s.View(func(tx storage.Tx) error {
+		j, err := loadJournal(tx)
+		if err != nil {
+			return err
+		}
+		m.journal = j
+
+		return tx.ForEach(accountBkt, func(k, v []byte) error {
+			eaj := exchgAcntJson{}
+			if err := json.Unmarshal(v, &eaj); err != nil {
+				return err
+			}
+			m.Accounts[eaj.ID] = eaj.ToExchgAcnt(j)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Accounts) > 0 {
+		return m, nil
+	}
+	return loadLegacyManager(s, m)
+}
+
+// loadLegacyManager loads the accounts from account.data file and writes them into store,
+// the empty manager will be returned if the file does not exist.
+func loadLegacyManager(s storage.Store, empty *ExchangeAccountManager) (Manager, error) {
 	p := filepath.Join(acntDir, acntName)
 	if _, err := os.Stat(p); os.IsNotExist(err) {
-		return nil, err
+		return empty, nil
 	}
 
 	a := exchgAcntMgrJson{}
@@ -50,10 +98,18 @@ func LoadManager() (Manager, error) {
 	if err := json.Unmarshal(d, &a); err != nil {
 		return nil, err
 	}
-	return a.ToExchgAcntMgr(), nil
+
+	m := a.ToExchgAcntMgr()
+	m.store = s
+	logger.Info("move %d accounts from %s into store", len(m.Accounts), p)
+	if err := m.Save(); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-// CreateAccountWithPubkey create an accounter with specific pubkey, this pubkey is generated by client.
+// CreateAccountWithPubkey create an accounter with specific pubkey, this pubkey is generated by client,
+// the account will be stored in next Save or Flush.
 func (self *ExchangeAccountManager) CreateAccountWithPubkey(pubkey string) (Accounter, error) {
 	self.mtx.Lock()
 	defer self.mtx.Unlock()
@@ -61,14 +117,9 @@ func (self *ExchangeAccountManager) CreateAccountWithPubkey(pubkey string) (Acco
 	if _, ok := self.Accounts[pubkey]; ok {
 		return nil, errors.New("duplicate account id")
 	}
-	at := newExchangeAccount(pubkey)
+	at := newExchangeAccount(pubkey, self.journal)
+	at.dirty = true
 	self.Accounts[pubkey] = &at
-
-	// save the account into disk
-	if err := self.save(); err != nil {
-		return nil, err
-	}
-
 	return &at, nil
 }
 
@@ -83,36 +134,105 @@ func (self *ExchangeAccountManager) GetAccount(id string) (Accounter, error) {
 	}
 }
 
-func (self ExchangeAccountManager) ToMarshalable() exchgAcntMgrJson {
-	amj := exchgAcntMgrJson{}
+// GetAccountByAddress return the account that owns the deposit address.
+func (self *ExchangeAccountManager) GetAccountByAddress(ct string, addr string) (Accounter, error) {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	for _, account := range self.Accounts {
+		if account.HasAddress(ct, addr) {
+			return account, nil
+		}
+	}
+	return nil, errors.New("account does not exist")
+}
+
+// Post records the postings in journal, either all of them or none will be recorded.
+func (self *ExchangeAccountManager) Post(ps ...Posting) error {
+	return self.journal.Post(ps...)
+}
+
+// CreditDeposit moves the deposit from wallet to the account, ErrDepositCredited
+// will be returned if the output was already credited.
+func (self *ExchangeAccountManager) CreditDeposit(id string, ct string, outputID string, amt uint64) error {
+	return self.journal.postDeposit(NewPosting(HistoryDeposit, WalletAccount, id, ct, amt, outputID))
+}
+
+// AdjustBalance sets the balance of the account, the difference is moved from or to the equity.
+func (self *ExchangeAccountManager) AdjustBalance(id string, ct string, amt uint64, ref string) error {
+	return self.journal.adjust(id, ct, amt, ref)
+}
+
+// Balance returns the balance of specific account, the system accounts are included.
+func (self *ExchangeAccountManager) Balance(id string, ct string) int64 {
+	return self.journal.Balance(id, ct)
+}
+
+// CheckJournal verifies the balances are consistent with the postings.
+func (self *ExchangeAccountManager) CheckJournal() error {
+	return self.journal.Check()
+}
+
+// Flush writes the new postings and the changed accounts into the transaction.
+func (self *ExchangeAccountManager) Flush(tx storage.Tx) error {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	if err := self.journal.flush(tx); err != nil {
+		return err
+	}
 
 	for _, acnt := range self.Accounts {
-		amj.Accounts = append(amj.Accounts, acnt.ToMarshalable())
+		if err := acnt.flush(tx); err != nil {
+			return err
+		}
 	}
-	return amj
+	return nil
 }
 
-func (self *ExchangeAccountManager) Save() error {
-	self.mtx.Lock()
-	defer self.mtx.Unlock()
-	return self.save()
+// Committed marks the postings written by the last Flush as stored, it must be called
+// after the transaction is committed, otherwise they're written again by next Flush.
+func (self *ExchangeAccountManager) Committed() {
+	self.journal.committed()
+}
+
+// Unflushed returns the postings that are not committed yet.
+func (self *ExchangeAccountManager) Unflushed() []Posting {
+	return self.journal.unflushed()
 }
 
-// persistance to disc. Save as JSON
-func (self *ExchangeAccountManager) save() error {
+// Save commits the changes into store.
+func (self *ExchangeAccountManager) Save() error {
 	logger.Debug("save accounts")
-	a := self.ToMarshalable()
-	// for self.Accounts
-	return file.SaveJSON(filepath.Join(acntDir, acntName), a, 0600)
+	if err := self.store.Update(self.Flush); err != nil {
+		return err
+	}
+	self.Committed()
+	return nil
 }
 
 func (self exchgAcntMgrJson) ToExchgAcntMgr() *ExchangeAccountManager {
+	j := NewJournal()
+	for _, p := range self.Postings {
+		j.apply(p)
+	}
+
 	acntMap := make(map[string]*ExchangeAccount, len(self.Accounts))
 	for _, acnt := range self.Accounts {
-		at := acnt.ToExchgAcnt()
+		at := acnt.ToExchgAcnt(j)
+		at.dirty = true
 		acntMap[at.ID] = at
+
+		// the balances saved before the journal are moved from equity.
+		for ct, bal := range acnt.Balance {
+			if bal == 0 {
+				continue
+			}
+			if err := j.post(NewPosting(HistoryAdmin, EquityAccount, at.ID, ct, bal, "opening")); err != nil {
+				logger.Error(err.Error())
+			}
+		}
 	}
 	return &ExchangeAccountManager{
 		Accounts: acntMap,
+		journal:  j,
 	}
 }
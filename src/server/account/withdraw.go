@@ -0,0 +1,135 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+// WithdrawPeriod is the period that the daily cap of withdrawals is summed over.
+var WithdrawPeriod = 24 * time.Hour
+
+// ErrWithdrawOverflow is returned when the withdraw amount plus the fee exceeds the max balance.
+var ErrWithdrawOverflow = errors.New("withdraw amount overflows")
+
+// addAmount returns a+b, the sum must not exceed the max balance, which is int64.
+func addAmount(a, b uint64) (uint64, error) {
+	if a > math.MaxInt64 || b > math.MaxInt64-a {
+		return 0, ErrWithdrawOverflow
+	}
+	return a + b, nil
+}
+
+// WithdrawPolicy is the withdrawal rules of one coin, 0 means no limit.
+type WithdrawPolicy struct {
+	Fee      uint64 `json:"fee"`       // flat fee of each withdrawal, deducted from the balance besides the amount.
+	Min      uint64 `json:"min"`       // minimum amount of one withdrawal.
+	Max      uint64 `json:"max"`       // maximum amount of one withdrawal.
+	DailyCap uint64 `json:"daily_cap"` // maximum coins an account can withdraw in WithdrawPeriod, including the fees.
+	Strategy string `json:"strategy"`  // coin selection strategy of the utxos, coin.DefaultSelector if empty.
+}
+
+// Validate checks the min amount doesn't exceed the max amount, and the strategy is known.
+func (p WithdrawPolicy) Validate() error {
+	if p.Max > 0 && p.Min > p.Max {
+		return errors.New("min withdraw amount must not be greater than max")
+	}
+
+	if p.DailyCap > 0 && p.Fee >= p.DailyCap {
+		return errors.New("withdraw fee must be less than daily cap")
+	}
+
+	if p.Strategy != "" {
+		if _, err := coin.GetSelector(p.Strategy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check checks the withdraw amount against the policy, withdrawn is the coins that
+// the account has withdrawn in WithdrawPeriod, including the fees.
+func (p WithdrawPolicy) Check(amt uint64, withdrawn uint64) error {
+	if amt == 0 {
+		return errors.New("withdraw amount must be greater than 0")
+	}
+
+	if amt < p.Min {
+		return fmt.Errorf("withdraw amount must not be less than %d", p.Min)
+	}
+
+	if p.Max > 0 && amt > p.Max {
+		return fmt.Errorf("withdraw amount must not be greater than %d", p.Max)
+	}
+
+	total, err := p.Total(amt)
+	if err != nil {
+		return err
+	}
+
+	if p.DailyCap > 0 {
+		sum, err := addAmount(withdrawn, total)
+		if err != nil || sum > p.DailyCap {
+			return fmt.Errorf("exceeds daily withdraw cap %d, %d already withdrawn", p.DailyCap, withdrawn)
+		}
+	}
+	return nil
+}
+
+// Total returns the coins deducted from the balance by the withdrawal, which is the amount plus the fee.
+func (p WithdrawPolicy) Total(amt uint64) (uint64, error) {
+	return addAmount(amt, p.Fee)
+}
+
+// LoadWithdrawPolicies loads the policies of coins from json file, which is a map of coin type to policy.
+func LoadWithdrawPolicies(path string) (map[string]WithdrawPolicy, error) {
+	ps := make(map[string]WithdrawPolicy)
+	if err := file.LoadJSON(path, &ps); err != nil {
+		return nil, err
+	}
+
+	for ct, p := range ps {
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("%s withdraw policy: %v", ct, err)
+		}
+	}
+	return ps, nil
+}
+
+// GetWithdrawn returns the coins of specific coin type that the account has withdrawn since
+// the time, including the fees and the withdrawals in progress, whose coins are held.
+func (self *ExchangeAccount) GetWithdrawn(ct string, since int64) uint64 {
+	var n int64
+	for _, p := range self.journal.GetPostings(self.ID) {
+		if p.CoinType != ct || p.CreatedAt < since {
+			continue
+		}
+
+		switch p.Type {
+		case HistoryWithdraw:
+			// the held coins are given back before they're withdrawn.
+			n += int64(p.Amount)
+		case HistoryFee:
+			// the withdraw fee kept by the exchange, the trading fees are paid from the escrow.
+			if p.Debit == self.ID {
+				n += int64(p.Amount)
+			}
+		case HistoryHold:
+			if p.Debit == self.ID {
+				n += int64(p.Amount)
+			} else {
+				n -= int64(p.Amount)
+			}
+		}
+	}
+
+	if n < 0 {
+		// the hold was posted before the period.
+		return 0
+	}
+	return uint64(n)
+}
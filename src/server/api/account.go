@@ -46,3 +46,52 @@ func CreateAccount(ee engine.Exchange) sknet.HandlerFunc {
 		return c.Error(errRlt)
 	}
 }
+
+// GetAccountHistory get the balance changes of the account, filtered by coin type and time range.
+func GetAccountHistory(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		errRlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetAccountHistoryReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				errRlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			// validate pubkey.
+			if err := validatePubkey(req.GetPubkey()); err != nil {
+				logger.Error(err.Error())
+				errRlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			a, err := ee.GetAccount(req.GetPubkey())
+			if err != nil {
+				errRlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
+				break
+			}
+
+			hs := a.GetHistory(req.GetCoinType(), req.GetStart(), req.GetEnd())
+			res := pp.GetAccountHistoryRes{
+				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+				Pubkey:  req.Pubkey,
+				History: make([]*pp.AccountHistory, len(hs)),
+			}
+
+			for i, h := range hs {
+				res.History[i] = &pp.AccountHistory{
+					Type:      pp.PtrString(string(h.Type)),
+					CoinType:  pp.PtrString(h.CoinType),
+					Amount:    pp.PtrInt64(h.Amount),
+					Ref:       pp.PtrString(h.Ref),
+					CreatedAt: pp.PtrInt64(h.CreatedAt),
+				}
+			}
+
+			return c.SendJSON(&res)
+		}
+
+		return c.Error(errRlt)
+	}
+}
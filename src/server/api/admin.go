@@ -1,6 +1,8 @@
 package api
 
 import (
+	"sort"
+
 	"github.com/skycoin/skycoin-exchange/src/pp"
 	"github.com/skycoin/skycoin-exchange/src/server/engine"
 	"github.com/skycoin/skycoin-exchange/src/sknet"
@@ -57,8 +59,8 @@ func UpdateCredit(ee engine.Exchange) sknet.HandlerFunc {
 				break
 			}
 
-			// get coin type.
-			if err := a.SetBalance(req.GetCoinType(), req.GetAmount()); err != nil {
+			// set the balance, the admin's pubkey is recorded as reference.
+			if err := ee.AdjustBalance(a.GetID(), req.GetCoinType(), req.GetAmount(), req.GetPubkey()); err != nil {
 				logger.Error(err.Error())
 				rlt = pp.MakeErrRes(err)
 				break
@@ -73,3 +75,37 @@ func UpdateCredit(ee engine.Exchange) sknet.HandlerFunc {
 		return c.Error(rlt)
 	}
 }
+
+// GetFees get the accrued trading fees of each coin.
+func GetFees(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.GetFeesReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			fees := ee.GetFeeRevenue()
+			cts := make([]string, 0, len(fees))
+			for ct := range fees {
+				cts = append(cts, ct)
+			}
+			sort.Strings(cts)
+
+			res := pp.GetFeesRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			for _, ct := range cts {
+				res.Fees = append(res.Fees, &pp.FeeRevenue{
+					CoinType: pp.PtrString(ct),
+					Amount:   pp.PtrUint64(fees[ct]),
+				})
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
@@ -16,3 +16,24 @@ func GetCoins(egn engine.Exchange) sknet.HandlerFunc {
 		return c.SendJSON(&coins)
 	}
 }
+
+// GetPairs get the trading pairs and their rules.
+func GetPairs(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		pairs := egn.GetPairs()
+		res := pp.PairsRes{
+			Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			Pairs:  make([]*pp.Pair, len(pairs)),
+		}
+		for i, p := range pairs {
+			res.Pairs[i] = &pp.Pair{
+				Name:     pp.PtrString(p.Name),
+				TickSize: pp.PtrUint64(p.TickSize),
+				LotSize:  pp.PtrUint64(p.LotSize),
+				MinOrder: pp.PtrUint64(p.MinOrder),
+				Enabled:  pp.PtrBool(p.Enabled),
+			}
+		}
+		return c.SendJSON(&res)
+	}
+}
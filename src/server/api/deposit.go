@@ -34,10 +34,21 @@ func GetNewAddress(ee engine.Exchange) sknet.HandlerFunc {
 
 			ct := req.GetCoinType()
 			// get the new address for depositing
-			addr := ee.GetNewAddress(ct)
+			addr, err := ee.GetNewAddress(ct)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
 
 			// add the new address to engin for watching it's utxos.
 			at.AddDepositAddress(ct, addr)
+			// the deposits are credited by address, so the address must be saved.
+			if err := ee.SaveAccount(); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
 			ee.WatchAddress(ct, addr)
 
 			ds := pp.GetDepositAddrRes{
@@ -39,6 +39,30 @@ func CreateOrder(egn engine.Exchange) sknet.HandlerFunc {
 				break
 			}
 
+			kind, err := order.KindFromStr(req.GetKind())
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			tif, err := order.TimeInForceFromStr(req.GetTimeInForce())
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			odr := order.New(pubkey, op, req.GetPrice(), req.GetAmount())
+			odr.Kind = kind
+			odr.TimeInForce = tif
+			odr.PostOnly = req.GetPostOnly()
+			if err := odr.Check(); err != nil {
+				rlt = pp.MakeErrRes(err)
+				logger.Error(err.Error())
+				break
+			}
+
 			// find the account
 			acnt, err := egn.GetAccount(pubkey)
 			if err != nil {
@@ -47,7 +71,7 @@ func CreateOrder(egn engine.Exchange) sknet.HandlerFunc {
 				break
 			}
 
-			cp, bal, err := needBalance(op, req)
+			cp, bal, err := needBalance(*odr, req.GetCoinPair())
 			if err != nil {
 				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
 				logger.Error(err.Error())
@@ -61,33 +85,15 @@ func CreateOrder(egn engine.Exchange) sknet.HandlerFunc {
 				break
 			}
 
-			var success bool
-			if op == order.Bid {
-				defer func() {
-					if success {
-						egn.SaveAccount()
-					} else {
-						acnt.IncreaseBalance(cp, bal)
-					}
-				}()
-				// decrease the balance, in case of double use the coins.
-				logger.Info("account:%s decrease %s:%d", acnt.GetID(), cp, bal)
-				if err := acnt.DecreaseBalance(cp, bal); err != nil {
-					rlt = pp.MakeErrRes(err)
-					logger.Error(err.Error())
-					break
-				}
-			}
-
-			odr := order.New(pubkey, op, req.GetPrice(), req.GetAmount())
+			// the escrow is locked by engine when adding the order,
+			// the escrow of market bid is decided by the asks in book.
 			oid, err := egn.AddOrder(req.GetCoinPair(), *odr)
 			if err != nil {
 				logger.Error(err.Error())
-				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				rlt = pp.MakeErrRes(err)
 				break
 			}
-			success = true
-			logger.Info(fmt.Sprintf("new %s order:%d", op, oid))
+			logger.Info(fmt.Sprintf("new %s %s %s order:%d", kind, tif, op, oid))
 			res := pp.OrderRes{
 				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
 				OrderId: &oid,
@@ -144,8 +150,316 @@ func GetOrders(egn engine.Exchange) sknet.HandlerFunc {
 	}
 }
 
-func needBalance(tp order.Type, req *pp.OrderReq) (string, uint64, error) {
-	pair := strings.Split(req.GetCoinPair(), "/")
+const (
+	defaultDepthLevels = 20  // number of price levels returned if not specified.
+	maxDepthLevels     = 500 // maximum number of price levels of each side.
+)
+
+// GetDepth get the price levels of each side of the book, the rest amounts
+// of the orders at the same price are summed up.
+func GetDepth(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetDepthReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			n := int(req.GetLevels())
+			switch {
+			case n <= 0:
+				n = defaultDepthLevels
+			case n > maxDepthLevels:
+				n = maxDepthLevels
+			}
+
+			bids, asks, err := egn.GetDepth(req.GetCoinPair(), n)
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			res := pp.GetDepthRes{
+				CoinPair: req.CoinPair,
+				Bids:     makePriceLevels(bids),
+				Asks:     makePriceLevels(asks),
+			}
+			res.Result = pp.MakeResultWithCode(pp.ErrCode_Success)
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+func makePriceLevels(pls []order.PriceLevel) []*pp.PriceLevel {
+	levels := make([]*pp.PriceLevel, len(pls))
+	for i := range pls {
+		levels[i] = &pp.PriceLevel{
+			Price:  &pls[i].Price,
+			Amount: &pls[i].Amount,
+		}
+	}
+	return levels
+}
+
+// CancelOrder cancel the order, only the owner of the order can cancel it.
+func CancelOrder(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.CancelOrderReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			// validate pubkey
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			odr, err := egn.CancelOrder(req.GetCoinPair(), req.GetOrderId(), pubkey)
+			if err != nil {
+				logger.Error(err.Error())
+				switch err {
+				case order.ErrOrderNotExist:
+					rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
+				case order.ErrNotOrderOwner:
+					rlt = pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized)
+				default:
+					rlt = pp.MakeErrRes(err)
+				}
+				break
+			}
+
+			res := pp.CancelOrderRes{
+				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+				OrderId: &odr.ID,
+				Refund:  pp.PtrUint64(odr.RestEscrow()),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// CreateConditionalOrder creates stop-loss or take-profit order, the order is placed into
+// book when the last trade price crosses its trigger price.
+func CreateConditionalOrder(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		req := &pp.ConditionalOrderReq{}
+		for {
+			if err := c.BindJSON(req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			// validate pubkey
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			cond, err := makeConditional(pubkey, req)
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			if err := cond.Check(); err != nil {
+				rlt = pp.MakeErrRes(err)
+				logger.Error(err.Error())
+				break
+			}
+
+			// find the account
+			acnt, err := egn.GetAccount(pubkey)
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				logger.Error(err.Error())
+				break
+			}
+
+			cp, bal, err := needBalance(cond.Order, req.GetCoinPair())
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			if acnt.GetBalance(cp) < bal {
+				err := fmt.Errorf("%s balance is not sufficient", cp)
+				rlt = pp.MakeErrRes(err)
+				logger.Debug(err.Error())
+				break
+			}
+
+			// the escrow is locked by engine when adding the order, not when it's triggered.
+			oid, err := egn.AddConditional(req.GetCoinPair(), cond)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+			logger.Info(fmt.Sprintf("new %s %s order:%d, trigger price:%d", cond.Trigger, cond.Type, oid, cond.TriggerPrice))
+			res := pp.OrderRes{
+				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+				OrderId: &oid,
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// makeConditional creates conditional order from the request.
+func makeConditional(pubkey string, req *pp.ConditionalOrderReq) (order.Conditional, error) {
+	op, err := order.TypeFromStr(req.GetType())
+	if err != nil {
+		return order.Conditional{}, err
+	}
+
+	kind, err := order.KindFromStr(req.GetKind())
+	if err != nil {
+		return order.Conditional{}, err
+	}
+
+	tif, err := order.TimeInForceFromStr(req.GetTimeInForce())
+	if err != nil {
+		return order.Conditional{}, err
+	}
+
+	tg, err := order.TriggerFromStr(req.GetTrigger())
+	if err != nil {
+		return order.Conditional{}, err
+	}
+
+	odr := order.New(pubkey, op, req.GetPrice(), req.GetAmount())
+	odr.Kind = kind
+	odr.TimeInForce = tif
+	return order.Conditional{
+		Order:        *odr,
+		Trigger:      tg,
+		TriggerPrice: req.GetTriggerPrice(),
+	}, nil
+}
+
+// GetConditionalOrders gets the conditional orders of the account that are not triggered yet.
+func GetConditionalOrders(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetConditionalOrderReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			// validate pubkey
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			cs, err := egn.GetConditionals(req.GetCoinPair(), pubkey)
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			res := pp.GetConditionalOrderRes{
+				CoinPair: req.CoinPair,
+				Orders:   make([]*pp.ConditionalOrder, len(cs)),
+			}
+
+			for i := range cs {
+				res.Orders[i] = &pp.ConditionalOrder{
+					Id:           &cs[i].ID,
+					Type:         pp.PtrString(cs[i].Type.String()),
+					Kind:         pp.PtrString(cs[i].Kind.String()),
+					TimeInForce:  pp.PtrString(cs[i].TimeInForce.String()),
+					Price:        &cs[i].Price,
+					Amount:       &cs[i].Amount,
+					Trigger:      pp.PtrString(cs[i].Trigger.String()),
+					TriggerPrice: &cs[i].TriggerPrice,
+					CreatedAt:    &cs[i].CreatedAt,
+				}
+			}
+
+			res.Result = pp.MakeResultWithCode(pp.ErrCode_Success)
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// CancelConditionalOrder cancel the conditional order that is not triggered yet,
+// only the owner of the order can cancel it.
+func CancelConditionalOrder(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.CancelOrderReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			// validate pubkey
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			cond, err := egn.CancelConditional(req.GetCoinPair(), req.GetOrderId(), pubkey)
+			if err != nil {
+				logger.Error(err.Error())
+				switch err {
+				case order.ErrOrderNotExist:
+					rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
+				case order.ErrNotOrderOwner:
+					rlt = pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized)
+				default:
+					rlt = pp.MakeErrRes(err)
+				}
+				break
+			}
+
+			res := pp.CancelOrderRes{
+				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+				OrderId: &cond.ID,
+				Refund:  pp.PtrUint64(cond.RestEscrow()),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// needBalance returns the coin type and the balance required by the order,
+// market bid has no price, so its balance is checked when locking the escrow.
+func needBalance(od order.Order, cp string) (string, uint64, error) {
+	pair := strings.Split(cp, "/")
 	if len(pair) != 2 {
 		return "", 0, errors.New("error coin pair")
 	}
@@ -153,11 +467,18 @@ func needBalance(tp order.Type, req *pp.OrderReq) (string, uint64, error) {
 	mainCt := pair[0]
 	subCt := pair[1]
 
-	switch tp {
+	switch od.Type {
 	case order.Bid:
-		return subCt, req.GetPrice() * req.GetAmount(), nil
+		bal, err := order.Volume(od.Price, od.Amount)
+		if err != nil {
+			return "", 0, err
+		}
+		return subCt, bal, nil
 	case order.Ask:
-		return mainCt, req.GetAmount(), nil
+		if od.Amount > order.MaxVolume {
+			return "", 0, order.ErrOverflow
+		}
+		return mainCt, od.Amount, nil
 	default:
 		return "", 0, errors.New("unknow order type")
 	}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/engine"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+)
+
+// Subscribe subscribes the topics, the events of them are pushed on the same connection
+// after the response, until the connection is closed. The account topic requires the sig
+// of the account key over the replay stamp of the request.
+func Subscribe(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.SubscribeReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if hasTopic(req.GetTopics(), "account") {
+				if err := sknet.VerifyProof(c.Request.GetPath(), req.GetReqTime(), req.GetReqNonce(), req.GetPubkey(), req.GetSig()); err != nil {
+					logger.Error(err.Error())
+					rlt = pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized)
+					break
+				}
+			}
+
+			topics, err := egn.ResolveTopics(req.GetPubkey(), req.GetTopics())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.SubscribeRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+				Topics: req.Topics,
+			}
+			return egn.GetHub().Subscribe(c, &res, topics...)
+		}
+		return c.Error(rlt)
+	}
+}
+
+func hasTopic(topics []string, topic string) bool {
+	for _, t := range topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
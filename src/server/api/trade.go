@@ -0,0 +1,217 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/engine"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+)
+
+// GetTrades get executed trades of specific coin pair, the latest trade's index is 0.
+func GetTrades(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetTradesReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			trades, err := egn.GetTrades(req.GetCoinPair(), req.GetStart(), req.GetEnd())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			res := pp.GetTradesRes{
+				CoinPair: req.CoinPair,
+				Trades:   make([]*pp.Trade, len(trades)),
+			}
+
+			for i := range trades {
+				res.Trades[i] = &pp.Trade{
+					Id:        &trades[i].ID,
+					MakerId:   &trades[i].MakerID,
+					TakerId:   &trades[i].TakerID,
+					TakerType: pp.PtrString(trades[i].TakerType.String()),
+					Price:     &trades[i].Price,
+					Amount:    &trades[i].Amount,
+					CreatedAt: &trades[i].CreatedAt,
+					BidFee:    &trades[i].BidFee,
+					AskFee:    &trades[i].AskFee,
+				}
+			}
+
+			res.Result = pp.MakeResultWithCode(pp.ErrCode_Success)
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// GetAccountFills get the trades of the account's orders.
+func GetAccountFills(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetAccountFillsReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			// validate pubkey
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			if _, err := egn.GetAccount(pubkey); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
+				break
+			}
+
+			trades, err := egn.GetAccountTrades(req.GetCoinPair(), pubkey, req.GetStart(), req.GetEnd())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			res := pp.GetAccountFillsRes{
+				CoinPair: req.CoinPair,
+			}
+
+			// the bidder pays the fee in main coin, and the asker in sub coin.
+			coins := strings.Split(req.GetCoinPair(), "/")
+
+			for _, t := range trades {
+				// self-trade fills both sides of the account.
+				if t.BidAccountID == pubkey {
+					res.Fills = append(res.Fills, &pp.Fill{
+						TradeId:   pp.PtrUint64(t.ID),
+						OrderId:   pp.PtrUint64(t.BidID()),
+						Type:      pp.PtrString(order.Bid.String()),
+						Maker:     pp.PtrBool(t.BidID() == t.MakerID),
+						Price:     pp.PtrUint64(t.Price),
+						Amount:    pp.PtrUint64(t.Amount),
+						CreatedAt: pp.PtrInt64(t.CreatedAt),
+						Fee:       pp.PtrUint64(t.BidFee),
+						FeeCoin:   pp.PtrString(coins[0]),
+					})
+				}
+
+				if t.AskAccountID == pubkey {
+					res.Fills = append(res.Fills, &pp.Fill{
+						TradeId:   pp.PtrUint64(t.ID),
+						OrderId:   pp.PtrUint64(t.AskID()),
+						Type:      pp.PtrString(order.Ask.String()),
+						Maker:     pp.PtrBool(t.AskID() == t.MakerID),
+						Price:     pp.PtrUint64(t.Price),
+						Amount:    pp.PtrUint64(t.Amount),
+						CreatedAt: pp.PtrInt64(t.CreatedAt),
+						Fee:       pp.PtrUint64(t.AskFee),
+						FeeCoin:   pp.PtrString(coins[1]),
+					})
+				}
+			}
+
+			res.Result = pp.MakeResultWithCode(pp.ErrCode_Success)
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// GetTicker get the last price, the high, low and volume in 24 hours, and the best bid and ask.
+func GetTicker(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetTickerReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			tk, err := egn.GetTicker(req.GetCoinPair())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			res := pp.GetTickerRes{
+				Result:   pp.MakeResultWithCode(pp.ErrCode_Success),
+				CoinPair: req.CoinPair,
+				Last:     &tk.Last,
+				High:     &tk.High,
+				Low:      &tk.Low,
+				Volume:   &tk.Volume,
+				BestBid:  &tk.BestBid,
+				BestAsk:  &tk.BestAsk,
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// maxCandles is the maximum number of candles returned in one request, the latest ones are returned.
+const maxCandles = 1000
+
+// GetCandles get the OHLCV candles of specific coin pair and interval, whose open time is in [start, end).
+func GetCandles(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetCandlesReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			candles, err := egn.GetCandles(req.GetCoinPair(), req.GetInterval(), req.GetStart(), req.GetEnd())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if len(candles) > maxCandles {
+				candles = candles[len(candles)-maxCandles:]
+			}
+
+			res := pp.GetCandlesRes{
+				CoinPair: req.CoinPair,
+				Interval: req.Interval,
+				Candles:  make([]*pp.Candle, len(candles)),
+			}
+
+			for i := range candles {
+				res.Candles[i] = &pp.Candle{
+					Time:   &candles[i].Time,
+					Open:   &candles[i].Open,
+					High:   &candles[i].High,
+					Low:    &candles[i].Low,
+					Close:  &candles[i].Close,
+					Volume: &candles[i].Volume,
+				}
+			}
+
+			res.Result = pp.MakeResultWithCode(pp.ErrCode_Success)
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
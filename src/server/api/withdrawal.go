@@ -1,9 +1,9 @@
 package api
 
 import (
-	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
@@ -19,20 +19,6 @@ import (
 // ChooseUtxoTm max time that will be allowed in choosing sufficient utxos.
 var ChooseUtxoTm = 5 * time.Second
 
-// BtcTxResult be used in creating bitcoin withdraw transaction.
-type BtcTxResult struct {
-	Tx         *bitcoin.Transaction
-	UsingUtxos []bitcoin.Utxo
-	ChangeAddr string
-}
-
-// SkyTxResult be used in creating skycoin withdraw transaction.
-type SkyTxResult struct {
-	Tx         *skycoin.Transaction
-	UsingUtxos []skycoin.Utxo
-	ChangeAddr string
-}
-
 func getWithdrawReqParams(c *sknet.Context, ee engine.Exchange) (*ReqParams, error) {
 	rp := NewReqParams()
 	req := pp.WithdrawalReq{}
@@ -75,16 +61,16 @@ func Withdraw(ee engine.Exchange) sknet.HandlerFunc {
 			amt := reqParam.Values["amt"].(uint64)
 			outAddr := reqParam.Values["outAddr"].(string)
 
-			// get handler for creating txIns and txOuts base on the coin type.
-			createTxInOut, err := getTxInOutHandler(cp)
+			// get handler for creating txIns and txOuts base on the coin family.
+			createTxInOut, err := getTxInOutHandler(ee, cp)
 			if err != nil {
 				logger.Error(err.Error())
 				rlt = pp.MakeErrRes(err)
 				break
 			}
 
-			// create txIns and txOuts.
-			inOutSet, err := createTxInOut(ee, a, amt, outAddr)
+			// check the withdrawal against the policy of the coin, and hold the coins before choosing utxos.
+			fee, err := ee.HoldWithdrawal(a.GetID(), cp, amt)
 			if err != nil {
 				logger.Error(err.Error())
 				rlt = pp.MakeErrRes(err)
@@ -94,8 +80,23 @@ func Withdraw(ee engine.Exchange) sknet.HandlerFunc {
 			var success bool
 			defer func() {
 				if !success {
-					// if not success, invoke the teardown, for putting back utxos, and reset balance.
-					inOutSet.Teardown()
+					releaseBalance(ee, a, cp, amt+fee)
+				}
+			}()
+
+			// create txIns and txOuts.
+			inOutSet, err := createTxInOut(ee, cp, amt, fee, outAddr)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			defer func() {
+				if success {
+					ee.MarkUtxosSpent(cp, inOutSet.Utxos)
+				} else {
+					ee.ReleaseUtxos(cp, inOutSet.Utxos)
 				}
 			}()
 
@@ -132,6 +133,16 @@ func Withdraw(ee engine.Exchange) sknet.HandlerFunc {
 			}
 
 			success = true
+			if inOutSet.ChangeAddr != "" {
+				ee.WatchAddress(cp, inOutSet.ChangeAddr)
+			}
+			if err := withdrawHeld(ee, a, cp, inOutSet.Amount, inOutSet.Fee, txid); err != nil {
+				logger.Error(err.Error())
+			}
+			if err := ee.SaveAccount(); err != nil {
+				logger.Error(err.Error())
+			}
+
 			resp := pp.WithdrawalRes{
 				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
 				NewTxid: &txid,
@@ -142,350 +153,200 @@ func Withdraw(ee engine.Exchange) sknet.HandlerFunc {
 	}
 }
 
+// GetWithdrawPolicy get the withdrawal fee and limits of specific coin, or all coins if coin type is empty.
+func GetWithdrawPolicy(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.GetWithdrawPolicyReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			ps := ee.GetWithdrawPolicies()
+			cts := []string{}
+			if req.GetCoinType() != "" {
+				if _, ok := ps[req.GetCoinType()]; !ok {
+					rlt = pp.MakeErrRes(fmt.Errorf("%s coin is not supported", req.GetCoinType()))
+					break
+				}
+				cts = append(cts, req.GetCoinType())
+			} else {
+				for ct := range ps {
+					cts = append(cts, ct)
+				}
+				sort.Strings(cts)
+			}
+
+			res := pp.GetWithdrawPolicyRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			for _, ct := range cts {
+				p := ps[ct]
+				res.Policies = append(res.Policies, &pp.WithdrawPolicy{
+					CoinType: pp.PtrString(ct),
+					Fee:      pp.PtrUint64(p.Fee),
+					Min:      pp.PtrUint64(p.Min),
+					Max:      pp.PtrUint64(p.Max),
+					DailyCap: pp.PtrUint64(p.DailyCap),
+				})
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
 func getAddrPrivKey(ee engine.Exchange, cp string) coin.GetPrivKey {
 	return func(addr string) (string, error) {
 		return ee.GetAddrPrivKey(cp, addr)
 	}
 }
 
-// txInOutHandler used to generate TxIns and txOuts.
-type txInOutHandler func(ee engine.Exchange, a account.Accounter, amount uint64, outAddr string) (*txInOutResult, error)
+// txInOutHandler used to generate TxIns and txOuts, the amount and fee are already held from the account.
+type txInOutHandler func(ee engine.Exchange, ct string, amount uint64, fee uint64, outAddr string) (*txInOutResult, error)
+
+// getTxInOutHandler returns the handler of the coin's family, the forks of skycoin share its tx format.
+func getTxInOutHandler(ee engine.Exchange, ct string) (txInOutHandler, error) {
+	c, err := ee.GetCoin(ct)
+	if err != nil {
+		return nil, err
+	}
 
-// global txInOut handlers, if new coin type need to be supported, register here.
-var txInOutHandlers = map[string]txInOutHandler{
-	bitcoin.Type: createBtcTxInOut,
-	skycoin.Type: createSkyTxInOut,
+	switch c.(type) {
+	case *bitcoin.Bitcoin:
+		return createBtcTxInOut, nil
+	case skycoin.Gateway:
+		return createSkyTxInOut, nil
+	default:
+		return nil, fmt.Errorf("%s tx in handler not found", ct)
+	}
 }
 
-func getTxInOutHandler(cp string) (txInOutHandler, error) {
-	if hd, ok := txInOutHandlers[cp]; ok {
-		return hd, nil
+// releaseBalance puts the held coins back to the account.
+func releaseBalance(ee engine.Exchange, a account.Accounter, ct string, amt uint64) {
+	if err := ee.Post(account.NewPosting(account.HistoryHold, account.HoldAccount, a.GetID(), ct, amt, "")); err != nil {
+		logger.Error(err.Error())
 	}
-	return nil, fmt.Errorf("%s tx in handler not found", cp)
+}
+
+// withdrawHeld records the held coins have been sent out of the exchange's wallet,
+// the fee that is not paid to the network is kept by the exchange.
+func withdrawHeld(ee engine.Exchange, a account.Accounter, ct string, amt uint64, fee uint64, txid string) error {
+	ps := []account.Posting{
+		account.NewPosting(account.HistoryHold, account.HoldAccount, a.GetID(), ct, amt+fee, ""),
+		account.NewPosting(account.HistoryWithdraw, a.GetID(), account.WalletAccount, ct, amt, txid),
+	}
+	if fee > 0 {
+		ps = append(ps, account.NewPosting(account.HistoryFee, a.GetID(), account.FeeAccount, ct, fee, txid))
+	}
+	return ee.Post(ps...)
 }
 
 type txInOutResult struct {
-	TxIns    []coin.TxIn // transaction in values.
-	TxOuts   interface{} // transaction out values, must be a slice.
-	Teardown func()      // function for put back the choosen utxos, and reset balance,etc.
+	TxIns      []coin.TxIn // transaction in values.
+	TxOuts     interface{} // transaction out values, must be a slice.
+	Amount     uint64      // coins sent out of the wallet, including the network fee.
+	Fee        uint64      // withdraw fee kept by the exchange.
+	ChangeAddr string      // address of the change output, empty if no change.
+	Utxos      []coin.Utxo // the reserved utxos, which are released if the withdrawal failed.
 }
 
-func createBtcTxInOut(ee engine.Exchange, a account.Accounter, amount uint64, outAddr string) (*txInOutResult, error) {
+// createBtcTxInOut creates the bitcoin tx, the withdraw fee is paid to the network.
+func createBtcTxInOut(ee engine.Exchange, ct string, amount uint64, fee uint64, outAddr string) (*txInOutResult, error) {
 	var rlt txInOutResult
 	// verify the outAddr
 	if _, err := cipher.BitcoinDecodeBase58Address(outAddr); err != nil {
 		return nil, errors.New("invalid bitcoin address")
 	}
 
-	var err error
-	// decrease balance and check if the balance is sufficient.
-	if err := a.DecreaseBalance("bitcoin", amount+ee.GetBtcFee()); err != nil {
-		return nil, err
-	}
-
-	var utxos []bitcoin.Utxo
-
 	// choose sufficient utxos.
-	uxs, err := ee.ChooseUtxos("bitcoin", amount+ee.GetBtcFee(), ChooseUtxoTm)
+	utxos, err := ee.ChooseUtxos(ct, amount+fee, ChooseUtxoTm)
 	if err != nil {
 		return nil, err
 	}
-	utxos = uxs.([]bitcoin.Utxo)
 
-	for _, u := range utxos {
+	var totalAmounts uint64
+	for _, ux := range utxos {
+		u := ux.(bitcoin.Utxo)
 		logger.Debug("using utxos: txid:%s vout:%d addr:%s", u.GetTxid(), u.GetVout(), u.GetAddress())
 		rlt.TxIns = append(rlt.TxIns, coin.TxIn{
 			Txid: u.GetTxid(),
 			Vout: u.GetVout(),
 		})
-	}
-
-	var totalAmounts uint64
-	for _, u := range utxos {
 		totalAmounts += u.GetAmount()
 	}
-	fee := ee.GetBtcFee()
-	txOuts := []bitcoin.TxOut{}
-	chgAmt := totalAmounts - fee - amount
-	chgAddr := ""
-	if chgAmt > 0 {
+
+	txOuts := []bitcoin.TxOut{{Addr: outAddr, Value: amount}}
+	if chgAmt := totalAmounts - fee - amount; chgAmt > 0 {
 		// generate a change address
-		chgAddr = ee.GetNewAddress(bitcoin.Type)
-		txOuts = append(txOuts,
-			bitcoin.TxOut{Addr: outAddr, Value: amount},
-			bitcoin.TxOut{Addr: chgAddr, Value: chgAmt})
-	} else {
-		txOuts = append(txOuts, bitcoin.TxOut{Addr: outAddr, Value: amount})
+		chgAddr, err := ee.GetNewAddress(ct)
+		if err != nil {
+			ee.ReleaseUtxos(ct, utxos)
+			return nil, err
+		}
+		txOuts = append(txOuts, bitcoin.TxOut{Addr: chgAddr, Value: chgAmt})
+		rlt.ChangeAddr = chgAddr
 	}
 
 	rlt.TxOuts = txOuts
-	rlt.Teardown = func() {
-		a.IncreaseBalance(bitcoin.Type, amount+ee.GetBtcFee())
-		ee.PutUtxos(bitcoin.Type, utxos)
-	}
-
+	rlt.Utxos = utxos
+	rlt.Amount = amount + fee
 	return &rlt, nil
 }
 
-func createSkyTxInOut(ee engine.Exchange, a account.Accounter, amount uint64, outAddr string) (*txInOutResult, error) {
-	return nil, nil
-}
-
-func btcWithdraw(rp *ReqParams) (*pp.WithdrawalRes, *pp.EmptyRes) {
-	ee := rp.Values["engine"].(engine.Exchange)
-	acnt := rp.Values["account"].(account.Accounter)
-	amt := rp.Values["amt"].(uint64)
-	ct := rp.Values["cointype"].(string)
-	toAddr := rp.Values["toAddr"].(string)
-	// verify the toAddr
-	if _, err := cipher.BitcoinDecodeBase58Address(toAddr); err != nil {
-		return nil, pp.MakeErrRes(errors.New("invalid bitcoin address"))
-	}
-	var success bool
-	var btcTxRlt *BtcTxResult
-	var err error
-	// decrease balance and check if the balance is sufficient.
-	if err := acnt.DecreaseBalance(ct, amt+ee.GetBtcFee()); err != nil {
-		return nil, pp.MakeErrRes(err)
-	}
-	defer func() {
-		if !success {
-			go func() {
-				if btcTxRlt != nil {
-					ee.PutUtxos(bitcoin.Type, btcTxRlt.UsingUtxos)
-				}
-				acnt.IncreaseBalance(ct, amt+ee.GetBtcFee())
-			}()
-		} else {
-			//TODO: handle the saving failure.
-			ee.SaveAccount()
-		}
-	}()
-
-	btcTxRlt, err = createBtcWithdrawTx(ee, amt, toAddr)
-	if err != nil {
-		return nil, pp.MakeErrRes(errors.New("failed to create withdrawal tx"))
-	}
-
-	rawtx, err := btcTxRlt.Tx.Serialize()
-	if err != nil {
-		return nil, pp.MakeErrRes(errors.New("tx serialize failed"))
-	}
-
-	newTxid, err := bitcoin.BroadcastTx(hex.EncodeToString(rawtx))
-	if err != nil {
-		logger.Error(err.Error())
-		return nil, pp.MakeErrResWithCode(pp.ErrCode_BroadcastTxFail)
-	}
-
-	success = true
-	if btcTxRlt.ChangeAddr != "" {
-		logger.Debug("change address:%s", btcTxRlt.ChangeAddr)
-		ee.WatchAddress(ct, btcTxRlt.ChangeAddr)
-	}
-
-	resp := pp.WithdrawalRes{
-		Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
-		NewTxid: &newTxid,
-	}
-	return &resp, nil
-}
-
-func skyWithdrawl(nodeAddr string, rp *ReqParams) (*pp.WithdrawalRes, *pp.EmptyRes) {
-	ee := rp.Values["engine"].(engine.Exchange)
-	acnt := rp.Values["account"].(account.Accounter)
-	amt := rp.Values["amt"].(uint64)
-	ct := rp.Values["cointype"].(string)
-	toAddr := rp.Values["toAddr"].(string)
-
-	if err := skycoin.VerifyAmount(amt); err != nil {
-		return nil, pp.MakeErrRes(err)
-	}
-
-	// verify the toAddr
-	if _, err := cipher.DecodeBase58Address(toAddr); err != nil {
-		return nil, pp.MakeErrRes(errors.New("invalid skycoin address"))
-	}
-
-	var success bool
-	var skyTxRlt *SkyTxResult
-	var err error
-	if err := acnt.DecreaseBalance(ct, amt); err != nil {
-		return nil, pp.MakeErrRes(err)
-	}
-	defer func() {
-		if !success {
-			go func() {
-				if skyTxRlt != nil {
-					ee.PutUtxos(skycoin.Type, skyTxRlt.UsingUtxos)
-				}
-				acnt.IncreaseBalance(ct, amt)
-			}()
-		} else {
-			//TODO: handle the saving failure.
-			ee.SaveAccount()
-		}
-	}()
-
-	skyTxRlt, err = createSkyWithdrawTx(ee, amt, toAddr)
-	if err != nil {
-		return nil, pp.MakeErrRes(errors.New("failed to create withdrawal tx"))
-	}
-	rawtx, err := skyTxRlt.Tx.Serialize()
-	if err != nil {
-		return nil, pp.MakeErrRes(errors.New("skycoin tx serialize failed"))
-	}
-
-	newTxid, err := skycoin.BroadcastTx(nodeAddr, hex.EncodeToString(rawtx))
-	if err != nil {
-		logger.Error(err.Error())
-		return nil, pp.MakeErrResWithCode(pp.ErrCode_BroadcastTxFail)
-	}
-
-	success = true
-	if skyTxRlt.ChangeAddr != "" {
-		logger.Debug("change address:%s", skyTxRlt.ChangeAddr)
-		ee.WatchAddress(ct, skyTxRlt.ChangeAddr)
-	}
-
-	resp := pp.WithdrawalRes{
-		Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
-		NewTxid: &newTxid,
-	}
-	return &resp, nil
-}
-
-// createBtcWithdrawTx create withdraw transaction.
-// amount is the number of coins that want to withdraw.
-// toAddr is the address that the coins will be sent to.
-func createBtcWithdrawTx(egn engine.Exchange, amount uint64, toAddr string) (*BtcTxResult, error) {
-	uxs, err := egn.ChooseUtxos(bitcoin.Type, amount+egn.GetBtcFee(), ChooseUtxoTm)
-	if err != nil {
+// createSkyTxInOut creates the tx of skycoin or its forks, the network fee is paid in coin hours,
+// so the withdraw fee stays in the change output. Each output gets 1/skycoin.TxHoursFactor of
+// the input hours.
+func createSkyTxInOut(ee engine.Exchange, ct string, amount uint64, fee uint64, outAddr string) (*txInOutResult, error) {
+	var rlt txInOutResult
+	if err := skycoin.VerifyAmount(amount); err != nil {
 		return nil, err
 	}
-	utxos := uxs.([]bitcoin.Utxo)
 
-	for _, u := range utxos {
-		logger.Debug("using utxos: txid:%s vout:%d addr:%s", u.GetTxid(), u.GetVout(), u.GetAddress())
+	// the change must be whole coins too.
+	if err := skycoin.VerifyAmount(fee); err != nil {
+		return nil, fmt.Errorf("%s withdraw fee: %v", ct, err)
 	}
 
-	var success bool
-	defer func() {
-		if !success {
-			// put utxos back to pool if withdraw failed.
-			go func() { egn.PutUtxos(bitcoin.Type, utxos) }()
-		}
-	}()
-
-	var totalAmounts uint64
-	for _, u := range utxos {
-		totalAmounts += u.GetAmount()
-	}
-	fee := egn.GetBtcFee()
-	outAddrs := []bitcoin.TxOut{}
-	chgAmt := totalAmounts - fee - amount
-	chgAddr := ""
-	if chgAmt > 0 {
-		// generate a change address
-		chgAddr = egn.GetNewAddress(bitcoin.Type)
-		outAddrs = append(outAddrs,
-			bitcoin.TxOut{Addr: toAddr, Value: amount},
-			bitcoin.TxOut{Addr: chgAddr, Value: chgAmt})
-	} else {
-		outAddrs = append(outAddrs, bitcoin.TxOut{Addr: toAddr, Value: amount})
-	}
-	// change utxo to UtxoWithkey
-	utxoKeys, err := makeBtcUtxoWithkeys(utxos, egn)
-	if err != nil {
-		return nil, err
-	}
-
-	logger.Debug("creating transaction...")
-	tx, err := bitcoin.NewTransaction(utxoKeys, outAddrs)
-	if err != nil {
-		logger.Error(err.Error())
-		return nil, err
-	}
-	success = true
-	rlt := BtcTxResult{
-		Tx:         tx,
-		UsingUtxos: utxos[:],
-		ChangeAddr: chgAddr,
+	// verify the outAddr
+	if _, err := cipher.DecodeBase58Address(outAddr); err != nil {
+		return nil, fmt.Errorf("invalid %s address", ct)
 	}
-	return &rlt, nil
-}
 
-func createSkyWithdrawTx(egn engine.Exchange, amount uint64, toAddr string) (*SkyTxResult, error) {
-	uxs, err := egn.ChooseUtxos(skycoin.Type, amount, ChooseUtxoTm)
+	// choose sufficient utxos.
+	utxos, err := ee.ChooseUtxos(ct, amount, ChooseUtxoTm)
 	if err != nil {
 		return nil, err
 	}
-	utxos := uxs.([]skycoin.Utxo)
-
-	for _, u := range utxos {
-		logger.Debug("using skycoin utxos:%s", u.GetHash())
-	}
-
-	var success bool
-	defer func() {
-		if !success {
-			go func() { egn.PutUtxos(skycoin.Type, utxos) }()
-		}
-	}()
 
-	var totalAmounts uint64
-	var totalHours uint64
-	for _, u := range utxos {
+	var totalAmounts, totalHours uint64
+	for _, ux := range utxos {
+		u := ux.(skycoin.Utxo)
+		logger.Debug("using %s utxos:%s", ct, u.GetHash())
+		rlt.TxIns = append(rlt.TxIns, coin.TxIn{Txid: u.GetHash()})
 		totalAmounts += u.GetCoins()
 		totalHours += u.GetHours()
 	}
 
-	outAddrs := []skycoin.TxOut{}
-	chgAmt := totalAmounts - amount
-	chgHours := totalHours / 4
-	chgAddr := ""
-	if chgAmt > 0 {
+	hours := totalHours / skycoin.TxHoursFactor
+	txOuts := []skycoin.TxOut{skycoin.MakeUtxoOutput(outAddr, amount, hours)}
+	if chgAmt := totalAmounts - amount; chgAmt > 0 {
 		// generate a change address
-		chgAddr = egn.GetNewAddress(skycoin.Type)
-		outAddrs = append(outAddrs,
-			skycoin.MakeUtxoOutput(toAddr, amount, chgHours/2),
-			skycoin.MakeUtxoOutput(chgAddr, chgAmt, chgHours/2))
-	} else {
-		outAddrs = append(outAddrs, skycoin.MakeUtxoOutput(toAddr, amount, chgHours/2))
-	}
-
-	keys := make([]cipher.SecKey, len(utxos))
-	for i, u := range utxos {
-		k, err := egn.GetAddrPrivKey(skycoin.Type, u.GetAddress())
+		chgAddr, err := ee.GetNewAddress(ct)
 		if err != nil {
-			panic(err)
+			ee.ReleaseUtxos(ct, utxos)
+			return nil, err
 		}
-		keys[i] = cipher.MustSecKeyFromHex(k)
-	}
-
-	logger.Debug("creating skycoin transaction...")
-	tx := skycoin.NewTransaction(utxos, keys, outAddrs)
-	if err := tx.Verify(); err != nil {
-		return nil, err
+		txOuts = append(txOuts, skycoin.MakeUtxoOutput(chgAddr, chgAmt, hours))
+		rlt.ChangeAddr = chgAddr
 	}
 
-	success = true
-	rlt := SkyTxResult{
-		Tx:         tx,
-		UsingUtxos: utxos[:],
-		ChangeAddr: chgAddr,
-	}
+	rlt.TxOuts = txOuts
+	rlt.Utxos = utxos
+	rlt.Amount = amount
+	rlt.Fee = fee
 	return &rlt, nil
 }
-
-func makeBtcUtxoWithkeys(utxos []bitcoin.Utxo, egn engine.Exchange) ([]bitcoin.UtxoWithkey, error) {
-	utxoks := make([]bitcoin.UtxoWithkey, len(utxos))
-	for i, u := range utxos {
-		key, err := egn.GetAddrPrivKey(bitcoin.Type, u.GetAddress())
-		if err != nil {
-			return []bitcoin.UtxoWithkey{}, err
-		}
-		utxoks[i] = bitcoin.NewUtxoWithKey(u, key)
-	}
-	return utxoks, nil
-}
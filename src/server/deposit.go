@@ -0,0 +1,49 @@
+package server
+
+import (
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+)
+
+// handleDeposits credits the new confirmed outputs of each coin's utxo manager until closing.
+func (serv *ExchangeServer) handleDeposits(c chan bool) {
+	for ct, um := range serv.utxoMgrs {
+		ch := make(chan coin.Utxo, 100)
+		um.RegisterDepositChan(ch)
+		ct := ct
+		serv.goRun(func() {
+			for {
+				select {
+				case <-c:
+					return
+				case u := <-ch:
+					serv.creditDeposit(ct, u.GetID(), u.GetAddress(), u.GetAmount())
+				}
+			}
+		})
+	}
+}
+
+// creditDeposit increases the balance of the account that owns the deposit address.
+// The output is recorded in the journal together with the balance, so outputs
+// that show up again after restart won't be credited twice. Outputs of
+// addresses that don't belong to any account, like the change addresses, are ignored.
+func (serv *ExchangeServer) creditDeposit(ct string, id string, addr string, amt uint64) {
+	a, err := serv.GetAccountByAddress(ct, addr)
+	if err != nil {
+		logger.Debug("%s output:%s of address:%s has no owner", ct, id, addr)
+		return
+	}
+
+	if err := serv.CreditDeposit(a.GetID(), ct, id, amt); err != nil {
+		if err != account.ErrDepositCredited {
+			logger.Error(err.Error())
+		}
+		return
+	}
+
+	logger.Info("account:%s deposit %s:%d, output:%s", a.GetID(), ct, amt, id)
+	if err := serv.SaveAccount(); err != nil {
+		logger.Error(err.Error())
+	}
+}
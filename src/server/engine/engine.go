@@ -1,11 +1,13 @@
 package engine
 
 import (
+	"context"
 	"time"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/skycoin/skycoin-exchange/src/server/account"
 	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
 )
 
 type Exchange interface {
@@ -14,33 +16,56 @@ type Exchange interface {
 	Addresser
 	Order
 	Utxor
+	Subscriber
 }
 
 type Accounter interface {
 	CreateAccountWithPubkey(pubkey string) (account.Accounter, error)
 	GetAccount(id string) (account.Accounter, error)
+	Post(ps ...account.Posting) error
+	AdjustBalance(id string, ct string, amt uint64, ref string) error
 	SaveAccount() error
 	IsAdmin(pubkey string) bool
+	GetFeeRevenue() map[string]uint64
+	GetWithdrawPolicies() map[string]account.WithdrawPolicy
+	HoldWithdrawal(id string, ct string, amt uint64) (uint64, error)
 }
 
 type Addresser interface {
 	WatchAddress(ct, addr string)
-	GetNewAddress(coinType string) string
+	GetNewAddress(coinType string) (string, error)
 	GetAddrPrivKey(ct, addr string) (string, error)
 }
 
 type Order interface {
 	AddOrder(cp string, odr order.Order) (uint64, error)
+	CancelOrder(cp string, id uint64, pubkey string) (order.Order, error)
+	AddConditional(cp string, c order.Conditional) (uint64, error)
+	CancelConditional(cp string, id uint64, pubkey string) (order.Conditional, error)
+	GetConditionals(cp string, pubkey string) ([]order.Conditional, error)
 	GetOrders(cp string, tp order.Type, start, end int64) ([]order.Order, error)
+	GetDepth(cp string, n int) ([]order.PriceLevel, []order.PriceLevel, error)
+	GetTrades(cp string, start, end int64) ([]order.Trade, error)
+	GetTicker(cp string) (order.Ticker, error)
+	GetCandles(cp string, iv string, start, end int64) ([]order.Candle, error)
+	GetAccountTrades(cp string, aid string, start, end int64) ([]order.Trade, error)
+	GetPairs() []order.Pair
 }
 
 type Utxor interface {
-	ChooseUtxos(ct string, amount uint64, tm time.Duration) (interface{}, error)
-	PutUtxos(ct string, utxos interface{})
+	ChooseUtxos(ct string, amount uint64, tm time.Duration) ([]coin.Utxo, error)
+	ReleaseUtxos(ct string, utxos []coin.Utxo)
+	MarkUtxosSpent(ct string, utxos []coin.Utxo)
+}
+
+type Subscriber interface {
+	GetHub() *sknet.Hub
+	ResolveTopics(pubkey string, topics []string) ([]string, error)
 }
 
 type Server interface {
-	Run()
+	Run() error
+	Shutdown(ctx context.Context) error
 	GetSecKey() string
 	GetBtcFee() uint64
 	GetSupportCoins() []string
@@ -1,16 +1,20 @@
 package order
 
 import (
+	"container/list"
+	"math"
 	"sort"
 	"sync"
 )
 
-// order book, which records the bid and ask order list.
+// Book is the order book of one coin pair, the orders are kept in price levels, and the
+// orders of the same price are matched in the order they arrived. The orders are matched
+// when they arrive, so the best bid is always lower than the best ask when the book is at rest.
 type Book struct {
-	bidOrders []Order
-	askOrders []Order
-	bidMtx    sync.Mutex
-	askMtx    sync.Mutex
+	bids   levels                   // bid levels, the best is the highest.
+	asks   levels                   // ask levels, the best is the lowest.
+	orders map[uint64]*list.Element // the orders in book, indexed by id.
+	mtx    sync.RWMutex
 }
 
 type BookJson struct {
@@ -23,218 +27,344 @@ type OrderPair struct {
 	Ask Order
 }
 
+// side returns the levels of the order type, and whether the best level is the highest.
+func (bk *Book) side(tp Type) (*levels, bool, bool) {
+	switch tp {
+	case Bid:
+		return &bk.bids, true, true
+	case Ask:
+		return &bk.asks, false, true
+	default:
+		return nil, false, false
+	}
+}
+
+// best returns the best level of the order type, nil if there's no order.
+func (bk *Book) best(tp Type) *level {
+	if tp == Bid {
+		return bk.bids.max()
+	}
+	return bk.asks.min()
+}
+
+// add appends the order to the queue of its price level.
+func (bk *Book) add(od Order) {
+	ls, _, ok := bk.side(od.Type)
+	if !ok {
+		return
+	}
+
+	if bk.orders == nil {
+		bk.orders = make(map[uint64]*list.Element)
+	}
+	bk.orders[od.ID] = ls.get(od.Price).orders.PushBack(&od)
+	ls.size++
+}
+
+// remove deletes the order of the element from its level, the level is removed once it's empty.
+func (bk *Book) remove(ls *levels, lvl *level, e *list.Element) {
+	od := lvl.orders.Remove(e).(*Order)
+	ls.size--
+	if bk.orders[od.ID] == e {
+		delete(bk.orders, od.ID)
+	}
+
+	if lvl.orders.Len() == 0 {
+		ls.remove(lvl.price)
+	}
+}
+
 func (bk *Book) AddBid(bid Order) {
-	bk.bidMtx.Lock()
-	bk.bidOrders = append(bk.bidOrders, bid)
-	sort.Sort(byPriceThenTimeDesc(bk.bidOrders))
-	bk.bidMtx.Unlock()
+	bk.mtx.Lock()
+	bid.Type = Bid
+	bk.add(bid)
+	bk.mtx.Unlock()
 }
 
 func (bk *Book) AddAsk(ask Order) {
-	bk.askMtx.Lock()
-	bk.askOrders = append(bk.askOrders, ask)
-	sort.Sort(byPriceThenTimeAsc(bk.askOrders))
-	bk.askMtx.Unlock()
+	bk.mtx.Lock()
+	ask.Type = Ask
+	bk.add(ask)
+	bk.mtx.Unlock()
 }
 
 func (bk *Book) Copy() Book {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
 	newBk := Book{}
-	bk.bidMtx.Lock()
-	newBk.bidOrders = make([]Order, len(bk.bidOrders))
-	copy(newBk.bidOrders, bk.bidOrders)
-	bk.bidMtx.Unlock()
-
-	bk.askMtx.Lock()
-	newBk.askOrders = make([]Order, len(bk.askOrders))
-	copy(newBk.askOrders, bk.askOrders)
-	bk.askMtx.Unlock()
+	for _, tp := range []Type{Bid, Ask} {
+		bk.walk(tp, func(od *Order) bool {
+			newBk.add(*od)
+			return true
+		})
+	}
 	return newBk
 }
 
-func (bk *Book) GetOrders(tp Type, start, end int64) []Order {
-	return bk.copyOrders(tp, start, end)
-}
+// walk visits the orders of specific type in priority order until fn returns false.
+func (bk *Book) walk(tp Type, fn func(od *Order) bool) {
+	ls, desc, ok := bk.side(tp)
+	if !ok {
+		return
+	}
 
-// copy orders of specific type from start index to end.
-func (bk *Book) copyOrders(tp Type, start, end int64) []Order {
-	switch tp {
-	case Bid:
-		bk.bidMtx.Lock()
-		defer bk.bidMtx.Unlock()
-		bidLen := int64(len(bk.bidOrders))
-		if end > bidLen {
-			end = bidLen
+	ls.walk(desc, func(lvl *level) bool {
+		for e := lvl.orders.Front(); e != nil; e = e.Next() {
+			if !fn(e.Value.(*Order)) {
+				return false
+			}
 		}
+		return true
+	})
+}
 
-		var l int64 = 0
-		if start < end {
-			l = end - start
+// GetOrders returns the orders of specific type from start index to end, the best order's index is 0.
+func (bk *Book) GetOrders(tp Type, start, end int64) []Order {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
+	orders := []Order{}
+	var i int64
+	bk.walk(tp, func(od *Order) bool {
+		if i >= end {
+			return false
 		}
-		orders := make([]Order, 0, l)
-		for i := start; i < end; i++ {
-			orders = append(orders, bk.bidOrders[i])
-		}
-		return orders
-	case Ask:
-		bk.askMtx.Lock()
-		defer bk.askMtx.Unlock()
-		bidLen := int64(len(bk.askOrders))
-		if end > bidLen {
-			end = bidLen
+		if i >= start {
+			orders = append(orders, *od)
 		}
+		i++
+		return true
+	})
+	return orders
+}
 
-		var l int64 = 0
-		if start < end {
-			l = end - start
-		}
-		orders := make([]Order, 0, l)
-		for i := start; i < end; i++ {
-			orders = append(orders, bk.askOrders[i])
+// Len returns the number of orders of specific type.
+func (bk *Book) Len(tp Type) int {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
+	ls, _, ok := bk.side(tp)
+	if !ok {
+		return 0
+	}
+	return ls.size
+}
+
+// PriceLevel is the total rest amount of the orders at one price.
+type PriceLevel struct {
+	Price  uint64 `json:"price"`
+	Amount uint64 `json:"amount"`
+}
+
+// Depth returns at most n best price levels of specific type, the rest amounts of the
+// orders at the same price are summed up.
+func (bk *Book) Depth(tp Type, n int) []PriceLevel {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
+	depth := []PriceLevel{}
+	ls, desc, ok := bk.side(tp)
+	if !ok || n <= 0 {
+		return depth
+	}
+
+	ls.walk(desc, func(lvl *level) bool {
+		pl := PriceLevel{Price: lvl.price}
+		for e := lvl.orders.Front(); e != nil; e = e.Next() {
+			pl.Amount += e.Value.(*Order).RestAmt
 		}
-		return orders
-	default:
-		return []Order{}
-	}
-}
-
-// func (bk *Book) CopyN(st, ed int64) (Book, error) {
-// 	if st > ed {
-// 		return Book{}, errors.New("be sure the start index is <= end index")
-// 	}
-//
-// 	newBk := Book{}
-// 	bk.bidMtx.Lock()
-// 	bidLen := int64(len(bk.bidOrders))
-// 	if ed >= bidLen {
-// 		newBk.bidOrders = make([]Order, bidLen-st)
-// 		copy(newBk.bidOrders, bk.bidOrders[st:])
-// 	} else {
-// 		newBk.bidOrders = make([]Order, ed-st+1)
-// 		copy(newBk.bidOrders, bk.bidOrders[st:ed+1])
-// 	}
-// 	bk.bidMtx.Unlock()
-//
-// 	bk.askMtx.Lock()
-// 	askLen := int64(len(bk.askOrders))
-// 	if ed >= askLen {
-// 		newBk.askOrders = make([]Order, askLen-st)
-// 		copy(newBk.askOrders, bk.askOrders[st:])
-// 	} else {
-// 		newBk.askOrders = make([]Order, ed-st+1)
-// 		copy(newBk.askOrders, bk.askOrders[st:ed+1])
-// 	}
-// 	bk.askMtx.Unlock()
-// 	return newBk, nil
-// }
-
-func (bk Book) getMaxOrderID() uint64 {
-	// sort the book with priority of order id.
-	orders := append(bk.bidOrders, bk.askOrders...)
-	sort.Sort(byOrderID(orders))
-	return orders[0].ID
-}
-
-// Match check if there're bids and asks are matched,
-// if matched, remove from the order book, and return the orders for
-// further use.
-func (bk *Book) Match() []Order {
-	bk.bidMtx.Lock()
-	bk.askMtx.Lock()
-	if len(bk.bidOrders) == 0 || len(bk.askOrders) == 0 {
-		bk.askMtx.Unlock()
-		bk.bidMtx.Unlock()
-		return []Order{}
-	}
-
-	// the highest buy price < the lowest sell price, no order match.
-	if bk.bidOrders[0].Price < bk.askOrders[0].Price {
-		bk.askMtx.Unlock()
-		bk.bidMtx.Unlock()
-		return []Order{}
-	}
-
-	// var bidIndex, askIndex int = 0, 0
-	var bidOrders []Order
-	var askOrders []Order
-
-	for i, bid := range bk.bidOrders {
-		restAmt, askOrderNum := checkAskOrders(bid, &bk.askOrders)
-		if restAmt == bid.Amount {
-			// no ask was matched
+		depth = append(depth, pl)
+		return len(depth) < n
+	})
+	return depth
+}
+
+// BestPrice returns the best price of specific type, false if there's no order.
+func (bk *Book) BestPrice(tp Type) (uint64, bool) {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
+	lvl := bk.best(tp)
+	if lvl == nil {
+		return 0, false
+	}
+	return lvl.price, true
+}
+
+// Match check if there're bids and asks are matched, the best bid and ask will be
+// matched one by one until their prices are not crossed. fullfilled orders are removed
+// from the order book, partially filled order will stay in the book with its rest amount updated.
+// the executed trades are returned for settlement.
+func (bk *Book) Match() []Trade {
+	bk.mtx.Lock()
+	defer bk.mtx.Unlock()
+
+	trades := []Trade{}
+	for {
+		bl, al := bk.best(Bid), bk.best(Ask)
+		// the highest buy price < the lowest sell price, no order match.
+		if bl == nil || al == nil || bl.price < al.price {
 			break
 		}
 
-		bk.bidOrders[i].RestAmt = restAmt
+		be, ae := bl.orders.Front(), al.orders.Front()
+		bid, ask := be.Value.(*Order), ae.Value.(*Order)
+		amt := bid.RestAmt
+		if ask.RestAmt < amt {
+			amt = ask.RestAmt
+		}
 
-		// append fullfilled ask orders
-		askOrders = append(askOrders, bk.askOrders[:askOrderNum]...)
-		// remove fullfilled ask orders from book.
-		bk.askOrders = bk.askOrders[askOrderNum:]
+		trades = append(trades, newTrade(*bid, *ask, amt))
+		bid.RestAmt -= amt
+		ask.RestAmt -= amt
 
-		if restAmt == 0 {
-			bidOrders = append(bidOrders, bk.bidOrders[i])
-		} else if restAmt > 0 {
-			break
+		// remove fullfilled orders from book.
+		if bid.RestAmt == 0 {
+			bk.remove(&bk.bids, bl, be)
+		}
+
+		if ask.RestAmt == 0 {
+			bk.remove(&bk.asks, al, ae)
 		}
 	}
 
-	bk.bidOrders = bk.bidOrders[len(bidOrders):]
-	bk.askMtx.Unlock()
-	bk.bidMtx.Unlock()
+	return trades
+}
+
+// RemoveOrder removes the order of specific id from the book, the order must be owned by aid.
+// the removed order is returned, and it will never be matched again.
+func (bk *Book) RemoveOrder(id uint64, aid string) (Order, error) {
+	bk.mtx.Lock()
+	defer bk.mtx.Unlock()
 
-	return append(bidOrders, askOrders...)
+	e, ok := bk.orders[id]
+	if !ok {
+		return Order{}, ErrOrderNotExist
+	}
+
+	od := e.Value.(*Order)
+	if od.AccountID != aid {
+		return Order{}, ErrNotOrderOwner
+	}
+
+	ls, _, _ := bk.side(od.Type)
+	bk.remove(ls, ls.find(od.Price), e)
+	return *od, nil
 }
 
-func (bk Book) ToMarshalable() BookJson {
-	bj := BookJson{
-		BidOrders: make([]Order, len(bk.bidOrders)),
-		AskOrders: make([]Order, len(bk.askOrders)),
+// fill decreases the rest amount of the order, the order is removed once it's fullfilled.
+func (bk *Book) fill(tp Type, id uint64, amt uint64) {
+	bk.mtx.Lock()
+	defer bk.mtx.Unlock()
+
+	e, ok := bk.orders[id]
+	if !ok {
+		return
 	}
 
-	copy(bj.BidOrders, bk.bidOrders)
-	copy(bj.AskOrders, bk.askOrders)
-	return bj
+	od := e.Value.(*Order)
+	if od.Type != tp {
+		return
+	}
+
+	od.RestAmt -= amt
+	if od.RestAmt == 0 {
+		ls, _, _ := bk.side(tp)
+		bk.remove(ls, ls.find(od.Price), e)
+	}
 }
 
-func NewBookFromJson(bj BookJson) *Book {
-	bk := &Book{
-		bidOrders: make([]Order, len(bj.BidOrders)),
-		askOrders: make([]Order, len(bj.AskOrders)),
+// getOrder finds the order of specific id in the book.
+func (bk *Book) getOrder(id uint64) (Order, bool) {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
+
+	e, ok := bk.orders[id]
+	if !ok {
+		return Order{}, false
 	}
+	return *e.Value.(*Order), true
+}
 
-	copy(bk.bidOrders, bj.BidOrders)
-	copy(bk.askOrders, bj.AskOrders)
-	return bk
+// crosses checks if the order would be matched with the best order on the other side.
+func (bk *Book) crosses(od Order) bool {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
+	switch od.Type {
+	case Bid:
+		al := bk.best(Ask)
+		return al != nil && al.price <= od.Price
+	case Ask:
+		bl := bk.best(Bid)
+		return bl != nil && bl.price >= od.Price
+	}
+	return false
 }
 
-// checkAskOrders check if there're asks that can match the bid order,
-// return value:
-// 				first: the reset bid amount that not matched.
-// 				second: ask orders number that has been used.
-func checkAskOrders(bid Order, askOrders *[]Order) (uint64, uint64) {
-	if bid.RestAmt == 0 {
-		panic("the bid amount already fullfilled")
+// sweepPrice returns the highest ask price that a market bid of amt needs to be filled,
+// the highest ask price in book is returned if the asks are not enough.
+func (bk *Book) sweepPrice(amt uint64) (uint64, error) {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
+	if bk.asks.size == 0 {
+		return 0, ErrNoLiquidity
 	}
 
-	var askNum uint64
-	for i, ask := range *askOrders {
-		// if ask.RestAmt > 0 {
-		if bid.Price < ask.Price {
-			return bid.RestAmt, askNum
-		}
+	var sum, price uint64
+	bk.walk(Ask, func(od *Order) bool {
+		sum += od.RestAmt
+		price = od.Price
+		return sum < amt
+	})
+	return price, nil
+}
 
-		if bid.RestAmt < ask.RestAmt {
-			(*askOrders)[i].RestAmt -= bid.RestAmt
-			return 0, 0
-		} else if bid.RestAmt == ask.RestAmt {
-			(*askOrders)[i].RestAmt = 0
-			askNum++
-			return 0, askNum
-		} else if bid.RestAmt > ask.RestAmt {
-			bid.RestAmt -= ask.RestAmt
-			(*askOrders)[i].RestAmt = 0
-			askNum++
+// fillable returns the amount of the order that would be filled if it's added to book now,
+// as the book is not crossed, the order can only be matched with the orders on the other
+// side whose prices cross its price.
+func (bk *Book) fillable(od Order) uint64 {
+	bk.mtx.RLock()
+	defer bk.mtx.RUnlock()
+	tp := Ask
+	if od.Type == Ask {
+		tp = Bid
+	}
+
+	var filled uint64
+	bk.walk(tp, func(o *Order) bool {
+		if (od.Type == Bid && o.Price > od.Price) || (od.Type == Ask && o.Price < od.Price) {
+			return false
 		}
+		filled += o.RestAmt
+		return filled < od.RestAmt
+	})
+	if filled > od.RestAmt {
+		return od.RestAmt
+	}
+	return filled
+}
+
+// ToMarshalable returns the orders of the book in priority order.
+func (bk *Book) ToMarshalable() BookJson {
+	return BookJson{
+		BidOrders: bk.GetOrders(Bid, 0, math.MaxInt64),
+		AskOrders: bk.GetOrders(Ask, 0, math.MaxInt64),
+	}
+}
+
+// NewBookFromJson creates book from the orders, the orders of the same price are
+// queued in the order they were created.
+func NewBookFromJson(bj BookJson) *Book {
+	orders := make([]Order, 0, len(bj.BidOrders)+len(bj.AskOrders))
+	for _, od := range bj.BidOrders {
+		od.Type = Bid
+		orders = append(orders, od)
+	}
+	for _, od := range bj.AskOrders {
+		od.Type = Ask
+		orders = append(orders, od)
 	}
-	return bid.RestAmt, askNum
+	sort.SliceStable(orders, func(i, j int) bool { return isEarlier(orders[i], orders[j]) })
+
+	bk := &Book{}
+	for _, od := range orders {
+		bk.add(od)
+	}
+	return bk
 }
@@ -1,7 +1,6 @@
 package order
 
 import (
-	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,15 +32,18 @@ func TestBook(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	if bk.bidOrders[0].Price < bk.bidOrders[1].Price {
+	bids := bk.GetOrders(Bid, 0, 10)
+	asks := bk.GetOrders(Ask, 0, 10)
+	if bids[0].Price < bids[1].Price {
 		t.Fatal("bid price not sorted")
 	}
 
-	if bk.askOrders[0].Price > bk.askOrders[1].Price {
+	if asks[0].Price > asks[1].Price {
 		t.Fatal("ask price not sorted")
 	}
 
-	if bk.askOrders[3].CreatedAt < bk.askOrders[4].CreatedAt {
+	// the orders of the same price are queued in the order they arrived.
+	if asks[3].CreatedAt > asks[4].CreatedAt {
 		t.Fatal("ask create time not sorted")
 	}
 }
@@ -72,12 +74,12 @@ func TestMatch(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	ods := bk.Match()
-	// for _, od := range ods {
-	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
+	trades := bk.Match()
+	// for _, td := range trades {
+	// 	fmt.Printf("maker:%d, taker:%d, price:%d, amount:%d\n", td.MakerID, td.TakerID, td.Price, td.Amount)
 	// }
-	// fmt.Println("len(ods):", len(ods))
-	assert.Equal(t, len(ods), 6)
+	// fmt.Println("len(trades):", len(trades))
+	assert.Equal(t, len(trades), 3)
 }
 
 // none match
@@ -100,8 +102,8 @@ func TestNoneMatch(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	ods := bk.Match()
-	assert.Equal(t, len(ods), 0)
+	trades := bk.Match()
+	assert.Equal(t, len(trades), 0)
 }
 
 // zero bid n asks match.
@@ -131,11 +133,11 @@ func TestMatchZero2N(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	ods := bk.Match()
-	// for _, od := range ods {
-	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
+	trades := bk.Match()
+	// for _, td := range trades {
+	// 	fmt.Printf("maker:%d, taker:%d, price:%d, amount:%d\n", td.MakerID, td.TakerID, td.Price, td.Amount)
 	// }
-	assert.Equal(t, len(ods), 5)
+	assert.Equal(t, len(trades), 5)
 }
 
 // one bid match n asks.
@@ -165,12 +167,12 @@ func TestMatchOne2N(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	ods := bk.Match()
-	// for _, od := range ods {
-	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
+	trades := bk.Match()
+	// for _, td := range trades {
+	// 	fmt.Printf("maker:%d, taker:%d, price:%d, amount:%d\n", td.MakerID, td.TakerID, td.Price, td.Amount)
 	// }
-	// fmt.Println("len(ods):", len(ods))
-	assert.Equal(t, len(ods), 6)
+	// fmt.Println("len(trades):", len(trades))
+	assert.Equal(t, len(trades), 4)
 }
 
 // n bid match one asks.
@@ -200,12 +202,12 @@ func TestMatchN2One(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	ods := bk.Match()
-	// for _, od := range ods {
-	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
+	trades := bk.Match()
+	// for _, td := range trades {
+	// 	fmt.Printf("maker:%d, taker:%d, price:%d, amount:%d\n", td.MakerID, td.TakerID, td.Price, td.Amount)
 	// }
-	// fmt.Println("len(ods):", len(ods))
-	assert.Equal(t, len(ods), 5)
+	// fmt.Println("len(trades):", len(trades))
+	assert.Equal(t, len(trades), 4)
 }
 
 // n bid match n asks.
@@ -236,12 +238,12 @@ func TestMatchN2N(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	ods := bk.Match()
-	// for _, od := range ods {
-	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
+	trades := bk.Match()
+	// for _, td := range trades {
+	// 	fmt.Printf("maker:%d, taker:%d, price:%d, amount:%d\n", td.MakerID, td.TakerID, td.Price, td.Amount)
 	// }
-	// fmt.Println("len(ods):", len(ods))
-	assert.Equal(t, len(ods), 6)
+	// fmt.Println("len(trades):", len(trades))
+	assert.Equal(t, len(trades), 4)
 }
 
 // zero bid and ask
@@ -259,12 +261,12 @@ func TestMatchZero(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	ods := bk.Match()
-	// for _, od := range ods {
-	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
+	trades := bk.Match()
+	// for _, td := range trades {
+	// 	fmt.Printf("maker:%d, taker:%d, price:%d, amount:%d\n", td.MakerID, td.TakerID, td.Price, td.Amount)
 	// }
-	// fmt.Println("len(ods):", len(ods))
-	assert.Equal(t, len(ods), 0)
+	// fmt.Println("len(trades):", len(trades))
+	assert.Equal(t, len(trades), 0)
 }
 
 func TestCopy(t *testing.T) {
@@ -294,13 +296,170 @@ func TestCopy(t *testing.T) {
 	}
 
 	copyBk := bk.Copy()
-	assert.Equal(t, len(bk.bidOrders), len(copyBk.bidOrders))
-	for i := 0; i < len(bk.bidOrders); i++ {
-		assert.NotEqual(t, fmt.Sprintf("%p", &bk.bidOrders[i]), fmt.Sprintf("%p", &copyBk.bidOrders[i]))
+	assert.Equal(t, bk.GetOrders(Bid, 0, 10), copyBk.GetOrders(Bid, 0, 10))
+	assert.Equal(t, bk.GetOrders(Ask, 0, 10), copyBk.GetOrders(Ask, 0, 10))
+
+	// changing the copy doesn't affect the book.
+	assert.Equal(t, 3, len(copyBk.Match()))
+	assert.Equal(t, 2, copyBk.Len(Bid))
+	assert.Equal(t, 5, bk.Len(Bid))
+	assert.Equal(t, 5, bk.Len(Ask))
+}
+
+func TestRemoveOrder(t *testing.T) {
+	var BidOrderList = []Order{
+		Order{ID: 1, AccountID: "a", Type: Bid, Price: 100, CreatedAt: 132424, Amount: 1, RestAmt: 1},
+		Order{ID: 2, AccountID: "b", Type: Bid, Price: 102, CreatedAt: 132425, Amount: 1, RestAmt: 1},
+	}
+
+	var AskOrderList = []Order{
+		Order{ID: 3, AccountID: "a", Type: Ask, Price: 104, CreatedAt: 132424, Amount: 1, RestAmt: 1},
+		Order{ID: 4, AccountID: "b", Type: Ask, Price: 103, CreatedAt: 132425, Amount: 1, RestAmt: 1},
+	}
+
+	bk := Book{}
+	for _, bid := range BidOrderList {
+		bk.AddBid(bid)
+	}
+
+	for _, ask := range AskOrderList {
+		bk.AddAsk(ask)
 	}
 
-	assert.Equal(t, len(bk.askOrders), len(copyBk.askOrders))
-	for i := 0; i < len(bk.askOrders); i++ {
-		assert.NotEqual(t, fmt.Sprintf("%p", &bk.askOrders[i]), fmt.Sprintf("%p", &copyBk.askOrders[i]))
+	// not the owner.
+	_, err := bk.RemoveOrder(1, "b")
+	assert.Equal(t, ErrNotOrderOwner, err)
+	assert.Equal(t, 2, bk.Len(Bid))
+
+	od, err := bk.RemoveOrder(1, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), od.ID)
+	assert.Equal(t, 1, bk.Len(Bid))
+	assert.Equal(t, uint64(2), bk.GetOrders(Bid, 0, 1)[0].ID)
+
+	od, err = bk.RemoveOrder(4, "b")
+	assert.Nil(t, err)
+	assert.Equal(t, Ask, od.Type)
+	assert.Equal(t, 1, bk.Len(Ask))
+	assert.Equal(t, uint64(3), bk.GetOrders(Ask, 0, 1)[0].ID)
+
+	// already removed.
+	_, err = bk.RemoveOrder(4, "b")
+	assert.Equal(t, ErrOrderNotExist, err)
+}
+
+// partially filled order stays in the book, trade is executed at maker's price.
+func TestMatchPartialFill(t *testing.T) {
+	bk := Book{}
+	bk.AddAsk(Order{ID: 1, AccountID: "a", Type: Ask, Price: 100, CreatedAt: 132424, Amount: 5, RestAmt: 5})
+	bk.AddBid(Order{ID: 2, AccountID: "b", Type: Bid, Price: 103, CreatedAt: 132425, Amount: 3, RestAmt: 3})
+
+	trades := bk.Match()
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, Trade{
+		MakerID:      1,
+		TakerID:      2,
+		TakerType:    Bid,
+		BidAccountID: "b",
+		AskAccountID: "a",
+		BidPrice:     103,
+		Price:        100,
+		Amount:       3,
+		CreatedAt:    trades[0].CreatedAt,
+	}, trades[0])
+	assert.Equal(t, uint64(2), trades[0].BidID())
+	assert.Equal(t, uint64(1), trades[0].AskID())
+
+	assert.Equal(t, 0, bk.Len(Bid))
+	assert.Equal(t, 1, bk.Len(Ask))
+	assert.Equal(t, uint64(2), bk.GetOrders(Ask, 0, 1)[0].RestAmt)
+
+	// the resting bid is the maker now.
+	bk.AddBid(Order{ID: 3, AccountID: "b", Type: Bid, Price: 101, CreatedAt: 132426, Amount: 4, RestAmt: 4})
+	bk.AddAsk(Order{ID: 4, AccountID: "c", Type: Ask, Price: 101, CreatedAt: 132427, Amount: 4, RestAmt: 4})
+	trades = bk.Match()
+	assert.Equal(t, 2, len(trades))
+	assert.Equal(t, uint64(1), trades[0].MakerID)
+	assert.Equal(t, uint64(100), trades[0].Price)
+	assert.Equal(t, uint64(2), trades[0].Amount)
+	assert.Equal(t, uint64(3), trades[1].MakerID)
+	assert.Equal(t, Ask, trades[1].TakerType)
+	assert.Equal(t, uint64(101), trades[1].Price)
+	assert.Equal(t, uint64(2), trades[1].Amount)
+
+	assert.Equal(t, 0, bk.Len(Bid))
+	assert.Equal(t, 1, bk.Len(Ask))
+	assert.Equal(t, uint64(4), bk.GetOrders(Ask, 0, 1)[0].ID)
+	assert.Equal(t, uint64(2), bk.GetOrders(Ask, 0, 1)[0].RestAmt)
+}
+
+func TestBookDepth(t *testing.T) {
+	bk := &Book{}
+	for i, p := range []uint64{100, 102, 100, 101, 99} {
+		bk.AddBid(Order{ID: uint64(i + 1), Price: p, Amount: uint64(i + 1), RestAmt: uint64(i + 1)})
+	}
+	bk.AddAsk(Order{ID: 6, Price: 105, Amount: 3, RestAmt: 2})
+	bk.AddAsk(Order{ID: 7, Price: 103, Amount: 1, RestAmt: 1})
+	bk.AddAsk(Order{ID: 8, Price: 105, Amount: 4, RestAmt: 4})
+
+	assert.Equal(t, []PriceLevel{{102, 2}, {101, 4}, {100, 4}}, bk.Depth(Bid, 3))
+	assert.Equal(t, []PriceLevel{{103, 1}, {105, 6}}, bk.Depth(Ask, 10))
+	assert.Equal(t, []PriceLevel{}, bk.Depth(Ask, 0))
+
+	p, ok := bk.BestPrice(Bid)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(102), p)
+	p, ok = bk.BestPrice(Ask)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(103), p)
+	_, ok = (&Book{}).BestPrice(Ask)
+	assert.False(t, ok)
+}
+
+// benchRestingOrders is the number of orders resting in book in the benchmarks.
+const benchRestingOrders = 100000
+
+// newBenchBook creates book with the resting orders, the bids are priced from 1 to 500,
+// and the asks from 1001 to 1500, each order's amount is 1.
+func newBenchBook() *Book {
+	bk := &Book{}
+	for i := 0; i < benchRestingOrders; i++ {
+		od := Order{ID: uint64(i + 1), AccountID: "maker", Amount: 1, RestAmt: 1, CreatedAt: int64(i)}
+		if i%2 == 0 {
+			od.Price = uint64(i/2%500 + 1)
+			bk.AddBid(od)
+		} else {
+			od.Price = uint64(i/2%500 + 1001)
+			bk.AddAsk(od)
+		}
+	}
+	return bk
+}
+
+// BenchmarkBookAddCancel adds and cancels an order that doesn't cross the book.
+func BenchmarkBookAddCancel(b *testing.B) {
+	bk := newBenchBook()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id := uint64(benchRestingOrders + i + 1)
+		bk.AddBid(Order{ID: id, AccountID: "taker", Price: uint64(i%500 + 1), Amount: 1, RestAmt: 1})
+		if _, err := bk.RemoveOrder(id, "taker"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBookMatch matches an incoming bid with the best ask, then adds an ask back,
+// so the book always has the resting orders.
+func BenchmarkBookMatch(b *testing.B) {
+	bk := newBenchBook()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id := uint64(benchRestingOrders + 2*i + 1)
+		bk.AddBid(Order{ID: id, AccountID: "taker", Price: bk.best(Ask).price, Amount: 1, RestAmt: 1})
+		if trades := bk.Match(); len(trades) != 1 {
+			b.Fatalf("expect 1 trade, got %d", len(trades))
+		}
+		bk.AddAsk(Order{ID: id + 1, AccountID: "maker", Price: uint64(i%500 + 1001), Amount: 1, RestAmt: 1})
 	}
 }
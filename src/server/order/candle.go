@@ -0,0 +1,226 @@
+package order
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+)
+
+// CandleIntervals are the supported candle intervals.
+var CandleIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+var candleTradeBkt = "candle_trades" // coin pair -> id of the last trade aggregated into the candles.
+
+// Candle is the OHLCV summary of the trades executed in one interval.
+type Candle struct {
+	Time   int64  `json:"time"` // open time of the interval, unix seconds.
+	Open   uint64 `json:"open"`
+	High   uint64 `json:"high"`
+	Low    uint64 `json:"low"`
+	Close  uint64 `json:"close"`
+	Volume uint64 `json:"volume"` // traded amount of the main coin.
+}
+
+// candleSeries is the candles of one interval ordered by time.
+type candleSeries struct {
+	span    int64 // seconds of the interval.
+	candles []Candle
+	dirty   map[int64]bool // open time of the candles changed since the last flush.
+}
+
+func newCandleSeries(span time.Duration) *candleSeries {
+	return &candleSeries{
+		span:  int64(span / time.Second),
+		dirty: make(map[int64]bool),
+	}
+}
+
+// add aggregates the trade into the candle of its interval.
+func (cs *candleSeries) add(t Trade) {
+	tm := t.CreatedAt - t.CreatedAt%cs.span
+	cs.dirty[tm] = true
+
+	// the trades arrive in time order mostly, so the candle is the last one.
+	i := len(cs.candles)
+	if i == 0 || cs.candles[i-1].Time < tm {
+		cs.candles = append(cs.candles, Candle{Time: tm, Open: t.Price, High: t.Price, Low: t.Price, Close: t.Price, Volume: t.Amount})
+		return
+	}
+
+	i = sort.Search(len(cs.candles), func(i int) bool { return cs.candles[i].Time >= tm })
+	if cs.candles[i].Time != tm {
+		cs.candles = append(cs.candles, Candle{})
+		copy(cs.candles[i+1:], cs.candles[i:])
+		cs.candles[i] = Candle{Time: tm, Open: t.Price, High: t.Price, Low: t.Price, Close: t.Price, Volume: t.Amount}
+		return
+	}
+
+	c := &cs.candles[i]
+	if t.Price > c.High {
+		c.High = t.Price
+	}
+	if t.Price < c.Low {
+		c.Low = t.Price
+	}
+	c.Close = t.Price
+	c.Volume += t.Amount
+}
+
+// get returns the candles whose open time is in [start, end).
+func (cs *candleSeries) get(start, end int64) []Candle {
+	i := sort.Search(len(cs.candles), func(i int) bool { return cs.candles[i].Time >= start })
+	j := sort.Search(len(cs.candles), func(i int) bool { return cs.candles[i].Time >= end })
+	candles := []Candle{}
+	if i < j {
+		candles = append(candles, cs.candles[i:j]...)
+	}
+	return candles
+}
+
+// candleBook aggregates the executed trades of one coin pair into the candles of each interval.
+type candleBook struct {
+	series  map[string]*candleSeries
+	tradeID uint64 // id of the last aggregated trade.
+}
+
+func newCandleBook() *candleBook {
+	cb := &candleBook{series: make(map[string]*candleSeries, len(CandleIntervals))}
+	for iv, span := range CandleIntervals {
+		cb.series[iv] = newCandleSeries(span)
+	}
+	return cb
+}
+
+// candleBkt returns the bucket name of the candles of specific coin pair and interval.
+func candleBkt(cp, iv string) string {
+	return "candles:" + cp + ":" + iv
+}
+
+// add aggregates the trades, the trades that were aggregated already are ignored.
+func (cb *candleBook) add(trades ...Trade) {
+	for _, t := range trades {
+		if t.ID <= cb.tradeID {
+			continue
+		}
+		for _, cs := range cb.series {
+			cs.add(t)
+		}
+		cb.tradeID = t.ID
+	}
+}
+
+// reset removes all the candles, the removed candles will be deleted from store in next flush.
+func (cb *candleBook) reset() {
+	for iv, cs := range cb.series {
+		ns := newCandleSeries(CandleIntervals[iv])
+		for _, c := range cs.candles {
+			ns.dirty[c.Time] = true
+		}
+		for tm := range cs.dirty {
+			ns.dirty[tm] = true
+		}
+		cb.series[iv] = ns
+	}
+	cb.tradeID = 0
+}
+
+// flush writes the changed candles, and the id of the last aggregated trade.
+func (cb *candleBook) flush(tx storage.Tx, cp string) error {
+	for iv, cs := range cb.series {
+		for tm := range cs.dirty {
+			k := storage.Itob(uint64(tm))
+			cds := cs.get(tm, tm+1)
+			if len(cds) == 0 {
+				if err := tx.Delete(candleBkt(cp, iv), k); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := storage.PutJSON(tx, candleBkt(cp, iv), k, cds[0]); err != nil {
+				return err
+			}
+		}
+		cs.dirty = make(map[int64]bool)
+	}
+	return tx.Put(candleTradeBkt, []byte(cp), storage.Itob(cb.tradeID))
+}
+
+// loadCandleBook loads the candles of specific coin pair from store.
+func loadCandleBook(tx storage.Tx, cp string) (*candleBook, error) {
+	cb := newCandleBook()
+	if v := tx.Get(candleTradeBkt, []byte(cp)); v != nil {
+		cb.tradeID = storage.Btoi(v)
+	}
+
+	for iv, cs := range cb.series {
+		err := tx.ForEach(candleBkt(cp, iv), func(k, v []byte) error {
+			c := Candle{}
+			if err := json.Unmarshal(v, &c); err != nil {
+				return err
+			}
+			cs.candles = append(cs.candles, c)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cb, nil
+}
+
+// aggregate adds the trades to the candles of specific coin pair.
+func (m *Manager) aggregate(cp string, trades []Trade) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.candles[cp].add(trades...)
+}
+
+// catchUpCandles aggregates the trades in history that are not in the candles yet, like the
+// trades executed by old version.
+func (m *Manager) catchUpCandles(cp string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	cb := m.candles[cp]
+	cb.add(m.histories[cp].tradesAfter(cb.tradeID)...)
+}
+
+// RebuildCandles rebuilds the candles of specific coin pair from the trade history, it must be
+// called before Start, the rebuilt candles are written into store in next Flush.
+func (m *Manager) RebuildCandles(cp string) error {
+	m.mtx.Lock()
+	cb, ok := m.candles[cp]
+	if !ok {
+		m.mtx.Unlock()
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	cb.reset()
+	m.mtx.Unlock()
+
+	m.catchUpCandles(cp)
+	return nil
+}
+
+// GetCandles returns the candles of specific coin pair and interval, whose open time is in [start, end).
+func (m *Manager) GetCandles(cp string, iv string, start, end int64) ([]Candle, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	cb, ok := m.candles[cp]
+	if !ok {
+		return []Candle{}, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+
+	cs, ok := cb.series[iv]
+	if !ok {
+		return []Candle{}, fmt.Errorf("unknow candle interval:%s", iv)
+	}
+	return cs.get(start, end), nil
+}
@@ -0,0 +1,107 @@
+package order
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandleBook(t *testing.T) {
+	cb := newCandleBook()
+	cb.add(
+		Trade{ID: 1, Price: 100, Amount: 1, CreatedAt: 60},
+		Trade{ID: 2, Price: 105, Amount: 2, CreatedAt: 90},
+		Trade{ID: 3, Price: 98, Amount: 1, CreatedAt: 119},
+		Trade{ID: 4, Price: 101, Amount: 3, CreatedAt: 400},
+		// the trade of earlier time is aggregated into its own candle.
+		Trade{ID: 5, Price: 99, Amount: 1, CreatedAt: 200},
+		// the aggregated trade is ignored.
+		Trade{ID: 3, Price: 98, Amount: 1, CreatedAt: 119},
+	)
+	assert.Equal(t, uint64(5), cb.tradeID)
+
+	assert.Equal(t, []Candle{
+		{Time: 60, Open: 100, High: 105, Low: 98, Close: 98, Volume: 4},
+		{Time: 180, Open: 99, High: 99, Low: 99, Close: 99, Volume: 1},
+		{Time: 360, Open: 101, High: 101, Low: 101, Close: 101, Volume: 3},
+	}, cb.series["1m"].get(0, 1000))
+
+	assert.Equal(t, []Candle{
+		{Time: 0, Open: 100, High: 105, Low: 98, Close: 99, Volume: 5},
+		{Time: 300, Open: 101, High: 101, Low: 101, Close: 101, Volume: 3},
+	}, cb.series["5m"].get(0, 1000))
+
+	assert.Equal(t, []Candle{{Time: 180, Open: 99, High: 99, Low: 99, Close: 99, Volume: 1}}, cb.series["1m"].get(120, 360))
+	assert.Equal(t, []Candle{}, cb.series["1h"].get(10, 100))
+}
+
+func TestManagerCandles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	cp := "btc/sky"
+	s := storage.NewMemStore()
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, &Book{}))
+	closing := make(chan bool)
+	go m.Start(closing)
+	for _, od := range []Order{
+		{Type: Ask, Price: 100, Amount: 2, RestAmt: 2},
+		{Type: Ask, Price: 105, Amount: 2, RestAmt: 2},
+		{Type: Bid, Price: 105, Amount: 3, RestAmt: 3},
+		{Type: Bid, Price: 105, Amount: 2, RestAmt: 2},
+	} {
+		_, err := m.Place(cp, od)
+		assert.Nil(t, err)
+	}
+	close(closing)
+	assert.Nil(t, s.Update(m.Flush))
+
+	candles, err := m.GetCandles(cp, "1d", 0, 1<<62)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(candles))
+	c := candles[0]
+	assert.Equal(t, uint64(100), c.Open)
+	assert.Equal(t, uint64(105), c.High)
+	assert.Equal(t, uint64(100), c.Low)
+	assert.Equal(t, uint64(105), c.Close)
+	assert.Equal(t, uint64(4), c.Volume)
+
+	_, err = m.GetCandles(cp, "3m", 0, 1<<62)
+	assert.NotNil(t, err)
+	_, err = m.GetCandles("btc/unknown", "1m", 0, 1<<62)
+	assert.NotNil(t, err)
+
+	// the candles are stored.
+	m1, err := LoadManager(s, nil)
+	assert.Nil(t, err)
+	for iv := range CandleIntervals {
+		c0, err := m.GetCandles(cp, iv, 0, 1<<62)
+		assert.Nil(t, err)
+		c1, err := m1.GetCandles(cp, iv, 0, 1<<62)
+		assert.Nil(t, err)
+		assert.Equal(t, c0, c1)
+	}
+
+	// the candles are rebuilt from trade history.
+	assert.Nil(t, m1.RebuildCandles(cp))
+	assert.Nil(t, s.Update(m1.Flush))
+	m2 := NewManager()
+	assert.Nil(t, m2.AddBook(cp, &Book{}))
+	for iv := range CandleIntervals {
+		c0, err := m.GetCandles(cp, iv, 0, 1<<62)
+		assert.Nil(t, err)
+		c1, err := m1.GetCandles(cp, iv, 0, 1<<62)
+		assert.Nil(t, err)
+		c2, err := m2.GetCandles(cp, iv, 0, 1<<62)
+		assert.Nil(t, err)
+		assert.Equal(t, c0, c1)
+		assert.Equal(t, c0, c2)
+	}
+	assert.NotNil(t, m1.RebuildCandles("btc/unknown"))
+}
@@ -0,0 +1,177 @@
+package order
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+)
+
+// Trigger decides in which direction the last trade price must move to trigger the conditional order.
+type Trigger uint8
+
+const (
+	StopLoss   Trigger = iota // ask is triggered when the price falls to the trigger price, bid when it rises to it.
+	TakeProfit                // ask is triggered when the price rises to the trigger price, bid when it falls to it.
+)
+
+var (
+	// ErrWouldTrigger is returned when the conditional order would be triggered by the current last price.
+	ErrWouldTrigger = errors.New("conditional order would be triggered immediately")
+)
+
+// Conditional is the order that rests outside the book, it's placed into the book when the last
+// trade price of the pair crosses the trigger price. Its escrow is locked when it's created.
+type Conditional struct {
+	Order
+	Trigger      Trigger `json:"trigger"`
+	TriggerPrice uint64  `json:"trigger_price"`
+}
+
+// Check checks the order and the trigger, the conditional order can't be post-only, and
+// the market bid is not supported, as its escrow can't be decided before it's triggered.
+func (c Conditional) Check() error {
+	if err := c.Order.Check(); err != nil {
+		return err
+	}
+
+	if c.Trigger != StopLoss && c.Trigger != TakeProfit {
+		return errors.New("unknow trigger")
+	}
+
+	if c.TriggerPrice == 0 {
+		return errors.New("trigger price must be greater than 0")
+	}
+
+	if c.PostOnly {
+		return errors.New("conditional order can't be post-only")
+	}
+
+	if c.Kind == Market && c.Type == Bid {
+		return errors.New("conditional market bid is not supported, use limit bid instead")
+	}
+	return nil
+}
+
+// Triggered checks if the order is triggered by the last trade price.
+func (c Conditional) Triggered(price uint64) bool {
+	// stop-loss ask and take-profit bid wait for the price to fall.
+	if (c.Trigger == StopLoss) == (c.Type == Ask) {
+		return price <= c.TriggerPrice
+	}
+	return price >= c.TriggerPrice
+}
+
+func (tg Trigger) String() string {
+	switch tg {
+	case StopLoss:
+		return "stop_loss"
+	case TakeProfit:
+		return "take_profit"
+	default:
+		return ""
+	}
+}
+
+// TriggerFromStr parses the trigger.
+func TriggerFromStr(tg string) (Trigger, error) {
+	switch tg {
+	case "stop_loss":
+		return StopLoss, nil
+	case "take_profit":
+		return TakeProfit, nil
+	default:
+		return 0, fmt.Errorf("unknow trigger:%s", tg)
+	}
+}
+
+// condBook keeps the conditional orders of one coin pair, the orders are not visible
+// in the book until triggered.
+type condBook struct {
+	orders map[uint64]Conditional
+	dirty  map[uint64]bool // ids of the orders changed since the last flush.
+}
+
+func newCondBook() *condBook {
+	return &condBook{
+		orders: make(map[uint64]Conditional),
+		dirty:  make(map[uint64]bool),
+	}
+}
+
+// condBkt returns the bucket name of the conditional orders of specific coin pair.
+func condBkt(cp string) string {
+	return "conds:" + cp
+}
+
+func (cb *condBook) add(c Conditional) {
+	cb.orders[c.ID] = c
+	cb.dirty[c.ID] = true
+}
+
+func (cb *condBook) remove(id uint64) {
+	delete(cb.orders, id)
+	cb.dirty[id] = true
+}
+
+// list returns the conditional orders of the account ordered by id, all orders are returned if aid is empty.
+func (cb *condBook) list(aid string) []Conditional {
+	cs := []Conditional{}
+	for _, c := range cb.orders {
+		if aid == "" || c.AccountID == aid {
+			cs = append(cs, c)
+		}
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].ID < cs[j].ID })
+	return cs
+}
+
+// triggered returns the orders triggered by the price ordered by id.
+func (cb *condBook) triggered(price uint64) []Conditional {
+	cs := []Conditional{}
+	for _, c := range cb.orders {
+		if c.Triggered(price) {
+			cs = append(cs, c)
+		}
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].ID < cs[j].ID })
+	return cs
+}
+
+// flush writes the changed orders, the removed orders are deleted.
+func (cb *condBook) flush(tx storage.Tx, cp string) error {
+	for id := range cb.dirty {
+		c, ok := cb.orders[id]
+		if !ok {
+			if err := tx.Delete(condBkt(cp), storage.Itob(id)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := storage.PutJSON(tx, condBkt(cp), storage.Itob(id), c); err != nil {
+			return err
+		}
+	}
+	cb.dirty = make(map[uint64]bool)
+	return nil
+}
+
+// loadCondBook loads the conditional orders of specific coin pair from store.
+func loadCondBook(tx storage.Tx, cp string) (*condBook, error) {
+	cb := newCondBook()
+	err := tx.ForEach(condBkt(cp), func(k, v []byte) error {
+		c := Conditional{}
+		if err := json.Unmarshal(v, &c); err != nil {
+			return err
+		}
+		cb.orders[c.ID] = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cb, nil
+}
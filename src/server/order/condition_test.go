@@ -0,0 +1,184 @@
+package order
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalTriggered(t *testing.T) {
+	testData := []struct {
+		trigger   Trigger
+		tp        Type
+		price     uint64
+		triggered bool
+	}{
+		{StopLoss, Ask, 96, false},
+		{StopLoss, Ask, 95, true},
+		{StopLoss, Ask, 90, true},
+		{StopLoss, Bid, 94, false},
+		{StopLoss, Bid, 95, true},
+		{TakeProfit, Ask, 94, false},
+		{TakeProfit, Ask, 100, true},
+		{TakeProfit, Bid, 96, false},
+		{TakeProfit, Bid, 95, true},
+	}
+
+	for _, d := range testData {
+		c := Conditional{Order: Order{Type: d.tp}, Trigger: d.trigger, TriggerPrice: 95}
+		assert.Equal(t, d.triggered, c.Triggered(d.price), "%s %s at %d", d.trigger, d.tp, d.price)
+	}
+}
+
+func TestConditionalCheck(t *testing.T) {
+	testData := []struct {
+		c  Conditional
+		ok bool
+	}{
+		{Conditional{Order: Order{Type: Ask, Price: 90}, Trigger: StopLoss, TriggerPrice: 95}, true},
+		{Conditional{Order: Order{Type: Ask, Kind: Market}, Trigger: StopLoss, TriggerPrice: 95}, true},
+		{Conditional{Order: Order{Type: Bid, Price: 110, TimeInForce: IOC}, Trigger: StopLoss, TriggerPrice: 105}, true},
+		{Conditional{Order: Order{Type: Ask, Price: 90}, Trigger: StopLoss}, false},
+		{Conditional{Order: Order{Type: Ask, Price: 90}, Trigger: Trigger(2), TriggerPrice: 95}, false},
+		{Conditional{Order: Order{Type: Ask, Price: 90, PostOnly: true}, Trigger: StopLoss, TriggerPrice: 95}, false},
+		{Conditional{Order: Order{Type: Bid, Kind: Market}, Trigger: StopLoss, TriggerPrice: 105}, false},
+	}
+
+	for i, d := range testData {
+		assert.Equal(t, d.ok, d.c.Check() == nil, "case %d", i)
+	}
+}
+
+func TestConditionalOrders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	cp := "btc/sky"
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, &Book{}))
+	st := &testSettler{}
+	m.RegisterSettler(st)
+	closing := make(chan bool)
+	defer close(closing)
+	go m.Start(closing)
+
+	// the last price is 100.
+	_, err = m.Place(cp, Order{AccountID: "b", Type: Bid, Price: 100, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+	_, err = m.Place(cp, Order{AccountID: "a", Type: Ask, Price: 100, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+
+	stop, err := m.PlaceConditional(cp, Conditional{
+		Order:        Order{AccountID: "s", Type: Ask, Price: 90, Amount: 2, RestAmt: 2},
+		Trigger:      StopLoss,
+		TriggerPrice: 95,
+	})
+	assert.Nil(t, err)
+	profit, err := m.PlaceConditional(cp, Conditional{
+		Order:        Order{AccountID: "s", Type: Ask, Price: 110, Amount: 1, RestAmt: 1},
+		Trigger:      TakeProfit,
+		TriggerPrice: 110,
+	})
+	assert.Nil(t, err)
+	_, err = m.PlaceConditional(cp, Conditional{
+		Order:        Order{AccountID: "s", Type: Ask, Price: 90, Amount: 1, RestAmt: 1},
+		Trigger:      StopLoss,
+		TriggerPrice: 105,
+	})
+	assert.Equal(t, ErrWouldTrigger, err)
+
+	// the escrow is locked when placed.
+	assert.Equal(t, 4, len(st.locks))
+	assert.Equal(t, stop.ID, st.locks[2].ID)
+	assert.Equal(t, uint64(2), st.locks[2].RestEscrow())
+
+	cs, err := m.GetConditionals(cp, "s")
+	assert.Nil(t, err)
+	assert.Equal(t, []Conditional{stop, profit}, cs)
+	asks, err := m.GetOrders(cp, Ask, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(asks))
+
+	// the trade at 95 triggers the stop-loss ask, which takes the rest of the bid.
+	_, err = m.Place(cp, Order{AccountID: "b", Type: Bid, Price: 95, Amount: 3, RestAmt: 3})
+	assert.Nil(t, err)
+	_, err = m.Place(cp, Order{AccountID: "a", Type: Ask, Price: 95, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(st.trades))
+	t2 := st.trades[2]
+	assert.Equal(t, Ask, t2.TakerType)
+	assert.Equal(t, "s", t2.AskAccountID)
+	assert.Equal(t, uint64(95), t2.Price)
+	assert.Equal(t, uint64(2), t2.Amount)
+	assert.True(t, t2.TakerID > stop.ID)
+	bids, err := m.GetOrders(cp, Bid, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(bids))
+	assert.Equal(t, 0, len(st.unlocks))
+
+	cs, err = m.GetConditionals(cp, "s")
+	assert.Nil(t, err)
+	assert.Equal(t, []Conditional{profit}, cs)
+
+	// only the owner can cancel the order, the escrow is given back.
+	_, err = m.CancelConditional(cp, profit.ID, "a")
+	assert.Equal(t, ErrNotOrderOwner, err)
+	_, err = m.CancelConditional(cp, stop.ID, "s")
+	assert.Equal(t, ErrOrderNotExist, err)
+	c, err := m.CancelConditional(cp, profit.ID, "s")
+	assert.Nil(t, err)
+	assert.Equal(t, profit, c)
+	assert.Equal(t, []Order{profit.Order}, st.unlocks)
+
+	cs, err = m.GetConditionals(cp, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(cs))
+}
+
+func TestLoadConditionalOrders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	cp := "btc/sky"
+	s := storage.NewMemStore()
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, &Book{}))
+	closing := make(chan bool)
+	go m.Start(closing)
+	var ids []uint64
+	for _, tp := range []Type{Bid, Ask} {
+		c, err := m.PlaceConditional(cp, Conditional{
+			Order:        Order{AccountID: "s", Type: tp, Price: 100, Amount: 1, RestAmt: 1},
+			Trigger:      StopLoss,
+			TriggerPrice: 100,
+		})
+		assert.Nil(t, err)
+		ids = append(ids, c.ID)
+	}
+	_, err = m.CancelConditional(cp, ids[0], "s")
+	assert.Nil(t, err)
+	close(closing)
+	assert.Nil(t, s.Update(m.Flush))
+
+	m1, err := LoadManager(s, nil)
+	assert.Nil(t, err)
+	cs0, err := m.GetConditionals(cp, "")
+	assert.Nil(t, err)
+	cs1, err := m1.GetConditionals(cp, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cs1))
+	assert.Equal(t, cs0, cs1)
+	assert.Equal(t, ids[1], cs1[0].ID)
+
+	// the order ids are not reused.
+	id, err := m1.NewOrderID(cp)
+	assert.Nil(t, err)
+	assert.Equal(t, ids[1]+1, id)
+}
@@ -0,0 +1,100 @@
+package order
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// FeeBase is the denominator of the fee rates, the rate is in basis points, 1 means 0.01%.
+	FeeBase uint64 = 10000
+
+	// FeeVolumePeriod is the period that the account's traded volume is summed over for the fee tiers.
+	FeeVolumePeriod = 30 * 24 * time.Hour
+)
+
+// FeeTier is the fee rates of the accounts whose traded volume reaches the tier.
+type FeeTier struct {
+	Volume   uint64 `json:"volume"`    // minimum traded amount of the main coin in FeeVolumePeriod.
+	MakerFee uint64 `json:"maker_fee"` // basis points.
+	TakerFee uint64 `json:"taker_fee"` // basis points.
+}
+
+// FeeSchedule is the trading fee rates of one coin pair. The fee is deducted from the coins
+// the account receives, the bidder pays in main coin, and the asker pays in sub coin.
+type FeeSchedule struct {
+	MakerFee uint64    `json:"maker_fee"` // basis points of the maker, used when no tier is reached.
+	TakerFee uint64    `json:"taker_fee"` // basis points of the taker, used when no tier is reached.
+	FeeTiers []FeeTier `json:"fee_tiers"` // ordered by volume.
+}
+
+// Validate checks the rates don't exceed FeeBase, and the tiers are ordered by volume.
+func (fs FeeSchedule) Validate() error {
+	if fs.MakerFee > FeeBase || fs.TakerFee > FeeBase {
+		return fmt.Errorf("fee rate must not be greater than %d", FeeBase)
+	}
+
+	for i, t := range fs.FeeTiers {
+		if t.MakerFee > FeeBase || t.TakerFee > FeeBase {
+			return fmt.Errorf("fee rate must not be greater than %d", FeeBase)
+		}
+
+		if i > 0 && t.Volume <= fs.FeeTiers[i-1].Volume {
+			return fmt.Errorf("fee tiers must be ordered by volume")
+		}
+	}
+	return nil
+}
+
+// Rates returns the maker and taker rates of the account with specific traded volume.
+func (fs FeeSchedule) Rates(volume uint64) (uint64, uint64) {
+	maker, taker := fs.MakerFee, fs.TakerFee
+	for _, t := range fs.FeeTiers {
+		if volume < t.Volume {
+			break
+		}
+		maker, taker = t.MakerFee, t.TakerFee
+	}
+	return maker, taker
+}
+
+// Fee returns the fee of the amount at the rate, rounded down.
+func Fee(amt uint64, rate uint64) uint64 {
+	// split the amount to avoid overflow.
+	return amt/FeeBase*rate + amt%FeeBase*rate/FeeBase
+}
+
+// SetFeeSchedule sets the fee rates of specific coin pair, it must be called before Start.
+func (m *Manager) SetFeeSchedule(cp string, fs FeeSchedule) {
+	m.fees[cp] = fs
+}
+
+// chargeFees sets the fees of the trades by the fee schedule of the coin pair,
+// the fees are recorded with the trades, and deducted when they're settled.
+func (m *Manager) chargeFees(cp string, trades []Trade) {
+	fs, ok := m.fees[cp]
+	if !ok {
+		return
+	}
+
+	since := time.Now().Add(-FeeVolumePeriod).Unix()
+	rates := func(aid string) (uint64, uint64) {
+		if len(fs.FeeTiers) == 0 {
+			return fs.Rates(0)
+		}
+		return fs.Rates(m.histories[cp].volume(aid, since))
+	}
+
+	for i := range trades {
+		t := &trades[i]
+		bidMaker, bidTaker := rates(t.BidAccountID)
+		askMaker, askTaker := rates(t.AskAccountID)
+		bidRate, askRate := bidMaker, askTaker
+		if t.TakerType == Bid {
+			bidRate, askRate = bidTaker, askMaker
+		}
+
+		t.BidFee = Fee(t.Amount, bidRate)
+		t.AskFee = Fee(t.Price*t.Amount, askRate)
+	}
+}
@@ -0,0 +1,103 @@
+package order
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeSchedule(t *testing.T) {
+	fs := FeeSchedule{
+		MakerFee: 10,
+		TakerFee: 20,
+		FeeTiers: []FeeTier{
+			{Volume: 100, MakerFee: 5, TakerFee: 15},
+			{Volume: 1000, MakerFee: 0, TakerFee: 10},
+		},
+	}
+	assert.Nil(t, fs.Validate())
+
+	for _, d := range []struct {
+		volume       uint64
+		maker, taker uint64
+	}{
+		{0, 10, 20},
+		{99, 10, 20},
+		{100, 5, 15},
+		{999, 5, 15},
+		{5000, 0, 10},
+	} {
+		maker, taker := fs.Rates(d.volume)
+		assert.Equal(t, d.maker, maker, "volume %d", d.volume)
+		assert.Equal(t, d.taker, taker, "volume %d", d.volume)
+	}
+
+	assert.NotNil(t, FeeSchedule{TakerFee: FeeBase + 1}.Validate())
+	assert.NotNil(t, FeeSchedule{FeeTiers: []FeeTier{{Volume: 10}, {Volume: 10}}}.Validate())
+	assert.NotNil(t, FeeSchedule{FeeTiers: []FeeTier{{Volume: 10, MakerFee: FeeBase + 1}}}.Validate())
+
+	assert.Equal(t, uint64(0), Fee(99, 100))
+	assert.Equal(t, uint64(1), Fee(100, 100))
+	assert.Equal(t, uint64(123456), Fee(1234567, 1000))
+	assert.Equal(t, uint64(math.MaxUint64/FeeBase*20+math.MaxUint64%FeeBase*20/FeeBase), Fee(math.MaxUint64, 20))
+	assert.Equal(t, uint64(math.MaxUint64), Fee(math.MaxUint64, FeeBase))
+}
+
+func TestManagerFees(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	m := NewManager()
+	cp := "btc/sky"
+	assert.Nil(t, m.AddBook(cp, &Book{}))
+	m.SetFeeSchedule(cp, FeeSchedule{
+		MakerFee: 10,
+		TakerFee: 20,
+		FeeTiers: []FeeTier{{Volume: 700, MakerFee: 0, TakerFee: 10}},
+	})
+	st := &testSettler{}
+	m.RegisterSettler(st)
+	closing := make(chan bool)
+	defer close(closing)
+	go m.Start(closing)
+
+	// the volume of the trade executed before the fee period is not counted.
+	for _, tr := range []Trade{
+		{BidAccountID: "whale", AskAccountID: "other", Price: 1, Amount: 1000, CreatedAt: time.Now().Add(-FeeVolumePeriod - time.Hour).Unix()},
+		{BidAccountID: "other", AskAccountID: "whale", Price: 1, Amount: 400, CreatedAt: time.Now().Unix()},
+	} {
+		tr := tr
+		assert.Nil(t, m.histories[cp].Append(&tr))
+		m.logs[cp].tradeID = tr.ID
+	}
+
+	place := func(aid string, tp Type, price, amt uint64) {
+		_, err := m.Place(cp, Order{AccountID: aid, Type: tp, Price: price, Amount: amt, RestAmt: amt})
+		assert.Nil(t, err)
+	}
+
+	// whale makes the bid, pays the maker fee in btc, while the taker pays in sky.
+	place("whale", Bid, 100, 1000)
+	place("small", Ask, 100, 600)
+	assert.Equal(t, 1, len(st.trades))
+	assert.Equal(t, uint64(600*10/FeeBase), st.trades[0].BidFee)
+	assert.Equal(t, uint64(100*600*20/FeeBase), st.trades[0].AskFee)
+
+	// whale reaches the tier by the last trade, while small doesn't.
+	place("small", Ask, 100, 400)
+	assert.Equal(t, 2, len(st.trades))
+	assert.Equal(t, uint64(0), st.trades[1].BidFee)
+	assert.Equal(t, uint64(100*400*20/FeeBase), st.trades[1].AskFee)
+
+	// the fees are recorded in history.
+	trades, err := m.GetTrades(cp, 0, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, st.trades[1].AskFee, trades[0].AskFee)
+	assert.Equal(t, st.trades[0].BidFee, trades[1].BidFee)
+}
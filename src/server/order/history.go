@@ -0,0 +1,212 @@
+package order
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+var (
+	tradeDir = filepath.Join(file.UserHome(), ".skycoin-exchange/trade")
+	tradeExt = "trd"
+)
+
+// TradeHistory is the durable trade ledger of one coin pair, every executed trade
+// will be appended to the end of the history file as one json line.
+type TradeHistory struct {
+	path     string
+	trades   []Trade
+	accounts map[string][]int // account id -> indexes of the account's trades.
+	mtx      sync.RWMutex
+}
+
+// InitTradeDir init the trade history dir.
+func InitTradeDir(path string) {
+	if path == "" {
+		path = tradeDir
+	} else {
+		tradeDir = path
+	}
+	// create the trade dir if not exist.
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// LoadTradeHistory loads the trade history of specific coin pair from local disk,
+// empty history will be returned if the history file does not exist.
+func LoadTradeHistory(cp string) (*TradeHistory, error) {
+	name := strings.Replace(cp, "/", "_", 1)
+	th := &TradeHistory{
+		path:     filepath.Join(tradeDir, name+"."+tradeExt),
+		accounts: make(map[string][]int),
+	}
+
+	f, err := os.Open(th.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return th, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var t Trade
+		if err := json.Unmarshal(sc.Bytes(), &t); err != nil {
+			return nil, err
+		}
+		th.add(t)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return th, nil
+}
+
+// Append writes the trade to the end of history file, the trade is synced to disk before return.
+// The trade id will be assigned if it's 0, the trade that is already in history will be ignored.
+func (th *TradeHistory) Append(t *Trade) error {
+	th.mtx.Lock()
+	defer th.mtx.Unlock()
+	n := uint64(len(th.trades))
+	switch {
+	case t.ID == 0:
+		t.ID = n + 1
+	case t.ID <= n:
+		return nil
+	case t.ID > n+1:
+		return fmt.Errorf("trade:%d is not continuous with history:%d", t.ID, n)
+	}
+
+	d, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(th.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(d, '\n')); err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	th.add(*t)
+	return nil
+}
+
+// LastID returns the id of the latest trade in history.
+func (th *TradeHistory) LastID() uint64 {
+	th.mtx.RLock()
+	defer th.mtx.RUnlock()
+	return uint64(len(th.trades))
+}
+
+// tradesAfter returns the trades whose id is greater than id in the order they were executed.
+func (th *TradeHistory) tradesAfter(id uint64) []Trade {
+	th.mtx.RLock()
+	defer th.mtx.RUnlock()
+	if id >= uint64(len(th.trades)) {
+		return nil
+	}
+	return append([]Trade{}, th.trades[id:]...)
+}
+
+// GetTrades returns the trades from start index to end, the latest trade's index is 0.
+func (th *TradeHistory) GetTrades(start, end int64) []Trade {
+	th.mtx.RLock()
+	defer th.mtx.RUnlock()
+	n := int64(len(th.trades))
+	if start < 0 {
+		start = 0
+	}
+	if end > n {
+		end = n
+	}
+
+	trades := []Trade{}
+	for i := start; i < end; i++ {
+		trades = append(trades, th.trades[n-1-i])
+	}
+	return trades
+}
+
+// GetAccountTrades returns trades of specific account from start index to end,
+// the account's latest trade's index is 0.
+func (th *TradeHistory) GetAccountTrades(aid string, start, end int64) []Trade {
+	th.mtx.RLock()
+	defer th.mtx.RUnlock()
+	idxs := th.accounts[aid]
+	n := int64(len(idxs))
+	if start < 0 {
+		start = 0
+	}
+	if end > n {
+		end = n
+	}
+
+	trades := []Trade{}
+	for i := start; i < end; i++ {
+		trades = append(trades, th.trades[idxs[n-1-i]])
+	}
+	return trades
+}
+
+// summary fills the last price, and the high, low and volume of the trades executed since the time.
+func (th *TradeHistory) summary(tk *Ticker, since int64) {
+	th.mtx.RLock()
+	defer th.mtx.RUnlock()
+	if len(th.trades) == 0 {
+		return
+	}
+
+	tk.Last = th.trades[len(th.trades)-1].Price
+	for i := len(th.trades) - 1; i >= 0 && th.trades[i].CreatedAt >= since; i-- {
+		t := th.trades[i]
+		if t.Price > tk.High {
+			tk.High = t.Price
+		}
+		if tk.Low == 0 || t.Price < tk.Low {
+			tk.Low = t.Price
+		}
+		tk.Volume += t.Amount
+	}
+}
+
+// volume returns the traded amount of the account's trades executed since the time.
+func (th *TradeHistory) volume(aid string, since int64) uint64 {
+	th.mtx.RLock()
+	defer th.mtx.RUnlock()
+	var v uint64
+	idxs := th.accounts[aid]
+	for i := len(idxs) - 1; i >= 0 && th.trades[idxs[i]].CreatedAt >= since; i-- {
+		v += th.trades[idxs[i]].Amount
+	}
+	return v
+}
+
+func (th *TradeHistory) add(t Trade) {
+	th.trades = append(th.trades, t)
+	i := len(th.trades) - 1
+	th.accounts[t.BidAccountID] = append(th.accounts[t.BidAccountID], i)
+	if t.AskAccountID != t.BidAccountID {
+		th.accounts[t.AskAccountID] = append(th.accounts[t.AskAccountID], i)
+	}
+}
@@ -0,0 +1,44 @@
+package order
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTradeHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trade-history")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	th, err := LoadTradeHistory("test/sky")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(th.GetTrades(0, 10)))
+
+	trades := []Trade{
+		{MakerID: 1, TakerID: 2, TakerType: Ask, BidAccountID: "a", AskAccountID: "b", Price: 100, Amount: 1},
+		{MakerID: 1, TakerID: 3, TakerType: Ask, BidAccountID: "a", AskAccountID: "c", Price: 100, Amount: 2},
+		{MakerID: 4, TakerID: 5, TakerType: Bid, BidAccountID: "c", AskAccountID: "c", Price: 101, Amount: 3},
+	}
+	for i := range trades {
+		assert.Nil(t, th.Append(&trades[i]))
+		assert.Equal(t, uint64(i+1), trades[i].ID)
+	}
+
+	// reload from disk.
+	th1, err := LoadTradeHistory("test/sky")
+	assert.Nil(t, err)
+
+	ts := th1.GetTrades(0, 2)
+	assert.Equal(t, []Trade{trades[2], trades[1]}, ts)
+	assert.Equal(t, []Trade{trades[0]}, th1.GetTrades(2, 10))
+
+	assert.Equal(t, []Trade{trades[1], trades[0]}, th1.GetAccountTrades("a", 0, 10))
+	assert.Equal(t, []Trade{trades[0]}, th1.GetAccountTrades("b", 0, 10))
+	// self trade only counts once.
+	assert.Equal(t, []Trade{trades[2], trades[1]}, th1.GetAccountTrades("c", 0, 10))
+	assert.Equal(t, 0, len(th1.GetAccountTrades("d", 0, 10)))
+}
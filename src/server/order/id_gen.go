@@ -1,51 +1,48 @@
 package order
 
 import (
-	"os"
-	"path/filepath"
-	"strings"
+	"sync"
 
-	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
 )
 
-// id generator
+var idBkt = "order_ids"
+
+// IDGenerator generates increasing order ids of one coin pair, the last id
+// is stored together with the orders.
 type IDGenerator struct {
-	IDC  chan uint64
-	Path string
+	cp     string
+	id     uint64
+	stored bool // the last id has been written to store.
+	mtx    sync.Mutex
 }
 
-func newIDGenerator(cp string) *IDGenerator {
-	name := strings.Replace(cp, "/", "_", 1)
+func newIDGenerator(cp string, id uint64) *IDGenerator {
 	return &IDGenerator{
-		IDC:  make(chan uint64),
-		Path: filepath.Join(orderDir, name+"."+idExt),
+		cp: cp,
+		id: id,
 	}
 }
 
-func (ig IDGenerator) Run(closing chan bool) {
-	id := struct {
-		ID uint64 `json:"id"`
-	}{}
-	if _, err := os.Stat(ig.Path); !os.IsNotExist(err) {
-		if err := file.LoadJSON(ig.Path, &id); err != nil {
-			panic(err)
-		}
-	}
-	for {
-		select {
-		case <-closing:
-			return
-		default:
-			id.ID += 1
-			ig.IDC <- id.ID
-
-			if err := file.SaveJSON(ig.Path, id, 0600); err != nil {
-				panic(err)
-			}
-		}
-	}
+// GetID returns the next id.
+func (ig *IDGenerator) GetID() uint64 {
+	ig.mtx.Lock()
+	defer ig.mtx.Unlock()
+	ig.id++
+	ig.stored = false
+	return ig.id
 }
 
-func (ig IDGenerator) GetID() uint64 {
-	return <-ig.IDC
+// flush writes the last id into store.
+func (ig *IDGenerator) flush(tx storage.Tx) error {
+	ig.mtx.Lock()
+	defer ig.mtx.Unlock()
+	if ig.stored {
+		return nil
+	}
+	if err := tx.Put(idBkt, []byte(ig.cp), storage.Itob(ig.id)); err != nil {
+		return err
+	}
+	ig.stored = true
+	return nil
 }
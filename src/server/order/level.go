@@ -0,0 +1,186 @@
+package order
+
+import "container/list"
+
+// level is the FIFO queue of the orders at the same price, the earliest order is at the front.
+// The levels of one side of the book are the nodes of an AVL tree ordered by price.
+type level struct {
+	price       uint64
+	orders      *list.List // *Order, ordered by arrival.
+	left, right *level
+	height      int
+}
+
+// levels is the price tree of one side of the book.
+type levels struct {
+	root *level
+	size int // number of orders.
+}
+
+// get returns the level of the price, the level is created if not exist.
+func (ls *levels) get(price uint64) *level {
+	var lvl *level
+	ls.root = insertLevel(ls.root, price, &lvl)
+	return lvl
+}
+
+// find returns the level of the price, nil if not exist.
+func (ls *levels) find(price uint64) *level {
+	n := ls.root
+	for n != nil {
+		switch {
+		case price < n.price:
+			n = n.left
+		case price > n.price:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// remove deletes the level of the price from tree.
+func (ls *levels) remove(price uint64) {
+	ls.root = removeLevel(ls.root, price)
+}
+
+// min returns the level of the lowest price.
+func (ls *levels) min() *level {
+	n := ls.root
+	for n != nil && n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+// max returns the level of the highest price.
+func (ls *levels) max() *level {
+	n := ls.root
+	for n != nil && n.right != nil {
+		n = n.right
+	}
+	return n
+}
+
+// walk visits the levels in price order, descending if desc is true, until fn returns false.
+func (ls *levels) walk(desc bool, fn func(lvl *level) bool) {
+	walkLevel(ls.root, desc, fn)
+}
+
+func walkLevel(n *level, desc bool, fn func(lvl *level) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	first, second := n.left, n.right
+	if desc {
+		first, second = n.right, n.left
+	}
+	return walkLevel(first, desc, fn) && fn(n) && walkLevel(second, desc, fn)
+}
+
+func height(n *level) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *level) update() {
+	n.height = height(n.left) + 1
+	if h := height(n.right) + 1; h > n.height {
+		n.height = h
+	}
+}
+
+func rotateRight(n *level) *level {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func rotateLeft(n *level) *level {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+// balance restores the AVL property of the subtree after insertion or removal.
+func balance(n *level) *level {
+	n.update()
+	switch bf := height(n.left) - height(n.right); {
+	case bf > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case bf < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+func insertLevel(n *level, price uint64, lvl **level) *level {
+	if n == nil {
+		*lvl = &level{price: price, orders: list.New(), height: 1}
+		return *lvl
+	}
+
+	switch {
+	case price < n.price:
+		n.left = insertLevel(n.left, price, lvl)
+	case price > n.price:
+		n.right = insertLevel(n.right, price, lvl)
+	default:
+		*lvl = n
+		return n
+	}
+	return balance(n)
+}
+
+func removeLevel(n *level, price uint64) *level {
+	if n == nil {
+		return nil
+	}
+
+	switch {
+	case price < n.price:
+		n.left = removeLevel(n.left, price)
+	case price > n.price:
+		n.right = removeLevel(n.right, price)
+	default:
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		// replace the node with the lowest level of the right subtree.
+		m := n.right
+		for m.left != nil {
+			m = m.left
+		}
+		m.right = removeMinLevel(n.right)
+		m.left = n.left
+		n = m
+	}
+	return balance(n)
+}
+
+func removeMinLevel(n *level) *level {
+	if n.left == nil {
+		return n.right
+	}
+	n.left = removeMinLevel(n.left)
+	return balance(n)
+}
@@ -5,56 +5,209 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	logging "github.com/op/go-logging"
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
 	"github.com/skycoin/skycoin/src/util/file"
 )
 
+var logger = logging.MustGetLogger("exchange.order")
+
+// Committer executes the function, and commits the changes it made together with
+// the pending changes of accounts, so the executed trades are durable before settlement.
+type Committer func(fn func() error) error
+
+// Settler moves the coins of the orders and trades, it's called by the matching goroutine
+// inside the committer, so the balance changes are committed together with the book changes.
+type Settler interface {
+	Lock(cp string, od Order) error   // locks the escrow of the new order, the order is rejected if failed.
+	Unlock(cp string, od Order) error // gives back the escrow of the unfilled part of the order, it's kept if failed.
+	Settle(cp string, t Trade) error  // settles the executed trade, it's left unsettled if failed.
+}
+
+// Migrator is called inside the transaction that moves the legacy books into store, with the resting
+// orders of each coin pair, so the changes it writes are committed together with the books.
+type Migrator func(tx storage.Tx, orders map[string][]Order) error
+
+// Notifier is called by the matching goroutine after the book changes are committed.
+type Notifier interface {
+	Notify(cp string, trades []Trade) // the book was changed, trades are the trades executed by the change.
+}
+
+type nopSettler struct{}
+
+func (nopSettler) Lock(cp string, od Order) error   { return nil }
+func (nopSettler) Unlock(cp string, od Order) error { return nil }
+func (nopSettler) Settle(cp string, t Trade) error  { return nil }
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(cp string, trades []Trade) {}
+
+// Manager manages the order books of all coin pairs, the changes of books are
+// recorded as events, and will be written into store by Flush. Each book is changed
+// only by its matching goroutine, the orders are matched when they arrive.
 type Manager struct {
-	books map[string]*Book
-	chans map[string]chan Order
-	idg   map[string]*IDGenerator
+	books        map[string]*Book
+	conds        map[string]*condBook   // conditional orders waiting for trigger, protected by mtx.
+	candles      map[string]*candleBook // candles of the executed trades, protected by mtx.
+	fees         map[string]FeeSchedule // fee rates of the coin pairs, no fee is charged if not set.
+	reqs         map[string]chan func() // requests executed by the matching goroutines.
+	idg          map[string]*IDGenerator
+	histories    map[string]*TradeHistory
+	logs         map[string]*bookLog // write-ahead logs of the books.
+	snapInterval uint64              // number of events between snapshots.
+	compact      bool                // delete the events included in snapshot.
+	commit       Committer
+	settler      Settler
+	notifier     Notifier
+	stopped      chan struct{} // closed by Stop, the requests are rejected after it.
+	stopOnce     sync.Once
+	mtx          sync.Mutex // mutex for protecting the logs, conditional orders and candles.
 }
 
 func NewManager() *Manager {
 	return &Manager{
-		books: make(map[string]*Book),
-		chans: make(map[string]chan Order),
-		idg:   make(map[string]*IDGenerator),
+		books:     make(map[string]*Book),
+		conds:     make(map[string]*condBook),
+		candles:   make(map[string]*candleBook),
+		fees:      make(map[string]FeeSchedule),
+		reqs:      make(map[string]chan func()),
+		idg:       make(map[string]*IDGenerator),
+		histories: make(map[string]*TradeHistory),
+		logs:      make(map[string]*bookLog),
+		commit: func(fn func() error) error {
+			return fn()
+		},
+		settler:  nopSettler{},
+		notifier: nopNotifier{},
+		stopped:  make(chan struct{}),
+	}
+}
+
+// bookBkt returns the bucket name of the orders of specific coin pair.
+func bookBkt(cp string) string {
+	return "book:" + cp
+}
+
+// LoadManager loads the order books from store, the events after the last snapshot
+// are replayed on top of it. The books saved in the order book dir by old version
+// will be moved into the store, the migrate is called for them if it's not nil.
+// os.ErrNotExist will be returned if there's no order book.
+func LoadManager(s storage.Store, migrate Migrator) (*Manager, error) {
+	m := NewManager()
+	err := s.View(func(tx storage.Tx) error {
+		// each coin pair has an id generator.
+		return tx.ForEach(idBkt, func(k, v []byte) error {
+			cp := string(k)
+			bk := &Book{}
+			err := tx.ForEach(bookBkt(cp), func(k, v []byte) error {
+				// the orders are stored by id, so they're queued in the order they arrived.
+				od := Order{}
+				if err := json.Unmarshal(v, &od); err != nil {
+					return err
+				}
+				bk.add(od)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			l, err := loadLog(tx, cp, bk)
+			if err != nil {
+				return err
+			}
+
+			cb, err := loadCondBook(tx, cp)
+			if err != nil {
+				return err
+			}
+
+			cdb, err := loadCandleBook(tx, cp)
+			if err != nil {
+				return err
+			}
+			m.books[cp] = bk
+			m.conds[cp] = cb
+			m.candles[cp] = cdb
+			m.reqs[cp] = make(chan func())
+			m.logs[cp] = l
+
+			g := newIDGenerator(cp, storage.Btoi(v))
+			g.stored = true
+			m.idg[cp] = g
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	if len(m.books) == 0 {
+		if err := m.loadLegacyBooks(s, migrate); err != nil {
+			return nil, err
+		}
+	}
+
+	// load trade history.
+	for cp := range m.books {
+		th, err := LoadTradeHistory(cp)
+		if err != nil {
+			return nil, err
+		}
+		m.histories[cp] = th
+
+		// the trades committed before crash may not be recorded in history, the unsettled
+		// trades are appended too for the store written before the unrecorded trades were kept.
+		l := m.logs[cp]
+		for _, t := range m.GetUnsettledTrades(cp) {
+			if _, ok := l.unrecorded[t.ID]; !ok && t.ID > th.LastID() {
+				l.unrecorded[t.ID] = t
+			}
+		}
+		if err := m.recordTrades(cp); err != nil {
+			return nil, err
+		}
+		if id := th.LastID(); id > l.tradeID {
+			l.tradeID = id
+		}
+		m.catchUpCandles(cp)
+	}
+	return m, nil
 }
 
-func LoadManager() (*Manager, error) {
+// loadLegacyBooks loads the order books and ids from the files in order book dir,
+// and writes them into store together with the changes of migrate.
+func (m *Manager) loadLegacyBooks(s storage.Store, migrate Migrator) error {
 	// check if the order dir exists
 	if _, err := os.Stat(orderDir); os.IsNotExist(err) {
-		return nil, err
+		return err
 	}
 
 	files, err := ioutil.ReadDir(orderDir)
 	if err != nil {
-		return nil, err
-	}
-
-	if len(files) == 0 {
-		return nil, os.ErrNotExist
+		return err
 	}
 
-	m := NewManager()
 	for _, f := range files {
 		if !strings.HasSuffix(f.Name(), orderExt) {
 			continue
 		}
 		d, err := ioutil.ReadFile(filepath.Join(orderDir, f.Name()))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		bj := BookJson{}
 		if err := json.Unmarshal(d, &bj); err != nil {
-			return nil, err
+			return err
 		}
 		p := strings.Split(f.Name(), ".")
 		pair := strings.Split(p[0], "_")
@@ -63,12 +216,52 @@ func LoadManager() (*Manager, error) {
 		}
 		cp := strings.Join(pair, "/")
 		m.books[cp] = NewBookFromJson(bj)
+		m.conds[cp] = newCondBook()
+		m.candles[cp] = newCandleBook()
+		m.reqs[cp] = make(chan func())
 
 		// init order id generator.
-		m.idg[cp] = newIDGenerator(cp)
+		id := struct {
+			ID uint64 `json:"id"`
+		}{}
+		idPath := filepath.Join(orderDir, p[0]+"."+idExt)
+		if _, err := os.Stat(idPath); !os.IsNotExist(err) {
+			if err := file.LoadJSON(idPath, &id); err != nil {
+				return err
+			}
+		}
+		m.idg[cp] = newIDGenerator(cp, id.ID)
+
+		l := newBookLog()
+		for _, od := range append(bj.BidOrders, bj.AskOrders...) {
+			l.dirty[od.ID] = true
+		}
+		m.logs[cp] = l
 	}
 
-	return m, nil
+	if len(m.books) == 0 {
+		return os.ErrNotExist
+	}
+
+	return s.Update(func(tx storage.Tx) error {
+		for cp, l := range m.logs {
+			if err := m.flushLog(tx, cp, l, true); err != nil {
+				return err
+			}
+		}
+		if err := m.Flush(tx); err != nil {
+			return err
+		}
+
+		if migrate == nil {
+			return nil
+		}
+		orders := make(map[string][]Order, len(m.books))
+		for cp, bk := range m.books {
+			orders[cp] = append(bk.GetOrders(Bid, 0, math.MaxInt64), bk.GetOrders(Ask, 0, math.MaxInt64)...)
+		}
+		return migrate(tx, orders)
+	})
 }
 
 // AddBook add the order book of specific coin pair to manager,
@@ -81,10 +274,30 @@ func (m *Manager) AddBook(coinPair string, book *Book) error {
 	if _, ok := m.books[coinPair]; ok {
 		return fmt.Errorf("book of coin pair: %s already exists", coinPair)
 	}
+	th, err := LoadTradeHistory(coinPair)
+	if err != nil {
+		return err
+	}
+
 	bk := book.Copy()
 	m.books[coinPair] = &bk
+	m.conds[coinPair] = newCondBook()
+	m.candles[coinPair] = newCandleBook()
+	m.reqs[coinPair] = make(chan func())
+
+	m.idg[coinPair] = newIDGenerator(coinPair, 0)
+	m.histories[coinPair] = th
+	m.catchUpCandles(coinPair)
 
-	m.idg[coinPair] = newIDGenerator(coinPair)
+	l := newBookLog()
+	l.tradeID = th.LastID()
+	for _, tp := range []Type{Bid, Ask} {
+		for _, od := range bk.GetOrders(tp, 0, math.MaxInt64) {
+			od := od
+			l.append(Event{Type: EventAdd, Order: &od})
+		}
+	}
+	m.logs[coinPair] = l
 	return nil
 }
 
@@ -95,32 +308,412 @@ func (m *Manager) IsExist(coinPair string) bool {
 	return false
 }
 
-// AddOrder add bid or ask order to order book.
-func (m *Manager) AddOrder(coinPair string, order Order) (uint64, error) {
-	bk, ok := m.books[coinPair]
+// Place sends the order to the matching goroutine of the coin pair, the order is matched when
+// it arrives, and the unfilled part rests in book unless it's an immediate order. The escrow, the
+// book changes and the settlement of the trades are committed together. The order is returned with
+// its id, and the rest amount, which was cancelled if it's an immediate order.
+func (m *Manager) Place(coinPair string, od Order) (Order, error) {
+	var err error
+	if e := m.do(coinPair, func() { od, err = m.place(coinPair, od) }); e != nil {
+		return Order{}, e
+	}
+	return od, err
+}
+
+// PlaceConditional sends the conditional order to the matching goroutine of the coin pair, its
+// escrow is locked now, and it's placed into book when the last trade price crosses the trigger
+// price. The order is rejected if it would be triggered by the current last price.
+func (m *Manager) PlaceConditional(coinPair string, c Conditional) (Conditional, error) {
+	var err error
+	if e := m.do(coinPair, func() { c, err = m.placeConditional(coinPair, c) }); e != nil {
+		return Conditional{}, e
+	}
+	return c, err
+}
+
+// CancelConditional removes the conditional order that is not triggered yet, and gives back its escrow.
+func (m *Manager) CancelConditional(coinPair string, id uint64, accountID string) (Conditional, error) {
+	var c Conditional
+	var err error
+	if e := m.do(coinPair, func() { c, err = m.cancelConditional(coinPair, id, accountID) }); e != nil {
+		return Conditional{}, e
+	}
+	return c, err
+}
+
+// GetConditionals returns the conditional orders of the account ordered by id,
+// all the conditional orders of the coin pair are returned if aid is empty.
+func (m *Manager) GetConditionals(cp string, aid string) ([]Conditional, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	cb, ok := m.conds[cp]
 	if !ok {
-		return 0, fmt.Errorf("coin pair:%s not supported", coinPair)
+		return []Conditional{}, errors.New("get conditional orders failed, err: unknow coin pair")
 	}
+	return cb.list(aid), nil
+}
 
-	idg, ok := m.idg[coinPair]
+// CancelOrder sends the cancel request to the matching goroutine of the coin pair, the order is
+// removed from book and its escrow is given back, only the account that created the order can cancel it.
+func (m *Manager) CancelOrder(coinPair string, orderID uint64, accountID string) (Order, error) {
+	var od Order
+	var err error
+	if e := m.do(coinPair, func() { od, err = m.cancel(coinPair, orderID, accountID) }); e != nil {
+		return Order{}, e
+	}
+	return od, err
+}
+
+// do executes the function in the matching goroutine of the coin pair, and waits until it's done.
+// ErrManagerStopped is returned if the manager is stopped before the function is executed.
+func (m *Manager) do(coinPair string, fn func()) error {
+	reqs, ok := m.reqs[coinPair]
 	if !ok {
-		return 0, fmt.Errorf("coin pair:%s's id generator not supported", coinPair)
+		return fmt.Errorf("coin pair:%s not supported", coinPair)
+	}
+
+	done := make(chan struct{})
+	select {
+	case reqs <- func() {
+		fn()
+		close(done)
+	}:
+	case <-m.stopped:
+		return ErrManagerStopped
+	}
+
+	// the matching goroutine finishes the received function before it returns.
+	select {
+	case <-done:
+		return nil
+	case <-m.stopped:
+		select {
+		case <-done:
+			return nil
+		default:
+			return ErrManagerStopped
+		}
+	}
+}
+
+// Stop stops the matching goroutines started by Start, and rejects the requests that are
+// not executed yet, including the ones sent before Start.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() { close(m.stopped) })
+}
+
+// place checks and matches the order, it must be called by the matching goroutine.
+func (m *Manager) place(cp string, od Order) (Order, error) {
+	bk := m.books[cp]
+	if od.Type != Bid && od.Type != Ask {
+		return Order{}, errors.New("unknow order type")
 	}
 
-	switch order.Type {
+	var trades []Trade
+	var serr error
+	err := m.commit(func() error {
+		// market bid is limited at the highest price it may pay, which decides its escrow.
+		if od.Kind == Market {
+			price, err := marketPrice(bk, od.Type, od.Amount)
+			if err != nil {
+				return err
+			}
+			if _, err := Volume(price, od.Amount); err != nil {
+				return err
+			}
+			od.Price = price
+		}
+
+		if od.ID == 0 {
+			od.ID = m.idg[cp].GetID()
+		}
+
+		if od.PostOnly && bk.crosses(od) {
+			return ErrPostOnlyCross
+		}
+
+		if od.TimeInForce == FOK && bk.fillable(od) < od.RestAmt {
+			return ErrNotFilled
+		}
+
+		// nothing can fail once the escrow is locked.
+		if err := m.settler.Lock(cp, od); err != nil {
+			return err
+		}
+
+		od, trades, serr = m.execute(cp, od)
+		if serr == nil {
+			var more []Trade
+			more, serr = m.trigger(cp, trades)
+			trades = append(trades, more...)
+		}
+		return nil
+	})
+	if err != nil {
+		return Order{}, err
+	}
+
+	if err := m.recordTrades(cp); err != nil {
+		logger.Error("record trades of %s failed: %v", cp, err)
+	}
+	m.notifier.Notify(cp, trades)
+	if serr != nil {
+		// the order is in book, but the match is aborted.
+		logger.Error("match %s order:%d failed: %v", cp, od.ID, serr)
+		return od, serr
+	}
+	return od, nil
+}
+
+// execute adds the order whose escrow is locked to book and matches it, the unfilled part of
+// immediate order is cancelled, it's left in book if its escrow can't be given back.
+// The order is returned with its rest amount.
+func (m *Manager) execute(cp string, od Order) (Order, []Trade, error) {
+	bk := m.books[cp]
+	m.addOrder(cp, od)
+	trades, err := m.matchBook(cp)
+
+	rest, ok := bk.getOrder(od.ID)
+	switch {
+	case !ok:
+		// fullfilled.
+		od.RestAmt = 0
+	case od.Immediate():
+		od.RestAmt = rest.RestAmt
+		if uerr := m.settler.Unlock(cp, rest); uerr != nil {
+			if err == nil {
+				err = uerr
+			}
+			break
+		}
+		bk.RemoveOrder(od.ID, od.AccountID)
+		m.logEvent(cp, Event{Type: EventCancel, Order: &rest})
+	default:
+		od.RestAmt = rest.RestAmt
+	}
+	return od, trades, err
+}
+
+// trigger places the conditional orders triggered by the last price of the trades into book, the
+// trades they made may trigger more orders. The executed trades are returned, the triggering stops
+// once a match is aborted, after the orders removed from the conditional book are placed.
+func (m *Manager) trigger(cp string, trades []Trade) ([]Trade, error) {
+	all := []Trade{}
+	var err error
+	for len(trades) > 0 && err == nil {
+		price := trades[len(trades)-1].Price
+		m.mtx.Lock()
+		cb := m.conds[cp]
+		cs := cb.triggered(price)
+		for _, c := range cs {
+			cb.remove(c.ID)
+		}
+		m.mtx.Unlock()
+
+		trades = nil
+		for _, c := range cs {
+			ts, e := m.fire(cp, c)
+			if e != nil && err == nil {
+				err = e
+			}
+			trades = append(trades, ts...)
+		}
+		all = append(all, trades...)
+	}
+	return all, err
+}
+
+// fire places the triggered order into book, it gets a new id, so it's matched as the latest order.
+// The FOK order that can't be filled entirely is cancelled, and its escrow is given back, it's
+// put back to the conditional book if the escrow can't be given back.
+func (m *Manager) fire(cp string, c Conditional) ([]Trade, error) {
+	od := c.Order
+	od.ID = m.idg[cp].GetID()
+	od.CreatedAt = time.Now().Unix()
+	if od.TimeInForce == FOK && m.books[cp].fillable(od) < od.RestAmt {
+		if err := m.settler.Unlock(cp, od); err != nil {
+			m.mtx.Lock()
+			m.conds[cp].add(c)
+			m.mtx.Unlock()
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	_, trades, err := m.execute(cp, od)
+	return trades, err
+}
+
+// placeConditional locks the escrow of the conditional order, and keeps it until triggered,
+// it must be called by the matching goroutine.
+func (m *Manager) placeConditional(cp string, c Conditional) (Conditional, error) {
+	if c.Type != Bid && c.Type != Ask {
+		return Conditional{}, errors.New("unknow order type")
+	}
+
+	if price, ok := m.lastPrice(cp); ok && c.Triggered(price) {
+		return Conditional{}, ErrWouldTrigger
+	}
+
+	err := m.commit(func() error {
+		if c.ID == 0 {
+			c.ID = m.idg[cp].GetID()
+		}
+
+		if err := m.settler.Lock(cp, c.Order); err != nil {
+			return err
+		}
+
+		m.mtx.Lock()
+		m.conds[cp].add(c)
+		m.mtx.Unlock()
+		return nil
+	})
+	if err != nil {
+		return Conditional{}, err
+	}
+	return c, nil
+}
+
+// cancelConditional removes the conditional order, and gives back its escrow,
+// it must be called by the matching goroutine.
+func (m *Manager) cancelConditional(cp string, id uint64, aid string) (Conditional, error) {
+	var c Conditional
+	err := m.commit(func() error {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+		cb := m.conds[cp]
+		var ok bool
+		c, ok = cb.orders[id]
+		if !ok {
+			return ErrOrderNotExist
+		}
+
+		if c.AccountID != aid {
+			return ErrNotOrderOwner
+		}
+		if err := m.settler.Unlock(cp, c.Order); err != nil {
+			return err
+		}
+		cb.remove(id)
+		return nil
+	})
+	return c, err
+}
+
+// lastPrice returns the price of the latest trade of specific coin pair.
+func (m *Manager) lastPrice(cp string) (uint64, bool) {
+	trades := m.histories[cp].GetTrades(0, 1)
+	if len(trades) == 0 {
+		return 0, false
+	}
+	return trades[0].Price, true
+}
+
+// cancel removes the order from book, it must be called by the matching goroutine.
+func (m *Manager) cancel(cp string, id uint64, aid string) (Order, error) {
+	var od Order
+	err := m.commit(func() error {
+		bk := m.books[cp]
+		var ok bool
+		od, ok = bk.getOrder(id)
+		if !ok {
+			return ErrOrderNotExist
+		}
+		if od.AccountID != aid {
+			return ErrNotOrderOwner
+		}
+
+		// the order is kept in book if its escrow can't be given back.
+		if err := m.settler.Unlock(cp, od); err != nil {
+			return err
+		}
+		bk.RemoveOrder(id, aid)
+		m.logEvent(cp, Event{Type: EventCancel, Order: &od})
+		return nil
+	})
+	if err != nil {
+		return Order{}, err
+	}
+
+	m.notifier.Notify(cp, nil)
+	return od, nil
+}
+
+// addOrder adds the order to book without matching.
+func (m *Manager) addOrder(coinPair string, order Order) {
+	bk := m.books[coinPair]
+	bk.mtx.Lock()
+	bk.add(order)
+	bk.mtx.Unlock()
+	m.logEvent(coinPair, Event{Type: EventAdd, Order: &order})
+}
+
+// matchBook matches the crossed orders in book, the fees of the trades are charged, and the trades are logged and settled.
+// The settlement is aborted once a trade fails to be settled.
+func (m *Manager) matchBook(cp string) ([]Trade, error) {
+	trades := m.books[cp].Match()
+	m.chargeFees(cp, trades)
+	m.logTrades(cp, trades)
+	m.aggregate(cp, trades)
+	for _, t := range trades {
+		if err := m.settler.Settle(cp, t); err != nil {
+			// the trade and the ones after it are committed as unsettled, they're settled again after restart.
+			return trades, fmt.Errorf("settle %s trade:%d failed: %v", cp, t.ID, err)
+		}
+		m.SettleTrade(cp, t.ID)
+	}
+	return trades, nil
+}
+
+// marketPrice returns the price limit of the market order, market bid is limited at the highest
+// ask price it needs to be filled, market ask has no limit.
+func marketPrice(bk *Book, tp Type, amt uint64) (uint64, error) {
+	switch tp {
 	case Bid:
-		order.ID = idg.GetID()
-		bk.AddBid(order)
-		return order.ID, nil
+		return bk.sweepPrice(amt)
 	case Ask:
-		order.ID = idg.GetID()
-		bk.AddAsk(order)
-		return order.ID, nil
+		if bk.Len(Bid) == 0 {
+			return 0, ErrNoLiquidity
+		}
+		return 0, nil
 	default:
 		return 0, errors.New("unknow order type")
 	}
 }
 
+// recordTrades appends the committed trades to the history of specific coin pair, the trades
+// are kept in store until they're appended, the failed ones are appended again in next call or on load.
+func (m *Manager) recordTrades(coinPair string) error {
+	th := m.histories[coinPair]
+	for _, t := range m.getUnrecordedTrades(coinPair) {
+		if err := th.Append(&t); err != nil {
+			return err
+		}
+		m.recordTrade(coinPair, t.ID)
+	}
+	return nil
+}
+
+// NewOrderID generates order id of specific coin pair.
+func (m *Manager) NewOrderID(coinPair string) (uint64, error) {
+	idg, ok := m.idg[coinPair]
+	if !ok {
+		return 0, fmt.Errorf("coin pair:%s's id generator not supported", coinPair)
+	}
+	return idg.GetID(), nil
+}
+
+// GetCoinPairs returns the coin pairs of all books.
+func (m *Manager) GetCoinPairs() []string {
+	cps := make([]string, 0, len(m.books))
+	for cp := range m.books {
+		cps = append(cps, cp)
+	}
+	sort.Strings(cps)
+	return cps
+}
+
 // GetBook get specific coin pair's order book.
 // the return book is an copy of internal book, for thread safe.
 func (m *Manager) GetBook(coinPair string) Book {
@@ -134,45 +727,117 @@ func (m *Manager) GetOrders(cp string, tp Type, start, end int64) ([]Order, erro
 	return m.books[cp].GetOrders(tp, start, end), nil
 }
 
-func (m *Manager) RegisterOrderChan(coinPair string, c chan Order) {
-	m.chans[coinPair] = c
+// GetDepth returns at most n best price levels of each side of the book of specific coin pair.
+func (m *Manager) GetDepth(cp string, n int) ([]PriceLevel, []PriceLevel, error) {
+	bk, ok := m.books[cp]
+	if !ok {
+		return nil, nil, errors.New("get depth failed, err: unknow coin pair")
+	}
+	return bk.Depth(Bid, n), bk.Depth(Ask, n), nil
 }
 
-// Run start the manager, tm is the match tick time, closing is used for stopping the manager from running.
-func (m *Manager) Start(tm time.Duration, closing chan bool) {
-	// start the id generators
-	for _, g := range m.idg {
-		go g.Run(closing)
+// GetTicker returns the ticker of specific coin pair, the high, low and volume are
+// calculated over the trades executed in the last TickerPeriod.
+func (m *Manager) GetTicker(cp string) (Ticker, error) {
+	bk, ok := m.books[cp]
+	if !ok {
+		return Ticker{}, errors.New("get ticker failed, err: unknow coin pair")
 	}
 
-	// start the match timer.
+	tk := Ticker{}
+	m.histories[cp].summary(&tk, time.Now().Add(-TickerPeriod).Unix())
+	tk.BestBid, _ = bk.BestPrice(Bid)
+	tk.BestAsk, _ = bk.BestPrice(Ask)
+	return tk, nil
+}
+
+// GetTrades gets trades of specific coin pair from start index to end, the latest trade's index is 0.
+func (m *Manager) GetTrades(cp string, start, end int64) ([]Trade, error) {
+	th, ok := m.histories[cp]
+	if !ok {
+		return []Trade{}, errors.New("get trades failed, err: unknow coin pair")
+	}
+	return th.GetTrades(start, end), nil
+}
+
+// GetAccountTrades gets trades of specific account and coin pair from start index to end.
+func (m *Manager) GetAccountTrades(cp string, aid string, start, end int64) ([]Trade, error) {
+	th, ok := m.histories[cp]
+	if !ok {
+		return []Trade{}, errors.New("get trades failed, err: unknow coin pair")
+	}
+	return th.GetAccountTrades(aid, start, end), nil
+}
+
+// RegisterCommitter registers the committer that the matching will be executed in.
+func (m *Manager) RegisterCommitter(c Committer) {
+	m.commit = c
+}
+
+// RegisterSettler registers the settler that moves the coins of orders and trades.
+func (m *Manager) RegisterSettler(s Settler) {
+	m.settler = s
+}
+
+// RegisterNotifier registers the notifier of the committed book changes.
+func (m *Manager) RegisterNotifier(n Notifier) {
+	m.notifier = n
+}
+
+// Start starts the matching goroutine of each book, which is the only goroutine that changes
+// the book. The orders left crossed in book by old version are matched first, then the requests
+// are executed one by one. closing is used for stopping the manager from running, the manager
+// is stopped once Start returns.
+func (m *Manager) Start(closing chan bool) {
+	defer m.Stop()
 	wg := sync.WaitGroup{}
-	for p, bk := range m.books {
+	for p := range m.books {
 		wg.Add(1)
-		go func(cp string, b *Book, orderChan chan Order, c chan bool, w *sync.WaitGroup) {
-			orders := []Order{}
+		go func(cp string) {
+			defer wg.Done()
+			m.match(cp)
+			reqs := m.reqs[cp]
 			for {
 				select {
-				case <-c:
-					w.Done()
+				case <-closing:
+					return
+				case <-m.stopped:
 					return
-				case <-time.After(tm):
-					orders = b.Match()
-					for _, o := range orders {
-						orderChan <- o
-					}
-					// update order book in local disk.
-					pairs := strings.Split(cp, "/")
-					if len(pairs) != 2 {
-						panic("error coin pair name")
-					}
-					filename := strings.Join(pairs, "_")
-					if err := file.SaveJSON(filepath.Join(orderDir, filename+"."+orderExt), b.Copy().ToMarshalable(), 0600); err != nil {
-						panic(err)
-					}
+				case fn := <-reqs:
+					fn()
 				}
 			}
-		}(p, bk, m.chans[p], closing, &wg)
+		}(p)
 	}
 	wg.Wait()
 }
+
+// match matches the book of specific coin pair, the trades are settled in the same transaction.
+func (m *Manager) match(cp string) []Trade {
+	var trades []Trade
+	var serr error
+	if err := m.commit(func() error {
+		trades, serr = m.matchBook(cp)
+		if serr == nil {
+			var more []Trade
+			more, serr = m.trigger(cp, trades)
+			trades = append(trades, more...)
+		}
+		return nil
+	}); err != nil {
+		logger.Error("match %s failed: %v", cp, err)
+		return nil
+	}
+
+	if serr != nil {
+		logger.Error("match %s failed: %v", cp, serr)
+	}
+
+	if err := m.recordTrades(cp); err != nil {
+		logger.Error("record trades of %s failed: %v", cp, err)
+	}
+	if len(trades) > 0 {
+		m.notifier.Notify(cp, trades)
+	}
+	return trades
+}
@@ -1,23 +1,63 @@
 package order
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
 	"github.com/skycoin/skycoin/src/util/file"
 	"github.com/stretchr/testify/assert"
 )
 
+// testSettler records the locked and unlocked orders, and the settled trades.
+type testSettler struct {
+	locks     []Order
+	unlocks   []Order
+	trades    []Trade
+	err       error // returned by Settle if it's not nil.
+	unlockErr error // returned by Unlock if it's not nil.
+}
+
+func (s *testSettler) Lock(cp string, od Order) error {
+	s.locks = append(s.locks, od)
+	return nil
+}
+
+func (s *testSettler) Unlock(cp string, od Order) error {
+	if s.unlockErr != nil {
+		return s.unlockErr
+	}
+	s.unlocks = append(s.unlocks, od)
+	return nil
+}
+
+func (s *testSettler) Settle(cp string, t Trade) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.trades = append(s.trades, t)
+	return nil
+}
+
 func TestManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
 	m := NewManager()
 	coinPair := "btc/sky"
 	m.AddBook(coinPair, &Book{})
-	btcSkyChan := make(chan Order, 100)
-	m.RegisterOrderChan(coinPair, btcSkyChan)
+	st := &testSettler{}
+	m.RegisterSettler(st)
 	closing := make(chan bool)
-	go m.Start(time.Duration(1)*time.Second, closing)
+	defer close(closing)
+	go m.Start(closing)
 
 	var BidOrderList = []Order{
 		Order{Type: Bid, Price: 100, CreatedAt: 132424, Amount: 1, RestAmt: 1},
@@ -36,35 +76,43 @@ func TestManager(t *testing.T) {
 	}
 
 	for _, od := range BidOrderList {
-		m.AddOrder(coinPair, od)
+		_, err := m.Place(coinPair, od)
+		assert.Nil(t, err)
 	}
 
+	// the asks are matched when they arrive.
 	for _, od := range AskOrderList {
-		m.AddOrder(coinPair, od)
+		_, err := m.Place(coinPair, od)
+		assert.Nil(t, err)
 	}
+	assert.Equal(t, 3, len(st.trades))
 
-	totalMath := 0
-	go func(orders chan Order, c chan bool) {
-		for {
-			select {
-			case od := <-orders:
-				// assert.Equal(t, od.RestAmt, 0)
-				if od.RestAmt != 0 {
-					t.Fatal("match order's reset amt is not zero")
-				}
-				totalMath += 1
-				// fmt.Printf("match order: type:%v, price:%d, amount:%d, restamt:%d\n", od.Type, od.Price, od.Amount, od.RestAmt)
-			case <-c:
-				return
-			}
-		}
-	}(btcSkyChan, closing)
-	time.Sleep(2 * time.Second)
-	// fmt.Println("add new bid: price 104")
-	m.AddOrder(coinPair, Order{Type: Bid, Price: 104, Amount: 1, RestAmt: 1})
-	time.Sleep(2 * time.Second)
-	close(closing)
-	assert.Equal(t, totalMath, 8)
+	od, err := m.Place(coinPair, Order{Type: Bid, Price: 104, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), od.RestAmt)
+	assert.Equal(t, 4, len(st.trades))
+	for _, td := range st.trades {
+		assert.Equal(t, uint64(1), td.Amount)
+	}
+
+	trades, err := m.GetTrades(coinPair, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(trades))
+	assert.Equal(t, 0, len(m.GetUnsettledTrades(coinPair)))
+
+	// the best bid is lower than the best ask.
+	bk := m.GetBook(coinPair)
+	assert.True(t, bk.GetOrders(Bid, 0, 1)[0].Price < bk.GetOrders(Ask, 0, 1)[0].Price)
+
+	// the match is aborted if the trade can't be settled, and the trade is left unsettled.
+	st.err = errors.New("settle failed")
+	_, err = m.Place(coinPair, Order{Type: Ask, Price: 100, Amount: 1, RestAmt: 1})
+	assert.NotNil(t, err)
+	assert.Equal(t, 4, len(st.trades))
+	assert.Equal(t, 1, len(m.GetUnsettledTrades(coinPair)))
+	trades, err = m.GetTrades(coinPair, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(trades))
 }
 
 func TestLoadManager(t *testing.T) {
@@ -95,11 +143,200 @@ func TestLoadManager(t *testing.T) {
 		bk.AddAsk(od)
 	}
 
-	// write book to files.
-	err := file.SaveJSON(filepath.Join(orderDir, strings.Join(coinPair, "_")+"."+orderExt), bk.ToMarshalable(), 0600)
+	dir, err := ioutil.TempDir("", "exchange-order")
 	assert.Nil(t, err)
-	m, err := LoadManager()
+	defer os.RemoveAll(dir)
+	InitDir(dir)
+
+	// write book to files of old version.
+	path := filepath.Join(orderDir, strings.Join(coinPair, "_")+"."+orderExt)
+	err = file.SaveJSON(path, bk.ToMarshalable(), 0600)
+	assert.Nil(t, err)
+	s := storage.NewMemStore()
+	cp := strings.Join(coinPair, "/")
+	var migrated map[string][]Order
+	m, err := LoadManager(s, func(tx storage.Tx, orders map[string][]Order) error {
+		migrated = orders
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(migrated[cp]))
+	bk1 := m.GetBook(cp)
+	assert.Equal(t, bk.ToMarshalable(), bk1.ToMarshalable())
+
+	// the book was moved into store.
+	assert.Nil(t, os.Remove(path))
+	m, err = LoadManager(s, nil)
+	assert.Nil(t, err)
+	bk1 = m.GetBook(cp)
+	assert.Equal(t, bk.ToMarshalable(), bk1.ToMarshalable())
+
+	// the changes are stored after flush.
+	_, err = m.cancel(cp, 1, "")
+	assert.Nil(t, err)
+	id, err := m.NewOrderID(cp)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Update(m.Flush))
+
+	m, err = LoadManager(s, nil)
+	assert.Nil(t, err)
+	bk1 = m.GetBook(cp)
+	assert.Equal(t, 4, len(bk1.GetOrders(Bid, 0, 10)))
+	assert.Equal(t, 5, len(bk1.GetOrders(Ask, 0, 10)))
+	nid, err := m.NewOrderID(cp)
+	assert.Nil(t, err)
+	assert.Equal(t, id+1, nid)
+
+	// no book in store and order book dir.
+	assert.Nil(t, os.RemoveAll(dir))
+	_, err = LoadManager(storage.NewMemStore(), nil)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGetTicker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	m := NewManager()
+	cp := "btc/sky"
+	assert.Nil(t, m.AddBook(cp, &Book{}))
+	closing := make(chan bool)
+	defer close(closing)
+	go m.Start(closing)
+
+	tk, err := m.GetTicker(cp)
+	assert.Nil(t, err)
+	assert.Equal(t, Ticker{}, tk)
+
+	// the trade executed before the ticker period is not counted.
+	old := Trade{Price: 200, Amount: 9, CreatedAt: time.Now().Add(-TickerPeriod - time.Hour).Unix()}
+	assert.Nil(t, m.histories[cp].Append(&old))
+	m.logs[cp].tradeID = old.ID
+
+	for _, od := range []Order{
+		{Type: Ask, Price: 100, Amount: 2, RestAmt: 2},
+		{Type: Ask, Price: 105, Amount: 2, RestAmt: 2},
+		{Type: Bid, Price: 105, Amount: 3, RestAmt: 3},
+		{Type: Ask, Price: 110, Amount: 1, RestAmt: 1},
+		{Type: Bid, Price: 90, Amount: 1, RestAmt: 1},
+	} {
+		_, err := m.Place(cp, od)
+		assert.Nil(t, err)
+	}
+
+	tk, err = m.GetTicker(cp)
+	assert.Nil(t, err)
+	assert.Equal(t, Ticker{Last: 105, High: 105, Low: 100, Volume: 3, BestBid: 90, BestAsk: 105}, tk)
+
+	_, err = m.GetTicker("btc/unknown")
+	assert.NotNil(t, err)
+}
+
+// BenchmarkManagerPlace places orders through the matching goroutine, each iteration places
+// a bid that takes the best ask, and an ask that rests in book.
+func BenchmarkManagerPlace(b *testing.B) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	m := NewManager()
+	cp := "btc/sky"
+	if err := m.AddBook(cp, newBenchBook()); err != nil {
+		b.Fatal(err)
+	}
+	m.idg[cp] = newIDGenerator(cp, benchRestingOrders)
+	closing := make(chan bool)
+	defer close(closing)
+	go m.Start(closing)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bid := Order{AccountID: "taker", Type: Bid, Kind: Market, Amount: 1, RestAmt: 1}
+		if od, err := m.Place(cp, bid); err != nil || od.RestAmt != 0 {
+			b.Fatalf("place bid failed: %v", err)
+		}
+
+		ask := Order{AccountID: "maker", Type: Ask, Price: uint64(i%500 + 1001), Amount: 1, RestAmt: 1}
+		if _, err := m.Place(cp, ask); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestManagerStop checks the requests are rejected instead of blocked once the manager is stopped.
+func TestManagerStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	// stopped before Start.
+	m := NewManager()
+	coinPair := "btc/sky"
+	assert.Nil(t, m.AddBook(coinPair, &Book{}))
+	m.Stop()
+	_, err = m.Place(coinPair, Order{Type: Bid, Price: 100, Amount: 1, RestAmt: 1})
+	assert.Equal(t, ErrManagerStopped, err)
+
+	// stopped once Start returns.
+	m = NewManager()
+	assert.Nil(t, m.AddBook(coinPair, &Book{}))
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		m.Start(closing)
+		close(done)
+	}()
+	_, err = m.Place(coinPair, Order{Type: Bid, Price: 100, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+	close(closing)
+	<-done
+	_, err = m.CancelOrder(coinPair, 1, "")
+	assert.Equal(t, ErrManagerStopped, err)
+	_, err = m.PlaceConditional(coinPair, Conditional{Order: Order{Type: Ask, Price: 90, Amount: 1, RestAmt: 1}, Trigger: StopLoss, TriggerPrice: 95})
+	assert.Equal(t, ErrManagerStopped, err)
+}
+
+// TestManagerUnlockFailed checks the order is kept if its escrow can't be given back.
+func TestManagerUnlockFailed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	st := &testSettler{unlockErr: errors.New("unlock failed")}
+	m.RegisterSettler(st)
+	closing := make(chan bool)
+	defer close(closing)
+	go m.Start(closing)
+
+	od, err := m.Place(coinPair, Order{Type: Bid, Price: 100, Amount: 1, RestAmt: 1, AccountID: "a"})
+	assert.Nil(t, err)
+	_, err = m.CancelOrder(coinPair, od.ID, "a")
+	assert.Equal(t, st.unlockErr, err)
+	_, ok := m.books[coinPair].getOrder(od.ID)
+	assert.True(t, ok)
+
+	c, err := m.PlaceConditional(coinPair, Conditional{Order: Order{Type: Ask, Price: 90, Amount: 1, RestAmt: 1, AccountID: "a"}, Trigger: StopLoss, TriggerPrice: 95})
+	assert.Nil(t, err)
+	_, err = m.CancelConditional(coinPair, c.ID, "a")
+	assert.Equal(t, st.unlockErr, err)
+	cs, err := m.GetConditionals(coinPair, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cs))
+
+	st.unlockErr = nil
+	_, err = m.CancelOrder(coinPair, od.ID, "a")
 	assert.Nil(t, err)
-	bk1 := m.GetBook(strings.Join(coinPair, "/"))
-	assert.Equal(t, bk, bk1)
+	_, ok = m.books[coinPair].getOrder(od.ID)
+	assert.False(t, ok)
+	assert.Equal(t, 1, len(st.unlocks))
 }
@@ -1,9 +1,12 @@
 package order
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/skycoin/skycoin/src/util/file"
@@ -16,74 +19,69 @@ const (
 	Ask
 )
 
+// Kind is the kind of order, limit order has a price, while market order
+// is executed at the best prices in book.
+type Kind uint8
+
+const (
+	Limit Kind = iota
+	Market
+)
+
+// TimeInForce decides how long the order stays in book.
+type TimeInForce uint8
+
+const (
+	GTC TimeInForce = iota // good till cancelled, the unfilled part rests in book.
+	IOC                    // immediate or cancel, the unfilled part is cancelled.
+	FOK                    // fill or kill, the order is rejected if it can't be filled entirely.
+)
+
 var (
 	orderDir string = filepath.Join(file.UserHome(), ".skycoin-exchange/orderbook")
 	orderExt string = "ods"
 	idExt    string = "id"
 )
 
-type Order struct {
-	ID        uint64 `json:"id"` // order id.
-	AccountID string `json:"account_id"`
-	Type      Type   `json:"type"`       // order type.
-	Price     uint64 `json:"price"`      // price of this order.
-	Amount    uint64 `json:"amount"`     // total amount of this order.
-	RestAmt   uint64 `json:"reset_amt"`  // rest amount.
-	CreatedAt int64  `json:"created_at"` // created time of the order.
-}
-
-type byPriceThenTimeDesc []Order
-type byPriceThenTimeAsc []Order
-type byOrderID []Order
-
-func (bp byPriceThenTimeDesc) Len() int {
-	return len(bp)
-}
-
-func (bp byPriceThenTimeDesc) Less(i, j int) bool {
-	a := bp[i]
-	b := bp[j]
-	if a.Price > b.Price {
-		return true
-	} else if a.Price == b.Price {
-		return a.CreatedAt > b.CreatedAt
-	}
-	return false
-}
-
-func (bp byPriceThenTimeDesc) Swap(i, j int) {
-	bp[i], bp[j] = bp[j], bp[i]
-}
+var (
+	// ErrOrderNotExist is returned when the order is not in the book, maybe it's already matched or canceled.
+	ErrOrderNotExist = errors.New("order does not exist")
+	// ErrNotOrderOwner is returned when someone tries to cancel an order that is not owned by him.
+	ErrNotOrderOwner = errors.New("not the owner of the order")
+	// ErrPostOnlyCross is returned when the post-only order would take liquidity from book.
+	ErrPostOnlyCross = errors.New("post-only order would cross the book")
+	// ErrNotFilled is returned when the FOK order can't be filled entirely.
+	ErrNotFilled = errors.New("order can't be filled entirely")
+	// ErrNoLiquidity is returned when there's no order in book for the market order to match.
+	ErrNoLiquidity = errors.New("no liquidity in book")
+	// ErrManagerStopped is returned when the request is sent to the stopped manager.
+	ErrManagerStopped = errors.New("order manager stopped")
+	// ErrOverflow is returned when the coins of the order exceed MaxVolume.
+	ErrOverflow = errors.New("price * amount overflows")
+)
 
-func (bp byPriceThenTimeAsc) Len() int {
-	return len(bp)
-}
+// MaxVolume is the max coins an order can lock or trade, as the balances are int64.
+const MaxVolume = math.MaxInt64
 
-func (bp byPriceThenTimeAsc) Less(i, j int) bool {
-	a := bp[i]
-	b := bp[j]
-	if a.Price < b.Price {
-		return true
-	} else if a.Price == b.Price {
-		return a.CreatedAt > b.CreatedAt
+// Volume returns price*amount, ErrOverflow is returned if it exceeds MaxVolume.
+func Volume(price, amount uint64) (uint64, error) {
+	if amount > MaxVolume || (price != 0 && amount > MaxVolume/price) {
+		return 0, ErrOverflow
 	}
-	return false
-}
-
-func (bp byPriceThenTimeAsc) Swap(i, j int) {
-	bp[i], bp[j] = bp[j], bp[i]
+	return price * amount, nil
 }
 
-func (bo byOrderID) Len() int {
-	return len(bo)
-}
-
-func (bo byOrderID) Less(i, j int) bool {
-	return bo[i].ID > bo[j].ID
-}
-
-func (bo byOrderID) Swap(i, j int) {
-	bo[i], bo[j] = bo[j], bo[i]
+type Order struct {
+	ID          uint64      `json:"id"` // order id.
+	AccountID   string      `json:"account_id"`
+	Type        Type        `json:"type"`                    // order type.
+	Kind        Kind        `json:"kind,omitempty"`          // limit or market.
+	TimeInForce TimeInForce `json:"time_in_force,omitempty"` // GTC, IOC or FOK.
+	PostOnly    bool        `json:"post_only,omitempty"`     // the order must not take liquidity.
+	Price       uint64      `json:"price"`                   // price of this order.
+	Amount      uint64      `json:"amount"`                  // total amount of this order.
+	RestAmt     uint64      `json:"reset_amt"`               // rest amount.
+	CreatedAt   int64       `json:"created_at"`              // created time of the order.
 }
 
 func InitDir(path string) {
@@ -111,6 +109,20 @@ func New(aid string, tp Type, price uint64, amount uint64) *Order {
 	}
 }
 
+// RestEscrow returns the coins locked for the unfilled part of the order,
+// bid locks price*amount of the sub coin in the pair, ask locks amount of the main coin.
+// The order must be checked by Pair.CheckOrder, so the escrow doesn't overflow.
+func (o Order) RestEscrow() uint64 {
+	switch o.Type {
+	case Bid:
+		return o.Price * o.RestAmt
+	case Ask:
+		return o.RestAmt
+	default:
+		return 0
+	}
+}
+
 func (tp Type) String() string {
 	switch tp {
 	case Bid:
@@ -122,6 +134,74 @@ func (tp Type) String() string {
 	}
 }
 
+// Immediate checks if the order must be matched when it's added, the unfilled
+// part of immediate order never rests in book.
+func (o Order) Immediate() bool {
+	return o.Kind == Market || o.TimeInForce != GTC
+}
+
+// Check checks the combination of kind, time in force and post-only.
+func (o Order) Check() error {
+	if o.Kind == Market && o.Price != 0 {
+		return errors.New("market order must not have price")
+	}
+
+	if o.PostOnly && o.Immediate() {
+		return errors.New("post-only order must be GTC limit order")
+	}
+	return nil
+}
+
+func (k Kind) String() string {
+	switch k {
+	case Limit:
+		return "limit"
+	case Market:
+		return "market"
+	default:
+		return ""
+	}
+}
+
+// KindFromStr parses the order kind, empty string means limit.
+func KindFromStr(k string) (Kind, error) {
+	switch k {
+	case "", "limit":
+		return Limit, nil
+	case "market":
+		return Market, nil
+	default:
+		return 0, fmt.Errorf("unknow order kind:%s", k)
+	}
+}
+
+func (tif TimeInForce) String() string {
+	switch tif {
+	case GTC:
+		return "GTC"
+	case IOC:
+		return "IOC"
+	case FOK:
+		return "FOK"
+	default:
+		return ""
+	}
+}
+
+// TimeInForceFromStr parses the time in force, empty string means GTC.
+func TimeInForceFromStr(tif string) (TimeInForce, error) {
+	switch strings.ToUpper(tif) {
+	case "", "GTC":
+		return GTC, nil
+	case "IOC":
+		return IOC, nil
+	case "FOK":
+		return FOK, nil
+	default:
+		return 0, fmt.Errorf("unknow time in force:%s", tif)
+	}
+}
+
 func TypeFromStr(tp string) (Type, error) {
 	switch tp {
 	case "bid":
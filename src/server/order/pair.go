@@ -0,0 +1,107 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+// Pair is the trading rules of one coin pair, the books are created for the
+// configured pairs when server starts.
+type Pair struct {
+	Name     string `json:"name"`      // coin pair, main coin and sub coin joined with `/`, like bitcoin/skycoin.
+	TickSize uint64 `json:"tick_size"` // price must be a multiple of tick size.
+	LotSize  uint64 `json:"lot_size"`  // amount must be a multiple of lot size.
+	MinOrder uint64 `json:"min_order"` // minimum amount of an order.
+	Enabled  bool   `json:"enabled"`   // new orders are rejected if the pair is disabled.
+	FeeSchedule
+}
+
+// DefaultPairs is used when no pair is configured.
+var DefaultPairs = []Pair{
+	{Name: "bitcoin/skycoin", TickSize: 1, LotSize: 1, MinOrder: 1, Enabled: true},
+}
+
+// ErrPairDisabled is returned when creating order in disabled coin pair.
+var ErrPairDisabled = errors.New("coin pair is disabled")
+
+// Coins returns the main coin and sub coin of the pair.
+func (p Pair) Coins() (string, string) {
+	ct := strings.Split(p.Name, "/")
+	return ct[0], ct[1]
+}
+
+// Validate checks the settings of the pair.
+func (p Pair) Validate() error {
+	ct := strings.Split(p.Name, "/")
+	if len(ct) != 2 || ct[0] == "" || ct[1] == "" || ct[0] == ct[1] {
+		return fmt.Errorf("error coin pair: %s", p.Name)
+	}
+
+	if p.TickSize == 0 || p.LotSize == 0 {
+		return fmt.Errorf("%s tick size and lot size must be greater than 0", p.Name)
+	}
+
+	if err := p.FeeSchedule.Validate(); err != nil {
+		return fmt.Errorf("%s %v", p.Name, err)
+	}
+	return nil
+}
+
+// CheckOrder checks if the order's price and amount conform to the pair's rules.
+func (p Pair) CheckOrder(od Order) error {
+	if !p.Enabled {
+		return ErrPairDisabled
+	}
+
+	// market order has no price.
+	if od.Kind == Limit && (od.Price == 0 || od.Price%p.TickSize != 0) {
+		return fmt.Errorf("price must be a multiple of tick size %d", p.TickSize)
+	}
+
+	if od.Amount == 0 || od.Amount%p.LotSize != 0 {
+		return fmt.Errorf("amount must be a multiple of lot size %d", p.LotSize)
+	}
+
+	if od.Amount < p.MinOrder {
+		return fmt.Errorf("amount must not be less than %d", p.MinOrder)
+	}
+
+	// the escrow and the fee are calculated on price*amount.
+	if _, err := Volume(od.Price, od.Amount); err != nil {
+		return err
+	}
+	return nil
+}
+
+// LoadPairs loads the pairs from json file, the pairs are validated,
+// and each coin pair can only be configured once.
+func LoadPairs(path string) ([]Pair, error) {
+	pairs := []Pair{}
+	if err := file.LoadJSON(path, &pairs); err != nil {
+		return nil, err
+	}
+
+	if err := ValidatePairs(pairs); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// ValidatePairs validates every pair, and checks there're no duplicate pairs.
+func ValidatePairs(pairs []Pair) error {
+	names := make(map[string]bool, len(pairs))
+	for _, p := range pairs {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+
+		if names[p.Name] {
+			return fmt.Errorf("duplicate coin pair: %s", p.Name)
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package order
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPairCheckOrder(t *testing.T) {
+	p := Pair{Name: "bitcoin/skycoin", TickSize: 5, LotSize: 2, MinOrder: 4, Enabled: true}
+	assert.Nil(t, p.CheckOrder(Order{Price: 10, Amount: 4}))
+	assert.NotNil(t, p.CheckOrder(Order{Price: 11, Amount: 4}))
+	assert.NotNil(t, p.CheckOrder(Order{Price: 10, Amount: 5}))
+	assert.NotNil(t, p.CheckOrder(Order{Price: 10, Amount: 2}))
+	assert.NotNil(t, p.CheckOrder(Order{Price: 0, Amount: 4}))
+
+	// price*amount overflows.
+	assert.Equal(t, ErrOverflow, p.CheckOrder(Order{Price: 5 << 61, Amount: 4}))
+	assert.Equal(t, ErrOverflow, p.CheckOrder(Order{Price: 5 << 58, Amount: 8}))
+	assert.Equal(t, ErrOverflow, p.CheckOrder(Order{Kind: Market, Amount: 1 << 63}))
+
+	p.Enabled = false
+	assert.Equal(t, ErrPairDisabled, p.CheckOrder(Order{Price: 10, Amount: 4}))
+}
+
+func TestLoadPairs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-pairs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "pairs.json")
+	for _, c := range []struct {
+		data string
+		ok   bool
+	}{
+		{`[{"name":"bitcoin/skycoin","tick_size":1,"lot_size":1,"min_order":1,"enabled":true},
+		   {"name":"skycoin/mzcoin","tick_size":10,"lot_size":1000,"min_order":1000,"maker_fee":10,"taker_fee":20,
+		    "fee_tiers":[{"volume":100,"maker_fee":5,"taker_fee":15}]}]`, true},
+		{`[{"name":"bitcoin","tick_size":1,"lot_size":1}]`, false},
+		{`[{"name":"skycoin/skycoin","tick_size":1,"lot_size":1}]`, false},
+		{`[{"name":"bitcoin/skycoin","tick_size":0,"lot_size":1}]`, false},
+		{`[{"name":"bitcoin/skycoin","tick_size":1,"lot_size":1},
+		   {"name":"bitcoin/skycoin","tick_size":1,"lot_size":1}]`, false},
+		{`[{"name":"bitcoin/skycoin","tick_size":1,"lot_size":1,"taker_fee":10001}]`, false},
+		{`[{"name":"bitcoin/skycoin","tick_size":1,"lot_size":1,"maker_fee":10,"taker_fee":20,
+		    "fee_tiers":[{"volume":100,"maker_fee":5,"taker_fee":15},{"volume":50}]}]`, false},
+	} {
+		assert.Nil(t, ioutil.WriteFile(path, []byte(c.data), 0600))
+		pairs, err := LoadPairs(path)
+		assert.Equal(t, c.ok, err == nil, c.data)
+		if c.ok {
+			assert.Equal(t, 2, len(pairs))
+			fs := FeeSchedule{MakerFee: 10, TakerFee: 20, FeeTiers: []FeeTier{{Volume: 100, MakerFee: 5, TakerFee: 15}}}
+			assert.Equal(t, Pair{Name: "skycoin/mzcoin", TickSize: 10, LotSize: 1000, MinOrder: 1000, FeeSchedule: fs}, pairs[1])
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package order
+
+import "time"
+
+// Trade records one execution between a bid and an ask order.
+type Trade struct {
+	ID           uint64 `json:"id"`             // trade id, assigned when the trade is recorded in history.
+	MakerID      uint64 `json:"maker_id"`       // id of the order that was resting in the book first.
+	TakerID      uint64 `json:"taker_id"`       // id of the order that came later.
+	TakerType    Type   `json:"taker_type"`     // type of the taker order, bid or ask.
+	BidAccountID string `json:"bid_account_id"` // account id of the bid order.
+	AskAccountID string `json:"ask_account_id"` // account id of the ask order.
+	BidPrice     uint64 `json:"bid_price"`      // limit price of the bid order.
+	Price        uint64 `json:"price"`          // execution price, it's the maker's price.
+	Amount       uint64 `json:"amount"`         // executed quantity.
+	BidFee       uint64 `json:"bid_fee"`        // fee charged to the bidder, in main coin.
+	AskFee       uint64 `json:"ask_fee"`        // fee charged to the asker, in sub coin.
+	CreatedAt    int64  `json:"created_at"`     // execution time.
+}
+
+// TickerPeriod is the period that the high, low and volume of ticker are calculated over.
+const TickerPeriod = 24 * time.Hour
+
+// Ticker is the market summary of one coin pair.
+type Ticker struct {
+	Last    uint64 `json:"last"`     // price of the latest trade.
+	High    uint64 `json:"high"`     // highest trade price in the ticker period.
+	Low     uint64 `json:"low"`      // lowest trade price in the ticker period.
+	Volume  uint64 `json:"volume"`   // traded amount of the main coin in the ticker period.
+	BestBid uint64 `json:"best_bid"` // highest bid price in book, 0 if there's no bid.
+	BestAsk uint64 `json:"best_ask"` // lowest ask price in book, 0 if there's no ask.
+}
+
+// newTrade creates trade of the bid and ask order, the maker is the earlier one,
+// and the trade is executed at the maker's price.
+func newTrade(bid, ask Order, amt uint64) Trade {
+	t := Trade{
+		BidAccountID: bid.AccountID,
+		AskAccountID: ask.AccountID,
+		BidPrice:     bid.Price,
+		Amount:       amt,
+		CreatedAt:    time.Now().Unix(),
+	}
+
+	if isEarlier(bid, ask) {
+		t.MakerID, t.TakerID, t.TakerType, t.Price = bid.ID, ask.ID, Ask, bid.Price
+	} else {
+		t.MakerID, t.TakerID, t.TakerType, t.Price = ask.ID, bid.ID, Bid, ask.Price
+	}
+	return t
+}
+
+// BidID returns the id of the bid order in this trade.
+func (t Trade) BidID() uint64 {
+	if t.TakerType == Bid {
+		return t.TakerID
+	}
+	return t.MakerID
+}
+
+// AskID returns the id of the ask order in this trade.
+func (t Trade) AskID() uint64 {
+	if t.TakerType == Ask {
+		return t.TakerID
+	}
+	return t.MakerID
+}
+
+// isEarlier checks if order a was created before order b, order id breaks the tie.
+func isEarlier(a, b Order) bool {
+	if a.CreatedAt != b.CreatedAt {
+		return a.CreatedAt < b.CreatedAt
+	}
+	return a.ID < b.ID
+}
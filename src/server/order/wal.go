@@ -0,0 +1,380 @@
+package order
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+)
+
+// EventType is the type of order book event.
+type EventType uint8
+
+const (
+	EventAdd    EventType = iota // order was added to book.
+	EventCancel                  // order was removed from book by its owner.
+	EventTrade                   // trade was executed between bid and ask.
+)
+
+var snapshotBkt = "book_snapshots" // coin pair -> seq of the last event included in the snapshot.
+
+// Event records one change of the order book, the events after the last snapshot
+// are replayed on top of the snapshot when loading the book.
+type Event struct {
+	Seq   uint64    `json:"seq"`
+	Type  EventType `json:"type"`
+	Order *Order    `json:"order,omitempty"` // the added or cancelled order.
+	Trade *Trade    `json:"trade,omitempty"` // the executed trade.
+}
+
+// bookLog is the write-ahead log of one order book, the events are written
+// into store in the same transaction as the other changes of the request.
+type bookLog struct {
+	seq        uint64           // seq of the last event.
+	snapSeq    uint64           // seq of the last event included in snapshot.
+	pending    []Event          // events not written into store yet.
+	dirty      map[uint64]bool  // ids of the orders changed since the last snapshot.
+	unsettled  map[uint64]Trade // executed trades that are not settled yet.
+	settled    []uint64         // settled trades that are not removed from store yet.
+	unrecorded map[uint64]Trade // executed trades that are not appended to the history yet.
+	recorded   []uint64         // recorded trades that are not removed from store yet.
+	tradeID    uint64           // id of the last executed trade.
+}
+
+func newBookLog() *bookLog {
+	return &bookLog{
+		dirty:      make(map[uint64]bool),
+		unsettled:  make(map[uint64]Trade),
+		unrecorded: make(map[uint64]Trade),
+	}
+}
+
+// eventBkt returns the bucket name of the events of specific coin pair.
+func eventBkt(cp string) string {
+	return "events:" + cp
+}
+
+// tradeBkt returns the bucket name of the unsettled trades of specific coin pair.
+func tradeBkt(cp string) string {
+	return "trades:" + cp
+}
+
+// unrecordedBkt returns the bucket name of the committed trades of specific coin pair that are
+// not appended to the trade history yet, they're appended again after restart.
+func unrecordedBkt(cp string) string {
+	return "unrecorded_trades:" + cp
+}
+
+// append records the event, and the orders it changed.
+func (l *bookLog) append(ev Event) {
+	l.seq++
+	ev.Seq = l.seq
+	l.pending = append(l.pending, ev)
+	for _, id := range ev.orderIDs() {
+		l.dirty[id] = true
+	}
+}
+
+func (ev Event) orderIDs() []uint64 {
+	switch ev.Type {
+	case EventAdd, EventCancel:
+		return []uint64{ev.Order.ID}
+	case EventTrade:
+		return []uint64{ev.Trade.BidID(), ev.Trade.AskID()}
+	}
+	return nil
+}
+
+// apply replays the event on the book.
+func (bk *Book) apply(ev Event) {
+	switch ev.Type {
+	case EventAdd:
+		switch ev.Order.Type {
+		case Bid:
+			bk.AddBid(*ev.Order)
+		case Ask:
+			bk.AddAsk(*ev.Order)
+		}
+	case EventCancel:
+		bk.RemoveOrder(ev.Order.ID, ev.Order.AccountID)
+	case EventTrade:
+		bk.fill(Bid, ev.Trade.BidID(), ev.Trade.Amount)
+		bk.fill(Ask, ev.Trade.AskID(), ev.Trade.Amount)
+	}
+}
+
+// SetSnapshot sets how often the order books are snapshotted, the snapshot is written
+// once interval events were appended since the last one, 0 means snapshot in every flush.
+// If compact is true, the events included in the snapshot will be deleted.
+func (m *Manager) SetSnapshot(interval uint64, compact bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.snapInterval = interval
+	m.compact = compact
+}
+
+// logEvent appends the event to the log of specific coin pair.
+func (m *Manager) logEvent(cp string, ev Event) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.logs[cp].append(ev)
+}
+
+// logTrades assigns the trade ids, and records the trades as unsettled and unrecorded.
+func (m *Manager) logTrades(cp string, trades []Trade) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	l := m.logs[cp]
+	for i := range trades {
+		l.tradeID++
+		trades[i].ID = l.tradeID
+		t := trades[i]
+		l.append(Event{Type: EventTrade, Trade: &t})
+		l.unsettled[t.ID] = t
+		l.unrecorded[t.ID] = t
+	}
+}
+
+// SettleTrade marks the trade as settled, it will be removed from the unsettled
+// trades in store in next Flush.
+func (m *Manager) SettleTrade(cp string, id uint64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	l, ok := m.logs[cp]
+	if !ok {
+		return
+	}
+	if _, ok := l.unsettled[id]; !ok {
+		return
+	}
+	delete(l.unsettled, id)
+	l.settled = append(l.settled, id)
+}
+
+// GetUnsettledTrades returns the executed trades that are not settled yet, ordered by trade id,
+// these trades must be settled after restart.
+func (m *Manager) GetUnsettledTrades(cp string) []Trade {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	l, ok := m.logs[cp]
+	if !ok {
+		return []Trade{}
+	}
+
+	trades := make([]Trade, 0, len(l.unsettled))
+	for _, t := range l.unsettled {
+		trades = append(trades, t)
+	}
+	sort.Slice(trades, func(i, j int) bool { return trades[i].ID < trades[j].ID })
+	return trades
+}
+
+// getUnrecordedTrades returns the executed trades that are not appended to the history yet, ordered by trade id.
+func (m *Manager) getUnrecordedTrades(cp string) []Trade {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	l, ok := m.logs[cp]
+	if !ok {
+		return []Trade{}
+	}
+
+	trades := make([]Trade, 0, len(l.unrecorded))
+	for _, t := range l.unrecorded {
+		trades = append(trades, t)
+	}
+	sort.Slice(trades, func(i, j int) bool { return trades[i].ID < trades[j].ID })
+	return trades
+}
+
+// recordTrade marks the trade as appended to the history, it will be removed from the
+// unrecorded trades in store in next Flush.
+func (m *Manager) recordTrade(cp string, id uint64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	l, ok := m.logs[cp]
+	if !ok {
+		return
+	}
+	if _, ok := l.unrecorded[id]; !ok {
+		return
+	}
+	delete(l.unrecorded, id)
+	l.recorded = append(l.recorded, id)
+}
+
+// Flush writes the new events, the unsettled trades, the conditional orders, the candles and the last order ids into the transaction,
+// the snapshot of the book is written if there're enough events since the last one.
+func (m *Manager) Flush(tx storage.Tx) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for cp, l := range m.logs {
+		if err := m.flushLog(tx, cp, l, false); err != nil {
+			return err
+		}
+	}
+
+	for cp, cb := range m.conds {
+		if err := cb.flush(tx, cp); err != nil {
+			return err
+		}
+	}
+
+	for cp, cb := range m.candles {
+		if err := cb.flush(tx, cp); err != nil {
+			return err
+		}
+	}
+
+	for _, g := range m.idg {
+		if err := g.flush(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) flushLog(tx storage.Tx, cp string, l *bookLog, forceSnap bool) error {
+	for _, ev := range l.pending {
+		if err := storage.PutJSON(tx, eventBkt(cp), storage.Itob(ev.Seq), ev); err != nil {
+			return err
+		}
+
+		if ev.Type == EventTrade {
+			if err := storage.PutJSON(tx, tradeBkt(cp), storage.Itob(ev.Trade.ID), ev.Trade); err != nil {
+				return err
+			}
+			if err := storage.PutJSON(tx, unrecordedBkt(cp), storage.Itob(ev.Trade.ID), ev.Trade); err != nil {
+				return err
+			}
+		}
+	}
+	l.pending = nil
+
+	for _, id := range l.settled {
+		if err := tx.Delete(tradeBkt(cp), storage.Itob(id)); err != nil {
+			return err
+		}
+	}
+	l.settled = nil
+
+	for _, id := range l.recorded {
+		if err := tx.Delete(unrecordedBkt(cp), storage.Itob(id)); err != nil {
+			return err
+		}
+	}
+	l.recorded = nil
+
+	if forceSnap || (l.seq > l.snapSeq && l.seq-l.snapSeq >= m.snapInterval) {
+		return m.snapshot(tx, cp, l)
+	}
+	return nil
+}
+
+// snapshot writes the orders changed since the last snapshot, the orders
+// that are no longer in the book are deleted.
+func (m *Manager) snapshot(tx storage.Tx, cp string, l *bookLog) error {
+	bk := m.books[cp]
+	for id := range l.dirty {
+		od, ok := bk.getOrder(id)
+		if !ok {
+			if err := tx.Delete(bookBkt(cp), storage.Itob(id)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := storage.PutJSON(tx, bookBkt(cp), storage.Itob(id), od); err != nil {
+			return err
+		}
+	}
+	l.dirty = make(map[uint64]bool)
+	l.snapSeq = l.seq
+	if err := tx.Put(snapshotBkt, []byte(cp), storage.Itob(l.snapSeq)); err != nil {
+		return err
+	}
+
+	if !m.compact {
+		return nil
+	}
+
+	// delete the events that are included in the snapshot.
+	seqs := [][]byte{}
+	if err := tx.ForEach(eventBkt(cp), func(k, v []byte) error {
+		if storage.Btoi(k) <= l.snapSeq {
+			seqs = append(seqs, k)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, k := range seqs {
+		if err := tx.Delete(eventBkt(cp), k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadLog replays the events after the last snapshot on the book, and loads the unsettled and unrecorded trades.
+func loadLog(tx storage.Tx, cp string, bk *Book) (*bookLog, error) {
+	l := newBookLog()
+	if v := tx.Get(snapshotBkt, []byte(cp)); v != nil {
+		l.snapSeq = storage.Btoi(v)
+	}
+	l.seq = l.snapSeq
+
+	err := tx.ForEach(eventBkt(cp), func(k, v []byte) error {
+		if storage.Btoi(k) <= l.snapSeq {
+			return nil
+		}
+
+		ev := Event{}
+		if err := json.Unmarshal(v, &ev); err != nil {
+			return err
+		}
+		bk.apply(ev)
+		l.seq = ev.Seq
+		for _, id := range ev.orderIDs() {
+			l.dirty[id] = true
+		}
+		if ev.Type == EventTrade && ev.Trade.ID > l.tradeID {
+			l.tradeID = ev.Trade.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.ForEach(tradeBkt(cp), func(k, v []byte) error {
+		t := Trade{}
+		if err := json.Unmarshal(v, &t); err != nil {
+			return err
+		}
+		l.unsettled[t.ID] = t
+		if t.ID > l.tradeID {
+			l.tradeID = t.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.ForEach(unrecordedBkt(cp), func(k, v []byte) error {
+		t := Trade{}
+		if err := json.Unmarshal(v, &t); err != nil {
+			return err
+		}
+		l.unrecorded[t.ID] = t
+		if t.ID > l.tradeID {
+			l.tradeID = t.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
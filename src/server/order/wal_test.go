@@ -0,0 +1,293 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var errKilled = errors.New("killed")
+
+// killStore kills the process after n writes in the transaction,
+// so none of the writes will be committed.
+type killStore struct {
+	storage.Store
+	n int
+}
+
+type killTx struct {
+	storage.Tx
+	n *int
+}
+
+func (s *killStore) Update(fn func(tx storage.Tx) error) error {
+	return s.Store.Update(func(tx storage.Tx) error {
+		return fn(&killTx{Tx: tx, n: &s.n})
+	})
+}
+
+func (t *killTx) Put(bucket string, key []byte, value []byte) error {
+	if *t.n == 0 {
+		return errKilled
+	}
+	*t.n--
+	return t.Tx.Put(bucket, key, value)
+}
+
+func (t *killTx) Delete(bucket string, key []byte) error {
+	if *t.n == 0 {
+		return errKilled
+	}
+	*t.n--
+	return t.Tx.Delete(bucket, key)
+}
+
+var walCoinPair = "bitcoin/skycoin"
+
+// walSteps changes the book step by step, each step is committed by one commit.
+var walSteps = []func(m *Manager){
+	func(m *Manager) {
+		m.place(walCoinPair, Order{AccountID: "a", Type: Bid, Price: 100, Amount: 3, RestAmt: 3, CreatedAt: 1})
+	},
+	func(m *Manager) {
+		m.place(walCoinPair, Order{AccountID: "b", Type: Ask, Price: 105, Amount: 2, RestAmt: 2, CreatedAt: 2})
+	},
+	func(m *Manager) {
+		m.place(walCoinPair, Order{AccountID: "b", Type: Ask, Price: 99, Amount: 2, RestAmt: 2, CreatedAt: 3})
+	},
+	func(m *Manager) {
+		m.place(walCoinPair, Order{AccountID: "c", Type: Bid, Price: 106, Amount: 4, RestAmt: 4, CreatedAt: 4})
+	},
+	func(m *Manager) {
+		m.cancel(walCoinPair, 4, "c")
+	},
+	func(m *Manager) {
+		m.place(walCoinPair, Order{AccountID: "a", Type: Ask, Price: 100, Amount: 1, RestAmt: 1, CreatedAt: 5})
+	},
+	func(m *Manager) {
+		m.place(walCoinPair, Order{AccountID: "a", Type: Ask, Price: 103, Amount: 1, RestAmt: 1, CreatedAt: 6})
+	},
+	func(m *Manager) {
+		m.place(walCoinPair, Order{AccountID: "d", Type: Bid, Price: 104, Amount: 3, RestAmt: 3, CreatedAt: 7, TimeInForce: IOC})
+	},
+}
+
+type bookState struct {
+	Bids      []Order
+	Asks      []Order
+	Unsettled []Trade
+}
+
+func getBookState(m *Manager) bookState {
+	bk := m.GetBook(walCoinPair)
+	return bookState{
+		Bids:      bk.GetOrders(Bid, 0, math.MaxInt64),
+		Asks:      bk.GetOrders(Ask, 0, math.MaxInt64),
+		Unsettled: m.GetUnsettledTrades(walCoinPair),
+	}
+}
+
+// allTrades returns the trades in history and the ones waiting to be recorded, ordered by trade id.
+func allTrades(m *Manager) []Trade {
+	trades := []Trade{}
+	recorded := m.histories[walCoinPair].GetTrades(0, math.MaxInt64)
+	for i := len(recorded) - 1; i >= 0; i-- {
+		trades = append(trades, recorded[i])
+	}
+	return append(trades, m.getUnrecordedTrades(walCoinPair)...)
+}
+
+// registerStore registers the committer that flushes the manager into store,
+// the returned error of the commit is set into err if it's not nil.
+func registerStore(m *Manager, s storage.Store, err *error) {
+	m.RegisterCommitter(func(fn func() error) error {
+		if e := fn(); e != nil {
+			return e
+		}
+		e := s.Update(m.Flush)
+		if err != nil {
+			*err = e
+		}
+		return e
+	})
+}
+
+// newWalManager creates manager with empty book, and runs the steps.
+func newWalManager(t *testing.T, s storage.Store, dir string, steps int) *Manager {
+	InitTradeDir(dir)
+	m := NewManager()
+	assert.Nil(t, m.AddBook(walCoinPair, &Book{}))
+	assert.Nil(t, s.Update(m.Flush))
+	registerStore(m, s, nil)
+	for i := 0; i < steps; i++ {
+		walSteps[i](m)
+	}
+	return m
+}
+
+func testKillAtEveryStep(t *testing.T, interval uint64, compact bool) {
+	dir, err := ioutil.TempDir("", "exchange-wal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for step := range walSteps {
+		for n := 0; ; n++ {
+			tdir := filepath.Join(dir, fmt.Sprintf("%d-%d", step, n))
+			s := storage.NewMemStore()
+			m := newWalManager(t, s, tdir, step)
+			m.SetSnapshot(interval, compact)
+			before := getBookState(m)
+			trades := allTrades(m)
+
+			// kill the process after n writes of the step's commit.
+			var err error
+			registerStore(m, &killStore{Store: s, n: n}, &err)
+			walSteps[step](m)
+
+			m1, lerr := LoadManager(s, nil)
+			assert.Nil(t, lerr)
+			if err != nil {
+				assert.Equal(t, errKilled, err)
+				assert.Equal(t, before, getBookState(m1), "step:%d writes:%d", step, n)
+				assert.Equal(t, trades, allTrades(m1), "step:%d writes:%d", step, n)
+				continue
+			}
+
+			// the commit is completed.
+			assert.Equal(t, getBookState(m), getBookState(m1), "step:%d", step)
+			assert.Equal(t, allTrades(m), allTrades(m1), "step:%d", step)
+			break
+		}
+
+		// kill the process after the commit, before the trades are appended to history.
+		tdir := filepath.Join(dir, fmt.Sprintf("%d-history", step))
+		s := storage.NewMemStore()
+		m := newWalManager(t, s, tdir, step)
+		m.SetSnapshot(interval, compact)
+		path := m.histories[walCoinPair].path
+		m.histories[walCoinPair].path = filepath.Join(tdir, "killed", "trades")
+		walSteps[step](m)
+		m.histories[walCoinPair].path = path
+
+		m1, err := LoadManager(s, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, getBookState(m), getBookState(m1), "step:%d", step)
+		assert.Equal(t, allTrades(m), allTrades(m1), "step:%d", step)
+		assert.Equal(t, 0, len(m1.getUnrecordedTrades(walCoinPair)))
+
+		// the recorded trades are removed from store in next commit.
+		assert.Nil(t, s.Update(m1.Flush))
+		assert.Equal(t, 0, countKeys(s, unrecordedBkt(walCoinPair)))
+	}
+}
+
+// countKeys returns the number of keys in the bucket.
+func countKeys(s storage.Store, bkt string) int {
+	n := 0
+	s.View(func(tx storage.Tx) error {
+		return tx.ForEach(bkt, func(k, v []byte) error {
+			n++
+			return nil
+		})
+	})
+	return n
+}
+
+func TestKillAtEveryStep(t *testing.T) {
+	testKillAtEveryStep(t, 0, true)
+	testKillAtEveryStep(t, 2, true)
+	testKillAtEveryStep(t, 100, false)
+}
+
+func TestReplayEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-wal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, c := range []struct {
+		interval uint64
+		compact  bool
+	}{
+		{0, false},
+		{3, true},
+		{3, false},
+		{100, true},
+	} {
+		tdir := filepath.Join(dir, fmt.Sprintf("%d-%v", c.interval, c.compact))
+		InitTradeDir(tdir)
+		s := storage.NewMemStore()
+		m := NewManager()
+		m.SetSnapshot(c.interval, c.compact)
+		assert.Nil(t, m.AddBook(walCoinPair, &Book{}))
+		assert.Nil(t, s.Update(m.Flush))
+		registerStore(m, s, nil)
+
+		for i, step := range walSteps {
+			step(m)
+
+			// restart after every step.
+			m1, err := LoadManager(s, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, getBookState(m), getBookState(m1), "step:%d interval:%d", i, c.interval)
+		}
+
+		// the ids continue after restart.
+		m1, err := LoadManager(s, nil)
+		assert.Nil(t, err)
+		id, err := m1.NewOrderID(walCoinPair)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(8), id)
+		assert.Equal(t, uint64(4), m1.logs[walCoinPair].tradeID)
+
+		// count the events left in store.
+		events := countKeys(s, eventBkt(walCoinPair))
+		l := m.logs[walCoinPair]
+		if c.compact {
+			assert.Equal(t, int(l.seq-l.snapSeq), events)
+		} else {
+			assert.Equal(t, int(l.seq), events)
+		}
+	}
+}
+
+// TestRecordUnsettledTrades checks the trades that were committed but not
+// recorded in history before crash are recorded after restart.
+func TestRecordUnsettledTrades(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-wal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	InitTradeDir(dir)
+
+	s := storage.NewMemStore()
+	m := newWalManager(t, s, dir, 2)
+
+	// crash before the trade is settled and appended to history.
+	id, err := m.NewOrderID(walCoinPair)
+	assert.Nil(t, err)
+	assert.Nil(t, m.commit(func() error {
+		m.addOrder(walCoinPair, Order{ID: id, AccountID: "b", Type: Ask, Price: 99, Amount: 2, RestAmt: 2, CreatedAt: 3})
+		m.logTrades(walCoinPair, m.books[walCoinPair].Match())
+		return nil
+	}))
+	assert.Equal(t, 0, len(m.histories[walCoinPair].GetTrades(0, 10)))
+
+	m1, err := LoadManager(s, nil)
+	assert.Nil(t, err)
+	trades := m1.GetUnsettledTrades(walCoinPair)
+	assert.Equal(t, 1, len(trades))
+	assert.Equal(t, trades, m1.histories[walCoinPair].GetTrades(0, 10))
+
+	// the trade id continues.
+	_, err = m1.place(walCoinPair, Order{AccountID: "c", Type: Bid, Price: 106, Amount: 4, RestAmt: 4, CreatedAt: 4})
+	assert.Nil(t, err)
+	trades = m1.histories[walCoinPair].GetTrades(0, 10)
+	assert.Equal(t, 2, len(trades))
+	assert.Equal(t, uint64(2), trades[0].ID)
+}
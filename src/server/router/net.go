@@ -6,19 +6,34 @@ import (
 	"github.com/skycoin/skycoin-exchange/src/sknet"
 )
 
-// New create sknet engine and register handlers.
-func New(ee engine.Exchange, quit chan bool) *sknet.Engine {
-	engine := sknet.New(ee.GetSecKey(), quit)
+// New create sknet engine with the options and register handlers, the requests are
+// rate limited by the quotas of their paths.
+func New(ee engine.Exchange, quit chan bool, opts sknet.Options, quotas map[string]sknet.Quota) *sknet.Engine {
+	engine := sknet.NewWithOptions(ee.GetSecKey(), quit, opts)
 	engine.Use(sknet.Logger())
+	engine.Use(sknet.RateLimit(quotas))
 
 	engine.Register("/create/account", api.CreateAccount(ee))
 	engine.Register("/create/deposit_address", api.GetNewAddress(ee))
 	engine.Register("/get/account/balance", api.GetAccountBalance(ee))
+	engine.Register("/get/account/history", api.GetAccountHistory(ee))
 	engine.Register("/get/address/balance", api.GetAddrBalance(ee))
 	engine.Register("/withdrawl", api.Withdraw(ee))
+	engine.Register("/get/withdraw/policy", api.GetWithdrawPolicy(ee))
 	engine.Register("/create/order", api.CreateOrder(ee))
+	engine.Register("/cancel/order", api.CancelOrder(ee))
+	engine.Register("/create/conditional_order", api.CreateConditionalOrder(ee))
+	engine.Register("/cancel/conditional_order", api.CancelConditionalOrder(ee))
+	engine.Register("/get/account/conditional_orders", api.GetConditionalOrders(ee))
 	engine.Register("/get/coins", api.GetCoins(ee))
+	engine.Register("/get/pairs", api.GetPairs(ee))
 	engine.Register("/get/orders", api.GetOrders(ee))
+	engine.Register("/get/depth", api.GetDepth(ee))
+	engine.Register("/get/ticker", api.GetTicker(ee))
+	engine.Register("/get/candles", api.GetCandles(ee))
+	engine.Register("/get/trades", api.GetTrades(ee))
+	engine.Register("/get/account/fills", api.GetAccountFills(ee))
+	engine.Register("/subscribe", api.Subscribe(ee))
 
 	// utxos handler
 	engine.Register("/get/utxos", api.GetUtxos(ee))
@@ -31,7 +46,10 @@ func New(ee engine.Exchange, quit chan bool) *sknet.Engine {
 	engine.Register("/get/tx", api.GetTx(ee))
 	engine.Register("/get/rawtx", api.GetRawTx(ee))
 
-	engine.Register("/admin/update/credit", api.UpdateCredit(ee))
+	// admin handlers, the request must be sent by admin.
+	admin := engine.Group("/admin", api.IsAdmin(ee))
+	admin.Register("/update/credit", api.UpdateCredit(ee))
+	admin.Register("/get/fees", api.GetFees(ee))
 
 	return engine
 }
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+// DefaultQuotas limits the requests that create orders, accounts, deposit addresses and withdrawals,
+// each deposit address is watched by the utxo manager of its coin.
+var DefaultQuotas = map[string]sknet.Quota{
+	"/create/account":           {IPRate: 0.1, IPBurst: 10},
+	"/create/deposit_address":   {Rate: 1.0 / 60, Burst: 5, IPRate: 0.1, IPBurst: 20},
+	"/create/order":             {Rate: 10, Burst: 20, IPRate: 50, IPBurst: 100},
+	"/create/conditional_order": {Rate: 10, Burst: 20, IPRate: 50, IPBurst: 100},
+	"/withdrawl":                {Rate: 0.1, Burst: 5, IPRate: 1, IPBurst: 20},
+}
+
+// LoadQuotas loads the quotas from json file, which is a map of request path to quota.
+func LoadQuotas(path string) (map[string]sknet.Quota, error) {
+	qs := make(map[string]sknet.Quota)
+	if err := file.LoadJSON(path, &qs); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateQuotas(qs); err != nil {
+		return nil, err
+	}
+	return qs, nil
+}
+
+// ValidateQuotas validates the quota of each path.
+func ValidateQuotas(qs map[string]sknet.Quota) error {
+	for p, q := range qs {
+		if err := q.Validate(); err != nil {
+			return fmt.Errorf("%s quota: %v", p, err)
+		}
+	}
+	return nil
+}
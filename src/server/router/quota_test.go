@@ -0,0 +1,40 @@
+package router
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadQuotas(t *testing.T) {
+	assert.Nil(t, ValidateQuotas(DefaultQuotas))
+
+	dir, err := ioutil.TempDir("", "exchange-quotas")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "quotas.json")
+	for _, c := range []struct {
+		data string
+		ok   bool
+	}{
+		{`{"/create/order": {"rate": 5, "burst": 10, "ip_rate": 20, "ip_burst": 50},
+		   "/create/account": {"ip_rate": 0.5, "ip_burst": 5}}`, true},
+		{`{"/create/order": {"rate": -1}}`, false},
+		{`{"/create/order": {"rate": 5}}`, false},
+		{`{"/create/order": {"ip_rate": 5, "ip_burst": 0}}`, false},
+		{`["/create/order"]`, false},
+	} {
+		assert.Nil(t, ioutil.WriteFile(path, []byte(c.data), 0600))
+		qs, err := LoadQuotas(path)
+		assert.Equal(t, c.ok, err == nil, c.data)
+		if c.ok {
+			assert.Equal(t, 2, len(qs))
+			assert.Equal(t, sknet.Quota{Rate: 5, Burst: 10, IPRate: 20, IPBurst: 50}, qs["/create/order"])
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"context"
 	"errors"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -13,46 +17,69 @@ import (
 	logging "github.com/op/go-logging"
 	"github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
-	"github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/skycoin/skycoin-exchange/src/server/account"
 	"github.com/skycoin/skycoin-exchange/src/server/engine"
 	"github.com/skycoin/skycoin-exchange/src/server/order"
 	"github.com/skycoin/skycoin-exchange/src/server/router"
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
 	"github.com/skycoin/skycoin/src/util/file"
 )
 
 var logger = logging.MustGetLogger("exchange.server")
 
+var errServerStopped = errors.New("server stopped")
+
 // Config store server's configuration.
 type Config struct {
-	Server        string            // api server ip
-	Port          int               // api port
-	BtcFee        int               // btc transaction fee
-	DataDir       string            // data directory
-	Seed          string            // seed
-	Seckey        string            // server's private key
-	UtxoPoolSize  int               // utxo pool size.
-	Admins        string            // admins joined with `,`
-	NodeAddresses map[string]string // node address map
-	HTTPProf      bool
-}
-
-// NewConfig creates config instance and init nodeaddresses map.
+	Server           string                            // api server ip
+	Port             int                               // api port
+	BtcFee           int                               // btc transaction fee
+	DataDir          string                            // data directory
+	Seed             string                            // seed
+	Seckey           string                            // server's private key
+	Admins           string                            // admins joined with `,`
+	NodeAddresses    map[string]string                 // node address map
+	Confirms         map[string]uint64                 // required confirmations of deposits.
+	SnapInterval     uint64                            // number of order book events between snapshots.
+	CompactLog       bool                              // delete the order book events included in snapshot.
+	Pairs            []order.Pair                      // trading pairs, order.DefaultPairs is used if empty.
+	RebuildCandles   bool                              // rebuild the candles from trade history.
+	WithdrawPolicies map[string]account.WithdrawPolicy // withdrawal rules of coins, the bitcoin fee is BtcFee if not set.
+	Net              sknet.Options                     // workers, timeouts and limits of the api connections.
+	Quotas           map[string]sknet.Quota            // rate limits of the api paths, router.DefaultQuotas is used if nil.
+	HTTPProf         bool
+}
+
+// NewConfig creates config instance and init nodeaddresses and confirms map.
 func NewConfig() *Config {
-	return &Config{NodeAddresses: make(map[string]string)}
+	return &Config{
+		NodeAddresses:    make(map[string]string),
+		Confirms:         make(map[string]uint64),
+		WithdrawPolicies: make(map[string]account.WithdrawPolicy),
+		Net:              sknet.DefaultOptions,
+	}
 }
 
 // ExchangeServer provides services like account system, order book, api for differenct coins, etc.
 type ExchangeServer struct {
 	account.Manager
-	btcum         bitcoin.UtxoManager
-	skyum         skycoin.UtxoManager
-	orderManager  *order.Manager
-	cfg           Config
-	wallets       wallets
-	wltMtx        sync.RWMutex                // mutex for protecting the wallet.
-	orderHandlers map[string]chan order.Order // order handlers, for handleing bid and ask.
-	coins         map[string]coin.Gateway
+	orderManager *order.Manager
+	pairs        map[string]order.Pair             // trading rules of the configured coin pairs.
+	policies     map[string]account.WithdrawPolicy // withdrawal rules of coins.
+	store        storage.Store
+	commitMtx    sync.Mutex // mutex for committing the changes of one request together.
+	cfg          Config
+	wallets      wallets
+	wltMtx       sync.RWMutex // mutex for protecting the wallet.
+	coins        map[string]coin.Gateway
+	utxoMgrs     map[string]coin.UtxoManager // utxo managers of the bound coins.
+	hub          *sknet.Hub                  // pushes the committed changes to the subscribers.
+	api          *sknet.Engine               // the api server, created by Run.
+	quit         chan bool                   // closed by Shutdown to stop the goroutines started by Run.
+	running      sync.WaitGroup              // the goroutines started by Run.
+	stopped      bool                        // the store is closed, no more changes can be committed.
+	runMtx       sync.Mutex
 }
 
 // New create new server
@@ -66,112 +93,254 @@ func New(cfg *Config) engine.Exchange {
 	// init the order book dir.
 	order.InitDir(filepath.Join(path, "orderbook"))
 
-	var (
-		acntMgr account.Manager
-		err     error
-	)
+	// init the trade history dir.
+	order.InitTradeDir(filepath.Join(path, "trade"))
 
-	// load account manager if exist.
-	acntMgr, err = account.LoadManager()
+	// open the store of accounts and order books.
+	store, err := storage.NewBoltStore(filepath.Join(path, "exchange.db"))
 	if err != nil {
-		if os.IsNotExist(err) {
-			// create new account manager.
-			acntMgr = account.NewManager()
-		} else {
-			panic(err)
-		}
-	}
-
-	wltItems := []walletItem{
-		{bitcoin.Type, cfg.Seed},
-		{skycoin.Type, cfg.Seed},
+		panic(err)
 	}
 
-	// init wallets in server.
-	wlts, err := makeWallets(filepath.Join(path, "wallet"), wltItems)
+	// load account manager.
+	acntMgr, err := account.LoadManager(store)
 	if err != nil {
 		panic(err)
 	}
 
-	// create bitcoin utxo manager
-	btcWatchAddrs, err := wlts.GetAddresses(bitcoin.Type)
-	if err != nil {
+	// the balances must be consistent with the journal.
+	if err := acntMgr.CheckJournal(); err != nil {
 		panic(err)
 	}
-	btcum := bitcoin.NewUtxoManager(cfg.UtxoPoolSize, btcWatchAddrs)
 
-	// create skycoin utxo manager
-	skyWatchAddrs, err := wlts.GetAddresses(skycoin.Type)
-	if err != nil {
+	// init wallets in server, the wallets of coins are created when they're bound.
+	wlts := makeWallets(filepath.Join(path, "wallet"))
+
+	pairs := cfg.Pairs
+	if len(pairs) == 0 {
+		pairs = order.DefaultPairs
+	}
+	if err := order.ValidatePairs(pairs); err != nil {
 		panic(err)
 	}
-	skyum := skycoin.NewUtxoManager(cfg.NodeAddresses[skycoin.Type], cfg.UtxoPoolSize, skyWatchAddrs)
 
-	// load or create order books.
+	// load order books, and create the books of new configured pairs.
 	var orderManager *order.Manager
-	orderManager, err = order.LoadManager()
+	orderManager, err = loadOrderManager(store, acntMgr)
 	if err != nil {
-		if os.IsNotExist(err) {
-			orderManager = order.NewManager()
-			orderManager.AddBook("bitcoin/skycoin", &order.Book{})
-		} else {
+		if !os.IsNotExist(err) {
 			panic(err)
 		}
+		orderManager = order.NewManager()
+	}
+
+	pairMap := make(map[string]order.Pair, len(pairs))
+	for _, p := range pairs {
+		pairMap[p.Name] = p
+		orderManager.SetFeeSchedule(p.Name, p.FeeSchedule)
+		if !orderManager.IsExist(p.Name) {
+			if err := orderManager.AddBook(p.Name, &order.Book{}); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// the books of pairs removed from config are kept, so the orders in them can be cancelled.
+	for _, cp := range orderManager.GetCoinPairs() {
+		if _, ok := pairMap[cp]; !ok {
+			logger.Warning("coin pair %s is not configured, new orders will be rejected", cp)
+		}
+		for _, tp := range []order.Type{order.Bid, order.Ask} {
+			ct, err := escrowCoin(cp, tp)
+			if err != nil {
+				panic(err)
+			}
+			account.RegisterCoinType(ct)
+		}
+	}
+
+	orderManager.SetSnapshot(cfg.SnapInterval, cfg.CompactLog)
+
+	policies := make(map[string]account.WithdrawPolicy, len(cfg.WithdrawPolicies)+1)
+	for ct, p := range cfg.WithdrawPolicies {
+		if err := p.Validate(); err != nil {
+			panic(fmt.Errorf("%s withdraw policy: %v", ct, err))
+		}
+		policies[ct] = p
+	}
+	if err := router.ValidateQuotas(cfg.Quotas); err != nil {
+		panic(err)
+	}
+
+	if _, ok := policies[bitcoin.Type]; !ok {
+		policies[bitcoin.Type] = account.WithdrawPolicy{Fee: uint64(cfg.BtcFee)}
+	}
+
+	if cfg.RebuildCandles {
+		for _, cp := range orderManager.GetCoinPairs() {
+			logger.Info("rebuild %s candles from trade history", cp)
+			if err := orderManager.RebuildCandles(cp); err != nil {
+				panic(err)
+			}
+		}
 	}
 
 	s := &ExchangeServer{
 		cfg:          *cfg,
 		wallets:      wlts,
 		Manager:      acntMgr,
-		btcum:        btcum,
-		skyum:        skyum,
 		orderManager: orderManager,
+		pairs:        pairMap,
+		policies:     policies,
+		store:        store,
 		coins:        make(map[string]coin.Gateway),
-		orderHandlers: map[string]chan order.Order{
-			"bitcoin/skycoin": make(chan order.Order, 100),
-		},
+		utxoMgrs:     make(map[string]coin.UtxoManager),
+		hub:          sknet.NewHub(),
+	}
+
+	// the orders are matched and settled inside the committer.
+	orderManager.RegisterCommitter(s.atomically)
+	orderManager.RegisterSettler(bookSettler{s})
+	orderManager.RegisterNotifier(bookNotifier{s})
+
+	// settle the trades that were executed before crash.
+	s.settleUnsettledTrades()
+
+	// the escrow must hold the coins locked by the orders.
+	if err := s.checkEscrow(); err != nil {
+		panic(err)
 	}
 
+	// store the new created books.
+	s.SaveAccount()
+
 	return s
 }
 
-// BindCoins registers coins
+// BindCoins registers coins, the wallet and utxo manager of each coin are created,
+// so the coin can be deposited and withdrawn. The utxos are chosen by the strategy
+// of the coin's withdraw policy. It must be called before Run.
 func (serv *ExchangeServer) BindCoins(cs ...coin.Gateway) error {
 	for _, c := range cs {
-		if _, exist := serv.coins[c.Type()]; exist {
-			return fmt.Errorf("%s coin already registered", c.Type())
+		ct := c.Type()
+		if _, exist := serv.coins[ct]; exist {
+			return fmt.Errorf("%s coin already registered", ct)
+		}
+
+		if err := serv.wallets.add(ct, serv.cfg.Seed); err != nil {
+			return err
+		}
+
+		addrs, err := serv.wallets.GetAddresses(ct)
+		if err != nil {
+			return err
+		}
+
+		um := c.NewUtxoManager(serv.cfg.Confirms[ct], addrs)
+		if name := serv.policies[ct].Strategy; name != "" {
+			s, err := coin.GetSelector(name)
+			if err != nil {
+				return err
+			}
+			um.SetSelector(s)
 		}
-		serv.coins[c.Type()] = c
+
+		serv.coins[ct] = c
+		serv.utxoMgrs[ct] = um
+		account.RegisterCoinType(ct)
 	}
 
 	return nil
 }
 
-// Run start the exchange server.
-func (serv *ExchangeServer) Run() {
+// Run start the exchange server, it blocks until Shutdown is called.
+func (serv *ExchangeServer) Run() error {
 	logger.Info("server started %s:%d", serv.cfg.Server, serv.cfg.Port)
 
-	// register the order handlers
-	for cp, c := range serv.orderHandlers {
-		serv.orderManager.RegisterOrderChan(cp, c)
+	serv.runMtx.Lock()
+	if serv.quit != nil {
+		serv.runMtx.Unlock()
+		return errors.New("server is already running")
+	}
+
+	// the wallets must hold the coins recorded in journal.
+	if err := serv.checkWallets(); err != nil {
+		serv.runMtx.Unlock()
+		return err
 	}
 
-	// start the utxo manager
+	// start the utxo managers, and credit their deposits.
 	c := make(chan bool)
-	go serv.btcum.Start(c)
-	go serv.skyum.Start(c)
+	serv.quit = c
+	serv.handleDeposits(c)
+	for _, um := range serv.utxoMgrs {
+		um := um
+		serv.goRun(func() { um.Start(c) })
+	}
 
-	go serv.orderManager.Start(1*time.Second, c)
-	serv.handleOrders(c)
+	serv.goRun(func() { serv.orderManager.Start(c) })
 
 	// start the api server.
-	r := router.New(serv, c)
-	r.Run(serv.cfg.Server, serv.cfg.Port)
+	quotas := serv.cfg.Quotas
+	if quotas == nil {
+		quotas = router.DefaultQuotas
+	}
+	serv.api = router.New(serv, c, serv.cfg.Net, quotas)
+	serv.runMtx.Unlock()
+
+	if err := serv.api.Run(serv.cfg.Server, serv.cfg.Port); err != sknet.ErrEngineClosed {
+		return err
+	}
+	return nil
+}
+
+// goRun runs the function in a goroutine that Shutdown waits for.
+func (serv *ExchangeServer) goRun(fn func()) {
+	serv.running.Add(1)
+	go func() {
+		defer serv.running.Done()
+		fn()
+	}()
 }
 
-// GetBtcFee get transaction fee of bitcoin.
+// Shutdown stops the api server after the requests in flight are replied, then stops the
+// order books, utxo managers and deposits, flushes the accounts and books, and closes the
+// store. The api connections still open when the ctx is done are closed.
+func (serv *ExchangeServer) Shutdown(ctx context.Context) error {
+	serv.runMtx.Lock()
+	defer serv.runMtx.Unlock()
+
+	var err error
+	if serv.api != nil {
+		if err = serv.api.Shutdown(ctx); err != nil {
+			logger.Error("shutdown api server: %v", err)
+		}
+	}
+
+	if serv.quit != nil {
+		close(serv.quit)
+		serv.running.Wait()
+	}
+	// the order requests are rejected even if the books were never started.
+	serv.orderManager.Stop()
+
+	serv.commitMtx.Lock()
+	defer serv.commitMtx.Unlock()
+	if serv.stopped {
+		return err
+	}
+	serv.commit()
+	serv.stopped = true
+	if cerr := serv.store.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	logger.Info("server stopped")
+	return err
+}
+
+// GetBtcFee get transaction fee of bitcoin, which is the fee of bitcoin withdraw policy.
 func (serv *ExchangeServer) GetBtcFee() uint64 {
-	return uint64(serv.cfg.BtcFee)
+	return serv.policies[bitcoin.Type].Fee
 }
 
 // GetSecKey get secret key
@@ -190,14 +359,14 @@ func (serv *ExchangeServer) GetAddrPrivKey(cp, addr string) (string, error) {
 }
 
 // GetNewAddress create new address of specific coin type.
-func (serv *ExchangeServer) GetNewAddress(cp string) string {
+func (serv *ExchangeServer) GetNewAddress(cp string) (string, error) {
 	serv.wltMtx.Lock()
 	defer serv.wltMtx.Unlock()
 	addrEntry, err := serv.wallets.NewAddresses(cp, 1)
 	if err != nil {
-		panic("server get new address failed")
+		return "", err
 	}
-	return addrEntry[0].Address
+	return addrEntry[0].Address, nil
 }
 
 // GetCoin gets coin gateway of specific type.
@@ -209,52 +378,325 @@ func (serv *ExchangeServer) GetCoin(ct string) (coin.Gateway, error) {
 	return c, nil
 }
 
-// ChooseUtxos choose appropriate bitcoin utxos,
-func (serv *ExchangeServer) ChooseUtxos(cp string, amount uint64, tm time.Duration) (interface{}, error) {
-	switch cp {
-	case bitcoin.Type:
-		return serv.btcum.ChooseUtxos(amount, tm)
-	case skycoin.Type:
-		return serv.skyum.ChooseUtxos(amount, tm)
-	default:
-		return nil, errors.New("unknow coin type")
+// ChooseUtxos reserves sufficient utxos of specific coin type, the utxos must be either
+// released or marked as spent, or they're released after coin.UtxoReserveTime.
+func (serv *ExchangeServer) ChooseUtxos(cp string, amount uint64, tm time.Duration) ([]coin.Utxo, error) {
+	um, ok := serv.utxoMgrs[cp]
+	if !ok {
+		return nil, fmt.Errorf("%s coin is not supported", cp)
 	}
+	return um.ChooseUtxos(amount, tm)
 }
 
-// PutUtxos set back the utxos of specific coin type.
-func (serv *ExchangeServer) PutUtxos(cp string, utxos interface{}) {
-	switch cp {
-	case bitcoin.Type:
-		btcUtxos := utxos.([]bitcoin.Utxo)
-		for _, u := range btcUtxos {
-			serv.btcum.PutUtxo(u)
-		}
-	case skycoin.Type:
-		skyUtxos := utxos.([]skycoin.Utxo)
-		for _, u := range skyUtxos {
-			serv.skyum.PutUtxo(u)
-		}
+// ReleaseUtxos puts back the reserved utxos of specific coin type.
+func (serv *ExchangeServer) ReleaseUtxos(cp string, utxos []coin.Utxo) {
+	if um, ok := serv.utxoMgrs[cp]; ok {
+		um.ReleaseUtxos(utxos)
+	}
+}
+
+// MarkUtxosSpent marks the reserved utxos as spent by the sent transaction.
+func (serv *ExchangeServer) MarkUtxosSpent(cp string, utxos []coin.Utxo) {
+	if um, ok := serv.utxoMgrs[cp]; ok {
+		um.MarkSpent(utxos)
 	}
 }
 
 // WatchAddress add watch address to utxo manager.
 func (serv *ExchangeServer) WatchAddress(cp, addr string) {
-	switch cp {
-	case bitcoin.Type:
-		serv.btcum.WatchAddresses([]string{addr})
-	case skycoin.Type:
-		serv.skyum.WatchAddresses([]string{addr})
+	if um, ok := serv.utxoMgrs[cp]; ok {
+		um.WatchAddresses([]string{addr})
 	}
 }
 
-// SaveAccount saves account
+// SaveAccount commits the changes of accounts and order books.
 func (serv *ExchangeServer) SaveAccount() error {
-	return serv.Save()
+	serv.commitMtx.Lock()
+	defer serv.commitMtx.Unlock()
+	if serv.stopped {
+		return errServerStopped
+	}
+	serv.commit()
+	return nil
+}
+
+// commit writes the changes of accounts and order books into store in one transaction,
+// the caller must hold the commitMtx, so the changes of one request are committed together.
+// The memory state can't be trusted once the transaction failed, so the server will panic
+// and reload the last committed state after restart. The committed balance changes are
+// published to the subscribers of the accounts.
+func (serv *ExchangeServer) commit() {
+	ps := serv.Manager.Unflushed()
+	err := serv.store.Update(func(tx storage.Tx) error {
+		if err := serv.Manager.Flush(tx); err != nil {
+			return err
+		}
+		return serv.orderManager.Flush(tx)
+	})
+	if err != nil {
+		panic(err)
+	}
+	serv.Manager.Committed()
+	serv.publishPostings(ps)
+}
+
+// atomically executes the function, and commits the changes it made in one transaction.
+func (serv *ExchangeServer) atomically(fn func() error) error {
+	serv.commitMtx.Lock()
+	defer serv.commitMtx.Unlock()
+	if serv.stopped {
+		return errServerStopped
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	serv.commit()
+	return nil
 }
 
-// AddOrder adds order
+// CreateAccountWithPubkey creates account, and commits it into store.
+func (serv *ExchangeServer) CreateAccountWithPubkey(pubkey string) (account.Accounter, error) {
+	serv.commitMtx.Lock()
+	defer serv.commitMtx.Unlock()
+	a, err := serv.Manager.CreateAccountWithPubkey(pubkey)
+	if err != nil {
+		return nil, err
+	}
+	serv.commit()
+	return a, nil
+}
+
+// AddOrder checks the order against the rules of the coin pair, and places it in the book, the
+// escrow of the order is locked before it's matched, and committed together with the book changes.
 func (serv *ExchangeServer) AddOrder(cp string, odr order.Order) (uint64, error) {
-	return serv.orderManager.AddOrder(cp, odr)
+	if err := odr.Check(); err != nil {
+		return 0, err
+	}
+
+	p, ok := serv.pairs[cp]
+	if !ok {
+		return 0, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+
+	if err := p.CheckOrder(odr); err != nil {
+		return 0, err
+	}
+
+	od, err := serv.orderManager.Place(cp, odr)
+	if err != nil {
+		return 0, err
+	}
+	return od.ID, nil
+}
+
+// CancelOrder removes the order from order book, and gives back the escrow of the unfilled part,
+// which was taken from the owner's balance when the order was created.
+func (serv *ExchangeServer) CancelOrder(cp string, id uint64, pubkey string) (order.Order, error) {
+	if _, err := serv.GetAccount(pubkey); err != nil {
+		return order.Order{}, err
+	}
+
+	// once removed from the book, the order can't be matched, so the refund happens only once.
+	od, err := serv.orderManager.CancelOrder(cp, id, pubkey)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	logger.Info("cancel %s order:%d", od.Type, od.ID)
+	return od, nil
+}
+
+// AddConditional checks the conditional order against the rules of the coin pair, and keeps it
+// until the last trade price crosses its trigger price, the escrow is locked now.
+func (serv *ExchangeServer) AddConditional(cp string, c order.Conditional) (uint64, error) {
+	if err := c.Check(); err != nil {
+		return 0, err
+	}
+
+	p, ok := serv.pairs[cp]
+	if !ok {
+		return 0, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+
+	if err := p.CheckOrder(c.Order); err != nil {
+		return 0, err
+	}
+
+	if c.TriggerPrice%p.TickSize != 0 {
+		return 0, fmt.Errorf("trigger price must be a multiple of tick size %d", p.TickSize)
+	}
+
+	c, err := serv.orderManager.PlaceConditional(cp, c)
+	if err != nil {
+		return 0, err
+	}
+	return c.ID, nil
+}
+
+// CancelConditional removes the conditional order that is not triggered yet, and gives back its escrow.
+func (serv *ExchangeServer) CancelConditional(cp string, id uint64, pubkey string) (order.Conditional, error) {
+	if _, err := serv.GetAccount(pubkey); err != nil {
+		return order.Conditional{}, err
+	}
+
+	c, err := serv.orderManager.CancelConditional(cp, id, pubkey)
+	if err != nil {
+		return order.Conditional{}, err
+	}
+
+	logger.Info("cancel %s conditional order:%d", c.Type, c.ID)
+	return c, nil
+}
+
+// GetConditionals gets the conditional orders of the account that are not triggered yet.
+func (serv *ExchangeServer) GetConditionals(cp string, pubkey string) ([]order.Conditional, error) {
+	return serv.orderManager.GetConditionals(cp, pubkey)
+}
+
+// bookSettler moves the coins of the orders and trades for the order manager, it's called
+// inside the committer, so the caller already holds the commitMtx.
+type bookSettler struct {
+	serv *ExchangeServer
+}
+
+// Lock moves the escrow of the order from the owner's balance.
+func (bs bookSettler) Lock(cp string, od order.Order) error {
+	ct, err := escrowCoin(cp, od.Type)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("account:%s lock %s:%d", od.AccountID, ct, od.RestEscrow())
+	return bs.serv.Post(account.NewPosting(account.HistoryOrder, od.AccountID, account.EscrowAccount, ct, od.RestEscrow(), strconv.FormatUint(od.ID, 10)))
+}
+
+// Unlock gives back the escrow of the unfilled part of the removed order.
+func (bs bookSettler) Unlock(cp string, od order.Order) error {
+	refund := od.RestEscrow()
+	if refund == 0 {
+		return nil
+	}
+
+	ct, err := escrowCoin(cp, od.Type)
+	if err != nil {
+		return err
+	}
+	logger.Info("account:%s increase %s:%d", od.AccountID, ct, refund)
+	return bs.serv.Post(account.NewPosting(account.HistoryCancel, account.EscrowAccount, od.AccountID, ct, refund, strconv.FormatUint(od.ID, 10)))
+}
+
+// Settle moves the coins of both sides of the trade from the escrow, which was locked when the
+// orders were created, so the bidder gets the main coin, and the price improvement if the trade
+// was executed below the bid's limit price, while the asker gets the sub coin.
+func (bs bookSettler) Settle(cp string, t order.Trade) error {
+	logger.Info("match trade=== bid:%d ask:%d, price:%d, amount:%d", t.BidID(), t.AskID(), t.Price, t.Amount)
+	ps, err := tradePostings(cp, t)
+	if err != nil {
+		return err
+	}
+	return bs.serv.Post(ps...)
+}
+
+// checkWallets checks that the unspent outputs of each bound coin hold at least the coins of the
+// wallet account, the surplus is the deposits not credited yet, or the outputs of the addresses
+// that don't belong to any account.
+func (serv *ExchangeServer) checkWallets() error {
+	for ct, um := range serv.utxoMgrs {
+		held, err := um.Holdings()
+		if err != nil {
+			return err
+		}
+
+		want := -serv.Balance(account.WalletAccount, ct)
+		if want > 0 && uint64(want) > held {
+			return fmt.Errorf("%s wallet holds %d, less than %d in journal", ct, held, want)
+		}
+		if want < 0 || uint64(want) != held {
+			logger.Warning("%s wallet holds %d, %d in journal", ct, held, want)
+		}
+	}
+	return nil
+}
+
+// loadOrderManager loads the order books from store, the escrow of the orders in the legacy books
+// is moved from equity, since old version took it from the balances before the journal.
+func loadOrderManager(s storage.Store, acntMgr account.Manager) (*order.Manager, error) {
+	m, err := order.LoadManager(s, func(tx storage.Tx, orders map[string][]order.Order) error {
+		for cp, ods := range orders {
+			for _, od := range ods {
+				ct, err := escrowCoin(cp, od.Type)
+				if err != nil {
+					return err
+				}
+				if od.RestEscrow() == 0 {
+					continue
+				}
+
+				account.RegisterCoinType(ct)
+				if err := acntMgr.Post(account.NewPosting(account.HistoryAdmin, account.EquityAccount, account.EscrowAccount, ct, od.RestEscrow(), strconv.FormatUint(od.ID, 10))); err != nil {
+					return err
+				}
+			}
+		}
+		return acntMgr.Flush(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the postings of the escrow were committed with the books.
+	acntMgr.Committed()
+	return m, nil
+}
+
+// checkEscrow checks that the escrow account holds exactly the coins locked by the orders in book,
+// and the conditional orders waiting for trigger.
+func (serv *ExchangeServer) checkEscrow() error {
+	locked := make(map[string]uint64)
+	for _, cp := range serv.orderManager.GetCoinPairs() {
+		bk := serv.orderManager.GetBook(cp)
+		for _, tp := range []order.Type{order.Bid, order.Ask} {
+			ct, err := escrowCoin(cp, tp)
+			if err != nil {
+				return err
+			}
+			for _, od := range bk.GetOrders(tp, 0, math.MaxInt64) {
+				locked[ct] += od.RestEscrow()
+			}
+		}
+
+		cs, err := serv.orderManager.GetConditionals(cp, "")
+		if err != nil {
+			return err
+		}
+		for _, c := range cs {
+			ct, err := escrowCoin(cp, c.Type)
+			if err != nil {
+				return err
+			}
+			locked[ct] += c.RestEscrow()
+		}
+	}
+
+	for ct, amt := range locked {
+		if bal := serv.Balance(account.EscrowAccount, ct); bal != int64(amt) {
+			return fmt.Errorf("%s escrow:%d, locked by orders:%d", ct, bal, amt)
+		}
+	}
+	return nil
+}
+
+// escrowCoin returns the coin type that the order locks, bid locks the sub coin, ask locks the main coin.
+func escrowCoin(cp string, tp order.Type) (string, error) {
+	pair := strings.Split(cp, "/")
+	if len(pair) != 2 {
+		return "", errors.New("error coin pair")
+	}
+
+	if tp == order.Ask {
+		return pair[0], nil
+	}
+	return pair[1], nil
 }
 
 // IsAdmin checks if the given pubkey is admin
@@ -283,57 +725,72 @@ func initDataDir(dir string) string {
 	return dir
 }
 
-func (serv *ExchangeServer) handleOrders(c chan bool) {
-	for cp, ch := range serv.orderHandlers {
-		go func(cp string, ch chan order.Order, closing chan bool) {
-			for {
-				select {
-				case <-closing:
-					return
-				case order := <-ch:
-					// handle the order
-					serv.settleOrder(cp, order)
-				}
-			}
-		}(cp, ch, c)
+// settleTrade settles the trade that was executed but not settled, the trade is marked as
+// settled in the same transaction as the postings.
+func (serv *ExchangeServer) settleTrade(cp string, t order.Trade) error {
+	serv.commitMtx.Lock()
+	defer serv.commitMtx.Unlock()
+	if err := (bookSettler{serv}).Settle(cp, t); err != nil {
+		return err
 	}
+	serv.orderManager.SettleTrade(cp, t.ID)
+	serv.commit()
+	return nil
 }
 
-func (serv *ExchangeServer) settleOrder(cp string, od order.Order) {
-	logger.Info("match order=== type:%s, price:%d, amount:%d", od.Type, od.Price, od.Amount)
-	acnt, err := serv.GetAccount(od.AccountID)
-	if err != nil {
-		panic("error account id")
-	}
-
+// tradePostings returns the postings that settle the trade, the fees recorded in the trade are
+// deducted from the coins both sides receive, and moved to the fee account.
+func tradePostings(cp string, t order.Trade) ([]account.Posting, error) {
 	pair := strings.Split(cp, "/")
 	if len(pair) != 2 {
-		panic("error coin pair")
+		return nil, errors.New("error coin pair")
 	}
 	mainCt := pair[0]
 	subCt := pair[1]
+	ref := strconv.FormatUint(t.ID, 10)
 
-	switch od.Type {
-	case order.Bid:
-		// increase main coin balance
-		logger.Info("account:%s increase %s:%d", od.AccountID, mainCt, od.Amount)
-		if err := acnt.IncreaseBalance(mainCt, od.Amount); err != nil {
-			panic(err)
-		}
+	vol, err := order.Volume(t.Price, t.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if t.BidFee > t.Amount || t.AskFee > vol {
+		return nil, fmt.Errorf("invalid trade:%d", t.ID)
+	}
 
-		serv.SaveAccount()
-	case order.Ask:
-		// increase sub coin balance.
-		logger.Info("account:%s increase %s:%d", od.AccountID, subCt, od.Price*od.Amount)
-		if err := acnt.IncreaseBalance(subCt, od.Price*od.Amount); err != nil {
-			panic(err)
+	// bidder gets the main coin.
+	ps := []account.Posting{
+		account.NewPosting(account.HistoryTrade, account.EscrowAccount, t.BidAccountID, mainCt, t.Amount-t.BidFee, ref),
+	}
+	if t.BidFee > 0 {
+		ps = append(ps, account.NewPosting(account.HistoryFee, account.EscrowAccount, account.FeeAccount, mainCt, t.BidFee, ref))
+	}
+
+	// give back the price improvement to bidder.
+	if t.BidPrice > t.Price {
+		refund, err := order.Volume(t.BidPrice-t.Price, t.Amount)
+		if err != nil {
+			return nil, err
 		}
-		// decrease main coin balance.
-		logger.Info("account:%s decrease %s:%d", od.AccountID, mainCt, od.Amount)
-		if err := acnt.DecreaseBalance(mainCt, od.Amount); err != nil {
-			panic(err)
+		ps = append(ps, account.NewPosting(account.HistoryTrade, account.EscrowAccount, t.BidAccountID, subCt, refund, ref))
+	}
+
+	// asker gets the sub coin.
+	ps = append(ps, account.NewPosting(account.HistoryTrade, account.EscrowAccount, t.AskAccountID, subCt, vol-t.AskFee, ref))
+	if t.AskFee > 0 {
+		ps = append(ps, account.NewPosting(account.HistoryFee, account.EscrowAccount, account.FeeAccount, subCt, t.AskFee, ref))
+	}
+	return ps, nil
+}
+
+// settleUnsettledTrades settles the trades that were committed but not settled.
+func (serv *ExchangeServer) settleUnsettledTrades() {
+	for _, cp := range serv.orderManager.GetCoinPairs() {
+		for _, t := range serv.orderManager.GetUnsettledTrades(cp) {
+			logger.Info("settle %s trade:%d executed before restart", cp, t.ID)
+			if err := serv.settleTrade(cp, t); err != nil {
+				logger.Error("settle %s trade:%d failed: %v", cp, t.ID, err)
+			}
 		}
-		serv.SaveAccount()
 	}
 }
 
@@ -342,6 +799,55 @@ func (serv *ExchangeServer) GetOrders(cp string, tp order.Type, start, end int64
 	return serv.orderManager.GetOrders(cp, tp, start, end)
 }
 
+// GetDepth gets at most n best price levels of each side of the book.
+func (serv *ExchangeServer) GetDepth(cp string, n int) ([]order.PriceLevel, []order.PriceLevel, error) {
+	return serv.orderManager.GetDepth(cp, n)
+}
+
+// GetTicker gets the market summary of specific coin pair.
+func (serv *ExchangeServer) GetTicker(cp string) (order.Ticker, error) {
+	return serv.orderManager.GetTicker(cp)
+}
+
+// GetCandles gets the candles of specific coin pair and interval, whose open time is in [start, end).
+func (serv *ExchangeServer) GetCandles(cp string, iv string, start, end int64) ([]order.Candle, error) {
+	return serv.orderManager.GetCandles(cp, iv, start, end)
+}
+
+// GetTrades gets executed trades of specific coin pair.
+func (serv *ExchangeServer) GetTrades(cp string, start, end int64) ([]order.Trade, error) {
+	return serv.orderManager.GetTrades(cp, start, end)
+}
+
+// GetAccountTrades gets executed trades of specific account.
+func (serv *ExchangeServer) GetAccountTrades(cp string, aid string, start, end int64) ([]order.Trade, error) {
+	return serv.orderManager.GetAccountTrades(cp, aid, start, end)
+}
+
+// GetPairs returns the configured trading pairs, ordered by name.
+func (serv *ExchangeServer) GetPairs() []order.Pair {
+	pairs := make([]order.Pair, 0, len(serv.pairs))
+	for _, p := range serv.pairs {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+// GetFeeRevenue returns the accrued trading and withdraw fees of the coins of all books and bound coins.
+func (serv *ExchangeServer) GetFeeRevenue() map[string]uint64 {
+	fees := make(map[string]uint64)
+	for _, cp := range serv.orderManager.GetCoinPairs() {
+		for _, ct := range strings.Split(cp, "/") {
+			fees[ct] = uint64(serv.Balance(account.FeeAccount, ct))
+		}
+	}
+	for ct := range serv.coins {
+		fees[ct] = uint64(serv.Balance(account.FeeAccount, ct))
+	}
+	return fees
+}
+
 // GetSupportCoins returns all supported coin's symbol
 func (serv *ExchangeServer) GetSupportCoins() []string {
 	symbols := make([]string, len(serv.coins))
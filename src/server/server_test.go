@@ -0,0 +1,986 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/coin/mzcoin"
+	"github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+	"github.com/skycoin/skycoin-exchange/src/coin/suncoin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/server/router"
+	"github.com/skycoin/skycoin-exchange/src/server/storage"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/stretchr/testify/assert"
+)
+
+var testCoinPair = "bitcoin/skycoin"
+
+var testPairs = map[string]order.Pair{
+	testCoinPair: {Name: testCoinPair, TickSize: 1, LotSize: 1, MinOrder: 1, Enabled: true},
+}
+
+// newTestServer creates server with the memory store.
+func newTestServer() *ExchangeServer {
+	s := storage.NewMemStore()
+	return loadTestServer(account.NewManager(s), order.NewManager(), s)
+}
+
+// loadTestServer creates server with the loaded managers, the orders are matched inside the committer.
+func loadTestServer(acntMgr account.Manager, orderManager *order.Manager, s storage.Store) *ExchangeServer {
+	serv := &ExchangeServer{
+		Manager:      acntMgr,
+		orderManager: orderManager,
+		pairs:        testPairs,
+		store:        s,
+		hub:          sknet.NewHub(),
+	}
+	orderManager.RegisterCommitter(serv.atomically)
+	orderManager.RegisterSettler(bookSettler{serv})
+	orderManager.RegisterNotifier(bookNotifier{serv})
+	return serv
+}
+
+// startBooks starts the matching goroutines of the books, the returned function stops them.
+func startBooks(serv *ExchangeServer) func() {
+	closing := make(chan bool)
+	go serv.orderManager.Start(closing)
+	return func() { close(closing) }
+}
+
+// totalBalances sums up the balances of all accounts and the escrow locked by the orders in book.
+func totalBalances(t *testing.T, serv *ExchangeServer, ids []string, bk *order.Book) map[string]uint64 {
+	total := map[string]uint64{}
+	for _, id := range ids {
+		a, err := serv.GetAccount(id)
+		assert.Nil(t, err)
+		total["bitcoin"] += a.GetBalance("bitcoin")
+		total["skycoin"] += a.GetBalance("skycoin")
+	}
+
+	for _, od := range bk.GetOrders(order.Bid, 0, math.MaxInt64) {
+		total["skycoin"] += od.RestEscrow()
+	}
+
+	for _, od := range bk.GetOrders(order.Ask, 0, math.MaxInt64) {
+		total["bitcoin"] += od.RestEscrow()
+	}
+	return total
+}
+
+// TestSettleTradeConservation places random orders, matches them, and checks that
+// settling the trades never creates or destroys coins.
+func TestSettleTradeConservation(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		serv := newTestServer()
+		ids := make([]string, 5)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("account%d", i)
+			_, err := serv.CreateAccountWithPubkey(ids[i])
+			assert.Nil(t, err)
+			assert.Nil(t, serv.AdjustBalance(ids[i], "bitcoin", uint64(r.Intn(100)), "test"))
+			assert.Nil(t, serv.AdjustBalance(ids[i], "skycoin", uint64(r.Intn(10000)), "test"))
+		}
+
+		bk := &order.Book{}
+		for i := 0; i < 40; i++ {
+			aid := ids[r.Intn(len(ids))]
+			tp := order.Type(r.Intn(2))
+			od := order.New(aid, tp, uint64(90+r.Intn(20)), uint64(1+r.Intn(10)))
+			od.ID = uint64(i + 1)
+			od.CreatedAt = int64(i)
+
+			// lock the escrow as AddOrder does.
+			ct, err := escrowCoin(testCoinPair, tp)
+			assert.Nil(t, err)
+			if serv.Post(account.NewPosting(account.HistoryOrder, aid, account.EscrowAccount, ct, od.RestEscrow(), "")) != nil {
+				continue
+			}
+
+			switch tp {
+			case order.Bid:
+				bk.AddBid(*od)
+			case order.Ask:
+				bk.AddAsk(*od)
+			}
+		}
+
+		before := totalBalances(t, serv, ids, bk)
+		for _, td := range bk.Match() {
+			assert.True(t, td.Price <= td.BidPrice)
+			assert.Nil(t, serv.settleTrade(testCoinPair, td))
+		}
+		after := totalBalances(t, serv, ids, bk)
+		assert.Equal(t, before, after, "seed:%d", seed)
+		assert.Nil(t, serv.CheckJournal(), "seed:%d", seed)
+
+		// the escrow account holds exactly the coins locked by the orders in book.
+		locked := totalBalances(t, serv, nil, bk)
+		assert.Equal(t, int64(locked["bitcoin"]), serv.Balance(account.EscrowAccount, "bitcoin"), "seed:%d", seed)
+		assert.Equal(t, int64(locked["skycoin"]), serv.Balance(account.EscrowAccount, "skycoin"), "seed:%d", seed)
+
+		// the book must not be crossed after matching.
+		bids := bk.GetOrders(order.Bid, 0, 1)
+		asks := bk.GetOrders(order.Ask, 0, 1)
+		if len(bids) > 0 && len(asks) > 0 {
+			assert.True(t, bids[0].Price < asks[0].Price, "seed:%d", seed)
+		}
+	}
+}
+
+// TestCreditDeposit checks that the same output is credited only once, even after
+// the accounts were reloaded from disk.
+func TestCreditDeposit(t *testing.T) {
+	serv := newTestServer()
+	a, err := serv.CreateAccountWithPubkey("account0")
+	assert.Nil(t, err)
+	a.AddDepositAddress("bitcoin", "addr0")
+	assert.Nil(t, serv.SaveAccount())
+
+	serv.creditDeposit("bitcoin", "txid:0", "addr0", 100)
+	serv.creditDeposit("bitcoin", "txid:0", "addr0", 100)
+	serv.creditDeposit("bitcoin", "txid:1", "addr0", 50)
+	assert.Equal(t, uint64(150), a.GetBalance("bitcoin"))
+
+	// the output of address that has no owner will be ignored.
+	serv.creditDeposit("bitcoin", "txid:2", "addr1", 30)
+	assert.Equal(t, uint64(150), a.GetBalance("bitcoin"))
+
+	// the outputs show up again after restart.
+	m, err := account.LoadManager(serv.store)
+	assert.Nil(t, err)
+	serv.Manager = m
+	serv.creditDeposit("bitcoin", "txid:0", "addr0", 100)
+	serv.creditDeposit("bitcoin", "txid:1", "addr0", 50)
+	a, err = serv.GetAccount("account0")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(150), a.GetBalance("bitcoin"))
+
+	hs := a.GetHistory("bitcoin", 0, 0)
+	assert.Equal(t, 2, len(hs))
+	assert.Equal(t, account.HistoryDeposit, hs[0].Type)
+	assert.Equal(t, "txid:0", hs[0].Ref)
+	assert.Equal(t, int64(100), hs[0].Amount)
+	assert.Equal(t, 0, len(a.GetHistory("skycoin", 0, 0)))
+	assert.Equal(t, 0, len(a.GetHistory("", 0, hs[0].CreatedAt-1)))
+}
+
+// TestSettleTradeHistory checks that the balance changes of settlement are recorded.
+func TestSettleTradeHistory(t *testing.T) {
+	serv := newTestServer()
+	bidAcnt, err := serv.CreateAccountWithPubkey("bidder")
+	assert.Nil(t, err)
+	askAcnt, err := serv.CreateAccountWithPubkey("asker")
+	assert.Nil(t, err)
+
+	// lock the escrow of the orders.
+	assert.Nil(t, serv.AdjustBalance("asker", "bitcoin", 2, "test"))
+	assert.Nil(t, serv.AdjustBalance("bidder", "skycoin", 200, "test"))
+	assert.Nil(t, serv.Post(
+		account.NewPosting(account.HistoryOrder, "asker", account.EscrowAccount, "bitcoin", 2, "1"),
+		account.NewPosting(account.HistoryOrder, "bidder", account.EscrowAccount, "skycoin", 200, "2")))
+
+	bk := &order.Book{}
+	bk.AddAsk(order.Order{ID: 1, AccountID: "asker", Type: order.Ask, Price: 90, Amount: 2, RestAmt: 2, CreatedAt: 1})
+	bk.AddBid(order.Order{ID: 2, AccountID: "bidder", Type: order.Bid, Price: 100, Amount: 2, RestAmt: 2, CreatedAt: 2})
+	trades := bk.Match()
+	assert.Equal(t, 1, len(trades))
+	trades[0].ID = 7
+	assert.Nil(t, serv.settleTrade(testCoinPair, trades[0]))
+
+	bhs := bidAcnt.GetHistory("", 0, 0)
+	assert.Equal(t, 4, len(bhs))
+	assert.Equal(t, account.HistoryOrder, bhs[1].Type)
+	assert.Equal(t, int64(-200), bhs[1].Amount)
+	assert.Equal(t, "bitcoin", bhs[2].CoinType)
+	assert.Equal(t, int64(2), bhs[2].Amount)
+	assert.Equal(t, "skycoin", bhs[3].CoinType)
+	assert.Equal(t, int64(20), bhs[3].Amount)
+	assert.Equal(t, uint64(20), bidAcnt.GetBalance("skycoin"))
+
+	assert.Equal(t, int64(0), serv.Balance(account.EscrowAccount, "bitcoin"))
+	assert.Equal(t, int64(0), serv.Balance(account.EscrowAccount, "skycoin"))
+	assert.Nil(t, serv.CheckJournal())
+
+	ahs := askAcnt.GetHistory("skycoin", 0, 0)
+	assert.Equal(t, 1, len(ahs))
+	assert.Equal(t, account.HistoryTrade, ahs[0].Type)
+	assert.Equal(t, "7", ahs[0].Ref)
+	assert.Equal(t, int64(180), ahs[0].Amount)
+}
+
+// TestCommitOrder checks that the escrow and the order book are committed together.
+func TestCommitOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-commit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	order.InitTradeDir(dir)
+
+	serv := newTestServer()
+	assert.Nil(t, serv.orderManager.AddBook(testCoinPair, &order.Book{}))
+	stop := startBooks(serv)
+	_, err = serv.CreateAccountWithPubkey("account0")
+	assert.Nil(t, err)
+	assert.Nil(t, serv.AdjustBalance("account0", "skycoin", 1000, "test"))
+	serv.SaveAccount()
+
+	bid, err := serv.AddOrder(testCoinPair, *order.New("account0", order.Bid, 100, 3))
+	assert.Nil(t, err)
+	_, err = serv.AddOrder(testCoinPair, *order.New("account0", order.Bid, 100, 8))
+	assert.NotNil(t, err)
+	_, err = serv.AddOrder(testCoinPair, *order.New("account0", order.Bid, 90, 5))
+	assert.Nil(t, err)
+	_, err = serv.CancelOrder(testCoinPair, bid, "account0")
+	assert.Nil(t, err)
+	stop()
+
+	// reload the state from store.
+	acntMgr, err := account.LoadManager(serv.store)
+	assert.Nil(t, err)
+	orderManager, err := loadOrderManager(serv.store, acntMgr)
+	assert.Nil(t, err)
+	serv = loadTestServer(acntMgr, orderManager, serv.store)
+	defer startBooks(serv)()
+	assert.Nil(t, serv.CheckJournal())
+	assert.Nil(t, serv.checkEscrow())
+
+	a, err := serv.GetAccount("account0")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(550), a.GetBalance("skycoin"))
+	bids, err := serv.GetOrders(testCoinPair, order.Bid, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(bids))
+	assert.Equal(t, uint64(90), bids[0].Price)
+
+	// the order id continues after reload.
+	id, err := serv.AddOrder(testCoinPair, *order.New("account0", order.Bid, 90, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, bids[0].ID+1, id)
+}
+
+// TestMatchCrossedBook checks that the orders left crossed in book by old version are
+// matched and settled when the books are started.
+func TestMatchCrossedBook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-recover")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	order.InitTradeDir(dir)
+
+	serv := newTestServer()
+	for _, id := range []string{"bidder", "asker"} {
+		_, err := serv.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, serv.AdjustBalance("bidder", "skycoin", 1000, "test"))
+	assert.Nil(t, serv.AdjustBalance("asker", "bitcoin", 10, "test"))
+
+	// the escrow of the orders was locked by old version.
+	bk := &order.Book{}
+	bid := order.Order{ID: 1, AccountID: "bidder", Type: order.Bid, Price: 100, Amount: 5, RestAmt: 5, CreatedAt: 1}
+	ask := order.Order{ID: 2, AccountID: "asker", Type: order.Ask, Price: 90, Amount: 3, RestAmt: 3, CreatedAt: 2}
+	bk.AddBid(bid)
+	bk.AddAsk(ask)
+	assert.Nil(t, serv.Post(
+		account.NewPosting(account.HistoryOrder, "bidder", account.EscrowAccount, "skycoin", bid.RestEscrow(), "1"),
+		account.NewPosting(account.HistoryOrder, "asker", account.EscrowAccount, "bitcoin", ask.RestEscrow(), "2")))
+	assert.Nil(t, serv.orderManager.AddBook(testCoinPair, bk))
+	serv.SaveAccount()
+	assert.Nil(t, serv.checkEscrow())
+
+	stop := startBooks(serv)
+	// wait until the book is matched.
+	for i := 0; i < 100; i++ {
+		if asks, _ := serv.GetOrders(testCoinPair, order.Ask, 0, 1); len(asks) == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	stop()
+
+	// the settlement is committed.
+	acntMgr, err := account.LoadManager(serv.store)
+	assert.Nil(t, err)
+	orderManager, err := loadOrderManager(serv.store, acntMgr)
+	assert.Nil(t, err)
+	serv = loadTestServer(acntMgr, orderManager, serv.store)
+	assert.Nil(t, serv.checkEscrow())
+	assert.Nil(t, serv.CheckJournal())
+	assert.Equal(t, 0, len(orderManager.GetUnsettledTrades(testCoinPair)))
+
+	bidder, err := serv.GetAccount("bidder")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), bidder.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(500), bidder.GetBalance("skycoin"))
+	asker, err := serv.GetAccount("asker")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(300), asker.GetBalance("skycoin"))
+}
+
+// testUtxo is the unspent output of the test wallet.
+type testUtxo struct {
+	id     string
+	amount uint64
+}
+
+func (u testUtxo) GetID() string      { return u.id }
+func (u testUtxo) GetAddress() string { return "addr" }
+func (u testUtxo) GetAmount() uint64  { return u.amount }
+
+// TestCheckWallets checks the wallet account against the unspent outputs of the wallet.
+func TestCheckWallets(t *testing.T) {
+	serv := newTestServer()
+	_, err := serv.CreateAccountWithPubkey("account0")
+	assert.Nil(t, err)
+	assert.Nil(t, serv.CreditDeposit("account0", bitcoin.Type, "txid:0", 100))
+
+	utxos := []coin.Utxo{testUtxo{"txid:0", 60}}
+	serv.utxoMgrs = map[string]coin.UtxoManager{
+		bitcoin.Type: coin.NewUtxoManager(func(addrs []string) ([]coin.Utxo, error) {
+			return utxos, nil
+		}, nil),
+	}
+	assert.NotNil(t, serv.checkWallets())
+
+	// the deposits not credited yet are allowed.
+	utxos = []coin.Utxo{testUtxo{"txid:0", 100}}
+	assert.Nil(t, serv.checkWallets())
+	utxos = append(utxos, testUtxo{"txid:1", 50})
+	assert.Nil(t, serv.checkWallets())
+
+	// the server won't run if the wallet holds less.
+	assert.Nil(t, serv.Post(account.NewPosting(account.HistoryAdmin, account.WalletAccount, "account0", bitcoin.Type, 60, "test")))
+	assert.NotNil(t, serv.Run())
+}
+
+// TestMigrateLegacyOrders checks that the escrow of the orders in the legacy books is locked when
+// they're moved into store, so they can be cancelled and filled.
+func TestMigrateLegacyOrders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-migrate")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	order.InitTradeDir(filepath.Join(dir, "trade"))
+
+	// the escrow of the orders was taken from the balances by old version.
+	acnts := `{"accounts":[
+		{"id":"bidder","balance":{"skycoin":500},"addresses":{}},
+		{"id":"asker","balance":{"bitcoin":5},"addresses":{}},
+		{"id":"taker","balance":{"skycoin":400},"addresses":{}}]}`
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "account", "account.data"), []byte(acnts), 0600))
+
+	bk := &order.Book{}
+	bk.AddBid(order.Order{ID: 1, AccountID: "bidder", Type: order.Bid, Price: 100, Amount: 5, RestAmt: 5, CreatedAt: 1})
+	bk.AddBid(order.Order{ID: 2, AccountID: "bidder", Type: order.Bid, Price: 90, Amount: 2, RestAmt: 2, CreatedAt: 2})
+	bk.AddAsk(order.Order{ID: 3, AccountID: "asker", Type: order.Ask, Price: 110, Amount: 3, RestAmt: 3, CreatedAt: 3})
+	d, err := json.Marshal(bk.ToMarshalable())
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "orderbook", "bitcoin_skycoin.ods"), d, 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "orderbook", "bitcoin_skycoin.id"), []byte(`{"id":3}`), 0600))
+
+	s := storage.NewMemStore()
+	acntMgr, err := account.LoadManager(s)
+	assert.Nil(t, err)
+	orderManager, err := loadOrderManager(s, acntMgr)
+	assert.Nil(t, err)
+	serv := loadTestServer(acntMgr, orderManager, s)
+	assert.Nil(t, serv.checkEscrow())
+	assert.Nil(t, serv.CheckJournal())
+	assert.Equal(t, int64(680), serv.Balance(account.EscrowAccount, "skycoin"))
+	assert.Equal(t, int64(3), serv.Balance(account.EscrowAccount, "bitcoin"))
+
+	// the escrow is committed with the books.
+	acntMgr, err = account.LoadManager(s)
+	assert.Nil(t, err)
+	orderManager, err = loadOrderManager(s, acntMgr)
+	assert.Nil(t, err)
+	serv = loadTestServer(acntMgr, orderManager, s)
+	assert.Nil(t, serv.checkEscrow())
+	defer startBooks(serv)()
+
+	_, err = serv.CancelOrder(testCoinPair, 2, "bidder")
+	assert.Nil(t, err)
+	bidder, err := serv.GetAccount("bidder")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(680), bidder.GetBalance("skycoin"))
+
+	_, err = serv.AddOrder(testCoinPair, *order.New("taker", order.Bid, 110, 3))
+	assert.Nil(t, err)
+	asker, err := serv.GetAccount("asker")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(330), asker.GetBalance("skycoin"))
+	assert.Equal(t, uint64(5), asker.GetBalance("bitcoin"))
+	taker, err := serv.GetAccount("taker")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), taker.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(70), taker.GetBalance("skycoin"))
+
+	assert.Nil(t, serv.checkEscrow())
+	assert.Nil(t, serv.CheckJournal())
+	assert.Equal(t, int64(500), serv.Balance(account.EscrowAccount, "skycoin"))
+	assert.Equal(t, int64(0), serv.Balance(account.EscrowAccount, "bitcoin"))
+}
+
+// TestAddOrderPairRules checks that the orders violating the rules of the pair are rejected.
+func TestAddOrderPairRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-pairs")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	order.InitTradeDir(dir)
+
+	serv := newTestServer()
+	serv.pairs = map[string]order.Pair{
+		testCoinPair:      {Name: testCoinPair, TickSize: 10, LotSize: 5, MinOrder: 10, Enabled: true},
+		"skycoin/mzcoin":  {Name: "skycoin/mzcoin", TickSize: 1, LotSize: 1, MinOrder: 1, Enabled: false},
+		"skycoin/suncoin": {Name: "skycoin/suncoin", TickSize: 1, LotSize: 1, MinOrder: 1, Enabled: true},
+	}
+	for cp := range serv.pairs {
+		assert.Nil(t, serv.orderManager.AddBook(cp, &order.Book{}))
+	}
+	defer startBooks(serv)()
+	_, err = serv.CreateAccountWithPubkey("account0")
+	assert.Nil(t, err)
+	assert.Nil(t, serv.AdjustBalance("account0", "skycoin", 10000, "test"))
+
+	for _, c := range []struct {
+		cp     string
+		price  uint64
+		amount uint64
+		ok     bool
+	}{
+		{testCoinPair, 15, 10, false}, // price is not multiple of tick size.
+		{testCoinPair, 20, 12, false}, // amount is not multiple of lot size.
+		{testCoinPair, 20, 5, false},  // less than minimum order.
+		{testCoinPair, 20, 10, true},
+		{"skycoin/mzcoin", 1, 1, false},  // disabled.
+		{"bitcoin/mzcoin", 1, 1, false},  // not configured.
+		{"skycoin/suncoin", 1, 1, false}, // suncoin is not posted before registered.
+	} {
+		_, err := serv.AddOrder(c.cp, *order.New("account0", order.Bid, c.price, c.amount))
+		assert.Equal(t, c.ok, err == nil, "%s price:%d amount:%d", c.cp, c.price, c.amount)
+	}
+
+	account.RegisterCoinType("suncoin")
+	assert.Nil(t, serv.AdjustBalance("account0", "suncoin", 10, "test"))
+	_, err = serv.AddOrder("skycoin/suncoin", *order.New("account0", order.Bid, 1, 1))
+	assert.Nil(t, err)
+	assert.Nil(t, serv.checkEscrow())
+
+	pairs := serv.GetPairs()
+	assert.Equal(t, 3, len(pairs))
+	assert.Equal(t, testCoinPair, pairs[0].Name)
+}
+
+// TestExecuteOrder checks the immediate orders are settled at once, and the
+// escrow of their unfilled part is given back.
+func TestExecuteOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-execute")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	order.InitTradeDir(dir)
+
+	serv := newTestServer()
+	assert.Nil(t, serv.orderManager.AddBook(testCoinPair, &order.Book{}))
+	defer startBooks(serv)()
+	for _, id := range []string{"maker", "taker"} {
+		_, err := serv.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+		assert.Nil(t, serv.AdjustBalance(id, "bitcoin", 100, "test"))
+		assert.Nil(t, serv.AdjustBalance(id, "skycoin", 10000, "test"))
+	}
+	balance := func(id, ct string) uint64 {
+		a, err := serv.GetAccount(id)
+		assert.Nil(t, err)
+		return a.GetBalance(ct)
+	}
+	newOrder := func(aid string, tp order.Type, kind order.Kind, tif order.TimeInForce, price, amt uint64) order.Order {
+		od := order.New(aid, tp, price, amt)
+		od.Kind = kind
+		od.TimeInForce = tif
+		return *od
+	}
+
+	// the maker's asks: 3@100, 2@110.
+	_, err = serv.AddOrder(testCoinPair, *order.New("maker", order.Ask, 100, 3))
+	assert.Nil(t, err)
+	_, err = serv.AddOrder(testCoinPair, *order.New("maker", order.Ask, 110, 2))
+	assert.Nil(t, err)
+
+	// post-only bid that crosses the asks is rejected.
+	po := order.New("taker", order.Bid, 100, 1)
+	po.PostOnly = true
+	_, err = serv.AddOrder(testCoinPair, *po)
+	assert.Equal(t, order.ErrPostOnlyCross, err)
+
+	// FOK bid can't be filled at 100.
+	_, err = serv.AddOrder(testCoinPair, newOrder("taker", order.Bid, order.Limit, order.FOK, 100, 4))
+	assert.Equal(t, order.ErrNotFilled, err)
+	assert.Equal(t, uint64(10000), balance("taker", "skycoin"))
+
+	// IOC bid fills 3@100, the rest is cancelled.
+	_, err = serv.AddOrder(testCoinPair, newOrder("taker", order.Bid, order.Limit, order.IOC, 105, 5))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(103), balance("taker", "bitcoin"))
+	assert.Equal(t, uint64(9700), balance("taker", "skycoin"))
+	bids, err := serv.GetOrders(testCoinPair, order.Bid, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(bids))
+
+	// market bid of 5 only fills the 2@110 left.
+	_, err = serv.AddOrder(testCoinPair, newOrder("taker", order.Bid, order.Market, order.GTC, 0, 5))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(105), balance("taker", "bitcoin"))
+	assert.Equal(t, uint64(9480), balance("taker", "skycoin"))
+
+	// market bid without asks is rejected.
+	_, err = serv.AddOrder(testCoinPair, newOrder("taker", order.Bid, order.Market, order.GTC, 0, 1))
+	assert.Equal(t, order.ErrNoLiquidity, err)
+
+	// market ask is executed at the bid's price.
+	_, err = serv.AddOrder(testCoinPair, *order.New("maker", order.Bid, 90, 2))
+	assert.Nil(t, err)
+	_, err = serv.AddOrder(testCoinPair, newOrder("taker", order.Ask, order.Market, order.GTC, 0, 3))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(103), balance("taker", "bitcoin"))
+	assert.Equal(t, uint64(9660), balance("taker", "skycoin"))
+
+	assert.Nil(t, serv.checkEscrow())
+	assert.Nil(t, serv.CheckJournal())
+	trades, err := serv.GetAccountTrades(testCoinPair, "taker", 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(trades))
+	assert.Equal(t, 0, len(serv.orderManager.GetUnsettledTrades(testCoinPair)))
+}
+
+// TestConditionalOrder checks that the escrow of the conditional order is locked when it's
+// placed, and the triggered order is settled with the locked escrow.
+func TestConditionalOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-conditional")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	order.InitTradeDir(dir)
+
+	serv := newTestServer()
+	assert.Nil(t, serv.orderManager.AddBook(testCoinPair, &order.Book{}))
+	defer startBooks(serv)()
+	for _, id := range []string{"maker", "taker", "stopper"} {
+		_, err := serv.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+		assert.Nil(t, serv.AdjustBalance(id, "bitcoin", 100, "test"))
+		assert.Nil(t, serv.AdjustBalance(id, "skycoin", 10000, "test"))
+	}
+	balance := func(id, ct string) uint64 {
+		a, err := serv.GetAccount(id)
+		assert.Nil(t, err)
+		return a.GetBalance(ct)
+	}
+
+	// the last price is 100.
+	_, err = serv.AddOrder(testCoinPair, *order.New("maker", order.Ask, 100, 1))
+	assert.Nil(t, err)
+	_, err = serv.AddOrder(testCoinPair, *order.New("taker", order.Bid, 100, 1))
+	assert.Nil(t, err)
+
+	// stop-loss market ask of 5 at 95, and stop-loss bid of 2@120 at 110.
+	ask := order.New("stopper", order.Ask, 0, 5)
+	ask.Kind = order.Market
+	_, err = serv.AddConditional(testCoinPair, order.Conditional{Order: *ask, Trigger: order.StopLoss, TriggerPrice: 95})
+	assert.Nil(t, err)
+	bid := order.New("stopper", order.Bid, 120, 2)
+	bidID, err := serv.AddConditional(testCoinPair, order.Conditional{Order: *bid, Trigger: order.StopLoss, TriggerPrice: 110})
+	assert.Nil(t, err)
+	_, err = serv.AddConditional(testCoinPair, order.Conditional{Order: *bid, Trigger: order.StopLoss, TriggerPrice: 100})
+	assert.Equal(t, order.ErrWouldTrigger, err)
+
+	// the escrow is locked when placed.
+	assert.Equal(t, uint64(95), balance("stopper", "bitcoin"))
+	assert.Equal(t, uint64(9760), balance("stopper", "skycoin"))
+	assert.Nil(t, serv.checkEscrow())
+	cs, err := serv.GetConditionals(testCoinPair, "stopper")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(cs))
+
+	// the trade at 95 triggers the market ask, which fills 2@95, and the rest is cancelled.
+	_, err = serv.AddOrder(testCoinPair, *order.New("maker", order.Bid, 95, 3))
+	assert.Nil(t, err)
+	_, err = serv.AddOrder(testCoinPair, *order.New("taker", order.Ask, 95, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(98), balance("stopper", "bitcoin"))
+	assert.Equal(t, uint64(9950), balance("stopper", "skycoin"))
+	assert.Equal(t, uint64(102), balance("maker", "bitcoin"))
+	assert.Nil(t, serv.checkEscrow())
+
+	// the escrow is given back when cancelled.
+	_, err = serv.CancelConditional(testCoinPair, bidID, "maker")
+	assert.NotNil(t, err)
+	c, err := serv.CancelConditional(testCoinPair, bidID, "stopper")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(240), c.RestEscrow())
+	assert.Equal(t, uint64(10190), balance("stopper", "skycoin"))
+
+	cs, err = serv.GetConditionals(testCoinPair, "stopper")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(cs))
+	assert.Nil(t, serv.checkEscrow())
+	assert.Nil(t, serv.CheckJournal())
+}
+
+// TestSubscribe checks that the committed changes are pushed to the subscribed connection.
+func TestSubscribe(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-subscribe")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	order.InitTradeDir(dir)
+
+	serv := newTestServer()
+	pk, sk := cipher.GenerateKeyPair()
+	serv.cfg.Seckey = sk.Hex()
+	sknet.SetPubkey(pk.Hex())
+	assert.Nil(t, serv.orderManager.AddBook(testCoinPair, &order.Book{}))
+	defer startBooks(serv)()
+	mpk, msk := cipher.GenerateKeyPair()
+	_, tsk := cipher.GenerateKeyPair()
+	maker := mpk.Hex()
+	for _, id := range []string{maker, "taker"} {
+		_, err := serv.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+		assert.Nil(t, serv.AdjustBalance(id, "bitcoin", 100, "test"))
+		assert.Nil(t, serv.AdjustBalance(id, "skycoin", 10000, "test"))
+	}
+
+	_, err = serv.ResolveTopics(maker, []string{"book:skycoin/mzcoin"})
+	assert.NotNil(t, err)
+	_, err = serv.ResolveTopics("nobody", []string{"account"})
+	assert.NotNil(t, err)
+
+	// serve the api on a free port.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := l.Addr().(*net.TCPAddr)
+	l.Close()
+	quit := make(chan bool)
+	defer close(quit)
+	go router.New(serv, quit, sknet.DefaultOptions, router.DefaultQuotas).Run(addr.IP.String(), addr.Port)
+
+	// prove signs over the replay stamp of the subscribe request with the seckey.
+	prove := func(req pp.SubscribeReq, seckey string) pp.SubscribeReq {
+		tm, nonce, err := sknet.NewStamp()
+		assert.Nil(t, err)
+		sig, err := sknet.SignProof("/subscribe", tm, nonce, seckey)
+		assert.Nil(t, err)
+		req.ReqTime, req.ReqNonce, req.Sig = &tm, &nonce, &sig
+		return req
+	}
+
+	req := pp.SubscribeReq{
+		Pubkey: pp.PtrString(maker),
+		Topics: []string{"book:" + testCoinPair, "trades:" + testCoinPair, "account"},
+	}
+	var sub *sknet.Subscription
+	var signed pp.SubscribeReq
+	for i := 0; sub == nil; i++ {
+		res := pp.SubscribeRes{}
+		signed = prove(req, msk.Hex())
+		sub, err = sknet.Subscribe(addr.String(), "/subscribe", signed, &res)
+		if i == 100 {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer sub.Close()
+
+	// the account topic can't be subscribed without the proof of the account key,
+	// and the proof can't be replayed.
+	for _, r := range []pp.SubscribeReq{req, prove(req, tsk.Hex()), signed} {
+		res := pp.SubscribeRes{}
+		s, err := sknet.Subscribe(addr.String(), "/subscribe", r, &res)
+		assert.NotNil(t, err)
+		if s != nil {
+			s.Close()
+		}
+	}
+
+	_, err = serv.AddOrder(testCoinPair, *order.New(maker, order.Bid, 100, 2))
+	assert.Nil(t, err)
+	_, err = serv.AddOrder(testCoinPair, *order.New("taker", order.Ask, 100, 1))
+	assert.Nil(t, err)
+
+	// maker locks the escrow, then the book is changed by the bid.
+	var acnt pp.AccountEvent
+	var book pp.BookEvent
+	ev, err := sub.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "account:"+maker, ev.Topic)
+	assert.Nil(t, json.Unmarshal(ev.Data, &acnt))
+	assert.Equal(t, "balance", acnt.GetType())
+	assert.Equal(t, int64(-200), acnt.GetHistory().GetAmount())
+	assert.Equal(t, uint64(9800), acnt.GetBalance())
+
+	ev, err = sub.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "book:"+testCoinPair, ev.Topic)
+	assert.Nil(t, json.Unmarshal(ev.Data, &book))
+	assert.Equal(t, 1, len(book.Bids))
+	assert.Equal(t, uint64(2), book.Bids[0].GetAmount())
+
+	// the ask fills the bid, maker gets the bitcoin.
+	ev, err = sub.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "account:"+maker, ev.Topic)
+	assert.Nil(t, json.Unmarshal(ev.Data, &acnt))
+	assert.Equal(t, "bitcoin", acnt.GetCoinType())
+	assert.Equal(t, uint64(101), acnt.GetBalance())
+
+	topics := []string{}
+	for len(topics) < 3 {
+		ev, err = sub.Next()
+		assert.Nil(t, err)
+		topics = append(topics, ev.Topic)
+	}
+	assert.Equal(t, []string{"book:" + testCoinPair, "trades:" + testCoinPair, "account:" + maker}, topics)
+	assert.Nil(t, json.Unmarshal(ev.Data, &acnt))
+	assert.Equal(t, "fill", acnt.GetType())
+	assert.True(t, acnt.GetFill().GetMaker())
+	assert.Equal(t, uint64(1), acnt.GetFill().GetAmount())
+}
+
+// TestTradeFees checks that the fees are deducted at settlement, and credited to the fee account.
+func TestTradeFees(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-fees")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	order.InitTradeDir(dir)
+
+	serv := newTestServer()
+	assert.Nil(t, serv.orderManager.AddBook(testCoinPair, &order.Book{}))
+	serv.orderManager.SetFeeSchedule(testCoinPair, order.FeeSchedule{MakerFee: 100, TakerFee: 200})
+	defer startBooks(serv)()
+	for _, id := range []string{"maker", "taker"} {
+		_, err := serv.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+		assert.Nil(t, serv.AdjustBalance(id, "bitcoin", 1000, "test"))
+		assert.Nil(t, serv.AdjustBalance(id, "skycoin", 100000, "test"))
+	}
+
+	_, err = serv.AddOrder(testCoinPair, *order.New("maker", order.Bid, 100, 500))
+	assert.Nil(t, err)
+	_, err = serv.AddOrder(testCoinPair, *order.New("taker", order.Ask, 100, 500))
+	assert.Nil(t, err)
+
+	// maker pays 1% of the bitcoin, taker pays 2% of the skycoin.
+	balance := func(id, ct string) uint64 {
+		a, err := serv.GetAccount(id)
+		assert.Nil(t, err)
+		return a.GetBalance(ct)
+	}
+	assert.Equal(t, uint64(1495), balance("maker", "bitcoin"))
+	assert.Equal(t, uint64(50000), balance("maker", "skycoin"))
+	assert.Equal(t, uint64(500), balance("taker", "bitcoin"))
+	assert.Equal(t, uint64(149000), balance("taker", "skycoin"))
+	assert.Equal(t, map[string]uint64{"bitcoin": 5, "skycoin": 1000}, serv.GetFeeRevenue())
+
+	trades, err := serv.GetTrades(testCoinPair, 0, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), trades[0].BidFee)
+	assert.Equal(t, uint64(1000), trades[0].AskFee)
+	assert.Nil(t, serv.checkEscrow())
+	assert.Nil(t, serv.CheckJournal())
+}
+
+// TestHoldWithdrawal checks the withdrawals are limited by the policy, and the
+// daily cap counts the fees, the sent withdrawals and the ones still held.
+func TestHoldWithdrawal(t *testing.T) {
+	serv := newTestServer()
+	serv.coins = map[string]coin.Gateway{bitcoin.Type: &bitcoin.Bitcoin{}}
+	serv.policies = map[string]account.WithdrawPolicy{
+		bitcoin.Type: {Fee: 10, Min: 100, Max: 1000, DailyCap: 1500},
+	}
+
+	a, err := serv.CreateAccountWithPubkey("account0")
+	assert.Nil(t, err)
+	assert.Nil(t, serv.AdjustBalance(a.GetID(), bitcoin.Type, 5000, "admin"))
+
+	_, err = serv.HoldWithdrawal(a.GetID(), bitcoin.Type, 99)
+	assert.NotNil(t, err)
+	_, err = serv.HoldWithdrawal(a.GetID(), bitcoin.Type, 1001)
+	assert.NotNil(t, err)
+	_, err = serv.HoldWithdrawal(a.GetID(), skycoin.Type, 100)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(5000), a.GetBalance(bitcoin.Type))
+
+	// the amount plus the fee overflows.
+	serv.policies[bitcoin.Type] = account.WithdrawPolicy{Fee: 10}
+	_, err = serv.HoldWithdrawal(a.GetID(), bitcoin.Type, math.MaxUint64-5)
+	assert.Equal(t, account.ErrWithdrawOverflow, err)
+	_, err = serv.HoldWithdrawal(a.GetID(), bitcoin.Type, math.MaxInt64)
+	assert.Equal(t, account.ErrWithdrawOverflow, err)
+	assert.Equal(t, uint64(5000), a.GetBalance(bitcoin.Type))
+	serv.policies[bitcoin.Type] = account.WithdrawPolicy{Fee: 10, Min: 100, Max: 1000, DailyCap: 1500}
+
+	fee, err := serv.HoldWithdrawal(a.GetID(), bitcoin.Type, 900)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), fee)
+	assert.Equal(t, uint64(4090), a.GetBalance(bitcoin.Type))
+
+	// the held coins are sent out.
+	assert.Nil(t, serv.Post(
+		account.NewPosting(account.HistoryHold, account.HoldAccount, a.GetID(), bitcoin.Type, 910, ""),
+		account.NewPosting(account.HistoryWithdraw, a.GetID(), account.WalletAccount, bitcoin.Type, 910, "txid")))
+	assert.Equal(t, uint64(910), a.GetWithdrawn(bitcoin.Type, 0))
+
+	// 910 + 600 + 10 exceeds the daily cap.
+	_, err = serv.HoldWithdrawal(a.GetID(), bitcoin.Type, 600)
+	assert.NotNil(t, err)
+
+	_, err = serv.HoldWithdrawal(a.GetID(), bitcoin.Type, 500)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1420), a.GetWithdrawn(bitcoin.Type, 0))
+	_, err = serv.HoldWithdrawal(a.GetID(), bitcoin.Type, 100)
+	assert.NotNil(t, err)
+
+	// the failed withdrawal gives back the held coins, and doesn't count.
+	assert.Nil(t, serv.Post(account.NewPosting(account.HistoryHold, account.HoldAccount, a.GetID(), bitcoin.Type, 510, "")))
+	assert.Equal(t, uint64(910), a.GetWithdrawn(bitcoin.Type, 0))
+	assert.Equal(t, uint64(4090), a.GetBalance(bitcoin.Type))
+
+	// the withdrawals before the period don't count.
+	assert.Equal(t, uint64(0), a.GetWithdrawn(bitcoin.Type, time.Now().Unix()+1))
+	assert.Nil(t, serv.Manager.CheckJournal())
+}
+
+// TestUtxoManagerFamily checks the forks of skycoin share the utxo manager with their own node.
+func TestUtxoManagerFamily(t *testing.T) {
+	assert.IsType(t, &coin.UtxoPool{}, (&bitcoin.Bitcoin{}).NewUtxoManager(1, nil))
+	sky := skycoin.New("127.0.0.1:6420").NewUtxoManager(1, nil)
+	for _, c := range []coin.Gateway{mzcoin.New("127.0.0.1:7420"), suncoin.New("127.0.0.1:7620")} {
+		assert.IsType(t, sky, c.NewUtxoManager(1, nil))
+	}
+}
+
+// TestHoursSelector checks the skycoin utxos are chosen with enough hours for the outputs.
+func TestHoursSelector(t *testing.T) {
+	makeUtxo := func(hash string, coins string, hours uint64) coin.Utxo {
+		return skycoin.SkyUtxo{ReadableOutput: visor.ReadableOutput{Hash: hash, Coins: coins, Hours: hours}}
+	}
+	utxos := []coin.Utxo{
+		makeUtxo("a", "10.000000", 0),
+		makeUtxo("b", "1.000000", 3),
+		makeUtxo("c", "2.000000", 20),
+	}
+
+	s := skycoin.HoursSelector(coin.LargestFirst)
+	uxs := s(utxos, 10e6)
+	assert.Equal(t, 2, len(uxs))
+	assert.Equal(t, "c", uxs[1].GetID())
+
+	// the hours are enough.
+	uxs = s(utxos, 11e6)
+	assert.Equal(t, 2, len(uxs))
+	assert.Equal(t, "c", uxs[1].GetID())
+
+	// the utxo of the most hours is added, though the hours are still not enough.
+	uxs = s(utxos[:2], 10e6)
+	assert.Equal(t, 2, len(uxs))
+	assert.Equal(t, "b", uxs[1].GetID())
+
+	// insufficient coins.
+	assert.Nil(t, s(utxos, 20e6))
+}
+
+// TestWithdrawFee checks the withdraw fee kept by the exchange counts in the daily cap.
+func TestWithdrawFee(t *testing.T) {
+	serv := newTestServer()
+	serv.coins = map[string]coin.Gateway{mzcoin.Type: mzcoin.New("127.0.0.1:7420")}
+	serv.policies = map[string]account.WithdrawPolicy{
+		mzcoin.Type: {Fee: 1e6, DailyCap: 10e6},
+	}
+	account.RegisterCoinType(mzcoin.Type)
+
+	a, err := serv.CreateAccountWithPubkey("account0")
+	assert.Nil(t, err)
+	assert.Nil(t, serv.AdjustBalance(a.GetID(), mzcoin.Type, 20e6, "admin"))
+
+	fee, err := serv.HoldWithdrawal(a.GetID(), mzcoin.Type, 5e6)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1e6), fee)
+	assert.Nil(t, serv.Post(
+		account.NewPosting(account.HistoryHold, account.HoldAccount, a.GetID(), mzcoin.Type, 6e6, ""),
+		account.NewPosting(account.HistoryWithdraw, a.GetID(), account.WalletAccount, mzcoin.Type, 5e6, "txid"),
+		account.NewPosting(account.HistoryFee, a.GetID(), account.FeeAccount, mzcoin.Type, 1e6, "txid")))
+	assert.Equal(t, uint64(6e6), a.GetWithdrawn(mzcoin.Type, 0))
+	assert.Equal(t, uint64(1e6), serv.GetFeeRevenue()[mzcoin.Type])
+
+	_, err = serv.HoldWithdrawal(a.GetID(), mzcoin.Type, 4e6)
+	assert.NotNil(t, err)
+	_, err = serv.HoldWithdrawal(a.GetID(), mzcoin.Type, 3e6)
+	assert.Nil(t, err)
+}
+
+func TestShutdown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-shutdown")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	order.InitTradeDir(dir)
+
+	s, err := storage.NewBoltStore(filepath.Join(dir, "exchange.db"))
+	assert.Nil(t, err)
+	serv := loadTestServer(account.NewManager(s), order.NewManager(), s)
+	assert.Nil(t, serv.orderManager.AddBook(testCoinPair, &order.Book{}))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := l.Addr().(*net.TCPAddr)
+	l.Close()
+	serv.cfg = Config{Server: addr.IP.String(), Port: addr.Port, Net: sknet.DefaultOptions}
+	errC := make(chan error, 1)
+	go func() { errC <- serv.Run() }()
+	for i := 0; ; i++ {
+		c, err := net.Dial("tcp", addr.String())
+		if err == nil {
+			c.Close()
+			break
+		}
+		if i == 100 {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the changes not committed yet are flushed.
+	_, err = serv.CreateAccountWithPubkey("account0")
+	assert.Nil(t, err)
+	assert.Nil(t, serv.AdjustBalance("account0", "skycoin", 1000, "test"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, serv.Shutdown(ctx))
+	assert.Nil(t, <-errC)
+	assert.Equal(t, errServerStopped, serv.SaveAccount())
+	_, err = net.Dial("tcp", addr.String())
+	assert.NotNil(t, err)
+
+	s, err = storage.NewBoltStore(filepath.Join(dir, "exchange.db"))
+	assert.Nil(t, err)
+	defer s.Close()
+	acntMgr, err := account.LoadManager(s)
+	assert.Nil(t, err)
+	a, err := acntMgr.GetAccount("account0")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1000), a.GetBalance("skycoin"))
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// BoltStore is the store backed by a BoltDB file.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+// NewBoltStore opens or creates the BoltDB file of the path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Update executes the function in a read-write transaction, which is synced to disk before return.
+func (s *BoltStore) Update(fn func(tx Tx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+// View executes the function in a read-only transaction.
+func (s *BoltStore) View(fn func(tx Tx) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+// Close closes the db file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (t *boltTx) Get(bucket string, key []byte) []byte {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	v := b.Get(key)
+	if v == nil {
+		return nil
+	}
+	// the value is only valid in the transaction.
+	return append([]byte{}, v...)
+}
+
+func (t *boltTx) Put(bucket string, key []byte, value []byte) error {
+	b, err := t.tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	return b.Put(key, value)
+}
+
+func (t *boltTx) Delete(bucket string, key []byte) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.Delete(key)
+}
+
+func (t *boltTx) ForEach(bucket string, fn func(k, v []byte) error) error {
+	b := t.tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(k, v []byte) error {
+		return fn(append([]byte{}, k...), append([]byte{}, v...))
+	})
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// MemStore is the store that keeps everything in memory, used for testing.
+type MemStore struct {
+	buckets map[string]map[string][]byte
+	mtx     sync.RWMutex
+}
+
+// memTx reads from the store, and keeps the writes until commit.
+type memTx struct {
+	store    *MemStore
+	writable bool
+	writes   map[string]map[string][]byte // bucket -> key -> value, nil value means deleted.
+}
+
+// NewMemStore creates empty memory store.
+func NewMemStore() *MemStore {
+	return &MemStore{buckets: make(map[string]map[string][]byte)}
+}
+
+// Update executes the function in a read-write transaction, the writes are applied
+// to the store only if the function returns nil.
+func (s *MemStore) Update(fn func(tx Tx) error) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	tx := &memTx{
+		store:    s,
+		writable: true,
+		writes:   make(map[string]map[string][]byte),
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	for bkt, kvs := range tx.writes {
+		if s.buckets[bkt] == nil {
+			s.buckets[bkt] = make(map[string][]byte)
+		}
+		for k, v := range kvs {
+			if v == nil {
+				delete(s.buckets[bkt], k)
+				continue
+			}
+			s.buckets[bkt][k] = v
+		}
+	}
+	return nil
+}
+
+// View executes the function in a read-only transaction.
+func (s *MemStore) View(fn func(tx Tx) error) error {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return fn(&memTx{store: s})
+}
+
+// Close does nothing.
+func (s *MemStore) Close() error {
+	return nil
+}
+
+func (t *memTx) Get(bucket string, key []byte) []byte {
+	if v, ok := t.writes[bucket][string(key)]; ok {
+		return copyBytes(v)
+	}
+	return copyBytes(t.store.buckets[bucket][string(key)])
+}
+
+func (t *memTx) Put(bucket string, key []byte, value []byte) error {
+	if !t.writable {
+		return errors.New("tx not writable")
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	t.write(bucket, key, copyBytes(value))
+	return nil
+}
+
+func (t *memTx) Delete(bucket string, key []byte) error {
+	if !t.writable {
+		return errors.New("tx not writable")
+	}
+	t.write(bucket, key, nil)
+	return nil
+}
+
+func (t *memTx) ForEach(bucket string, fn func(k, v []byte) error) error {
+	kvs := make(map[string][]byte)
+	for k, v := range t.store.buckets[bucket] {
+		kvs[k] = v
+	}
+	for k, v := range t.writes[bucket] {
+		kvs[k] = v
+	}
+
+	keys := make([]string, 0, len(kvs))
+	for k, v := range kvs {
+		if v != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := fn([]byte(k), copyBytes(kvs[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *memTx) write(bucket string, key []byte, value []byte) {
+	if t.writes[bucket] == nil {
+		t.writes[bucket] = make(map[string][]byte)
+	}
+	t.writes[bucket][string(key)] = value
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
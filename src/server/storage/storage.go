@@ -0,0 +1,56 @@
+// Package storage provides the transactional key value store used for persisting
+// the accounts, order books and id generators of the exchange.
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+)
+
+// Store is a transactional key value store, the values are grouped by buckets.
+type Store interface {
+	// Update executes the function in a read-write transaction, the changes are
+	// committed if the function returns nil, otherwise all of them are discarded.
+	Update(fn func(tx Tx) error) error
+	// View executes the function in a read-only transaction.
+	View(fn func(tx Tx) error) error
+	Close() error
+}
+
+// Tx is the transaction of store, it's only valid in the Update or View function.
+type Tx interface {
+	Get(bucket string, key []byte) []byte                    // returns nil if the key does not exist.
+	Put(bucket string, key []byte, value []byte) error       // the bucket will be created if not exist.
+	Delete(bucket string, key []byte) error                  // deletes the key, no error if not exist.
+	ForEach(bucket string, fn func(k, v []byte) error) error // iterates the keys in bytes order.
+}
+
+// Itob encodes the uint64 as big endian bytes, so the keys are iterated in numeric order.
+func Itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// Btoi decodes the big endian bytes created by Itob.
+func Btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
+
+// PutJSON encodes the value as json and puts it into the bucket.
+func PutJSON(tx Tx, bucket string, key []byte, v interface{}) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return tx.Put(bucket, key, d)
+}
+
+// GetJSON decodes the json value of the key, returns false if the key does not exist.
+func GetJSON(tx Tx, bucket string, key []byte, v interface{}) (bool, error) {
+	d := tx.Get(bucket, key)
+	if d == nil {
+		return false, nil
+	}
+	return true, json.Unmarshal(d, v)
+}
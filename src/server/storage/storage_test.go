@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testStore(t *testing.T, s Store) {
+	// the writes are committed.
+	err := s.Update(func(tx Tx) error {
+		for i := uint64(3); i > 0; i-- {
+			if err := PutJSON(tx, "orders", Itob(i), i*10); err != nil {
+				return err
+			}
+		}
+		return tx.Put("ids", []byte("bitcoin/skycoin"), Itob(3))
+	})
+	assert.Nil(t, err)
+
+	// the writes are discarded if error is returned.
+	err = s.Update(func(tx Tx) error {
+		assert.Nil(t, tx.Delete("orders", Itob(1)))
+		assert.Nil(t, tx.Put("ids", []byte("bitcoin/skycoin"), Itob(4)))
+		// the writes can be read in the same transaction.
+		assert.Equal(t, uint64(4), Btoi(tx.Get("ids", []byte("bitcoin/skycoin"))))
+		assert.Nil(t, tx.Get("orders", Itob(1)))
+		return errors.New("rollback")
+	})
+	assert.NotNil(t, err)
+
+	err = s.View(func(tx Tx) error {
+		assert.Equal(t, uint64(3), Btoi(tx.Get("ids", []byte("bitcoin/skycoin"))))
+		assert.Nil(t, tx.Get("ids", []byte("unknow")))
+		assert.Nil(t, tx.Get("unknow", []byte("unknow")))
+
+		var v uint64
+		ok, err := GetJSON(tx, "orders", Itob(1), &v)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(10), v)
+
+		// keys are iterated in order.
+		ids := []uint64{}
+		assert.Nil(t, tx.ForEach("orders", func(k, v []byte) error {
+			ids = append(ids, Btoi(k))
+			return nil
+		}))
+		assert.Equal(t, []uint64{1, 2, 3}, ids)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	err = s.Update(func(tx Tx) error {
+		return tx.Delete("orders", Itob(2))
+	})
+	assert.Nil(t, err)
+
+	err = s.View(func(tx Tx) error {
+		n := 0
+		tx.ForEach("orders", func(k, v []byte) error {
+			n++
+			return nil
+		})
+		assert.Equal(t, 2, n)
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func TestMemStore(t *testing.T) {
+	testStore(t, NewMemStore())
+}
+
+func TestBoltStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange-storage")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "exchange.db")
+	s, err := NewBoltStore(path)
+	assert.Nil(t, err)
+	testStore(t, s)
+	assert.Nil(t, s.Close())
+
+	// the committed data is persisted.
+	s, err = NewBoltStore(path)
+	assert.Nil(t, err)
+	defer s.Close()
+	s.View(func(tx Tx) error {
+		assert.Equal(t, uint64(3), Btoi(tx.Get("ids", []byte("bitcoin/skycoin"))))
+		assert.NotNil(t, tx.Get("orders", Itob(3)))
+		return nil
+	})
+}
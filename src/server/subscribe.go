@@ -0,0 +1,184 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+)
+
+var (
+	eventLevels = 20  // number of price levels of each side in the book event.
+	eventTrades = 100 // max number of trades in one trades event, so the event fits in one frame.
+)
+
+// The topics that can be subscribed, the account topic is resolved into the
+// topic of the account's pubkey.
+func bookTopic(cp string) string    { return "book:" + cp }
+func tradesTopic(cp string) string  { return "trades:" + cp }
+func accountTopic(id string) string { return "account:" + id }
+
+// GetHub returns the hub that pushes the events to the subscribed connections.
+func (serv *ExchangeServer) GetHub() *sknet.Hub {
+	return serv.hub
+}
+
+// ResolveTopics checks the topics, and returns the topics that the events are published on,
+// the account topic requires the pubkey of an existing account.
+func (serv *ExchangeServer) ResolveTopics(pubkey string, topics []string) ([]string, error) {
+	if len(topics) == 0 {
+		return nil, errors.New("empty topics")
+	}
+
+	ts := make([]string, len(topics))
+	for i, t := range topics {
+		if t == "account" {
+			if _, err := serv.GetAccount(pubkey); err != nil {
+				return nil, err
+			}
+			ts[i] = accountTopic(pubkey)
+			continue
+		}
+
+		ps := strings.SplitN(t, ":", 2)
+		if len(ps) != 2 || (ps[0] != "book" && ps[0] != "trades") {
+			return nil, fmt.Errorf("unknow topic:%s", t)
+		}
+
+		if !serv.orderManager.IsExist(ps[1]) {
+			return nil, fmt.Errorf("coin pair:%s not supported", ps[1])
+		}
+		ts[i] = t
+	}
+	return ts, nil
+}
+
+// bookNotifier publishes the book changes and the trades, it's called by the matching
+// goroutine, so the published depth is in the same order as the changes.
+type bookNotifier struct {
+	serv *ExchangeServer
+}
+
+// Notify publishes the depth of the book, the trades, and the fills of the accounts.
+func (bn bookNotifier) Notify(cp string, trades []order.Trade) {
+	hub := bn.serv.hub
+	bids, asks, err := bn.serv.orderManager.GetDepth(cp, eventLevels)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	hub.Publish(bookTopic(cp), &pp.BookEvent{
+		CoinPair: pp.PtrString(cp),
+		Bids:     makePriceLevels(bids),
+		Asks:     makePriceLevels(asks),
+	})
+
+	for i := 0; i < len(trades); i += eventTrades {
+		j := i + eventTrades
+		if j > len(trades) {
+			j = len(trades)
+		}
+
+		ev := pp.TradesEvent{CoinPair: pp.PtrString(cp)}
+		for _, t := range trades[i:j] {
+			ev.Trades = append(ev.Trades, &pp.Trade{
+				Id:        pp.PtrUint64(t.ID),
+				MakerId:   pp.PtrUint64(t.MakerID),
+				TakerId:   pp.PtrUint64(t.TakerID),
+				TakerType: pp.PtrString(t.TakerType.String()),
+				Price:     pp.PtrUint64(t.Price),
+				Amount:    pp.PtrUint64(t.Amount),
+				CreatedAt: pp.PtrInt64(t.CreatedAt),
+				BidFee:    pp.PtrUint64(t.BidFee),
+				AskFee:    pp.PtrUint64(t.AskFee),
+			})
+		}
+		hub.Publish(tradesTopic(cp), &ev)
+	}
+
+	for _, t := range trades {
+		hub.Publish(accountTopic(t.BidAccountID), makeFillEvent(cp, t, order.Bid))
+		hub.Publish(accountTopic(t.AskAccountID), makeFillEvent(cp, t, order.Ask))
+	}
+}
+
+// makeFillEvent returns the fill event of the order of specific type in the trade.
+func makeFillEvent(cp string, t order.Trade, tp order.Type) *pp.AccountEvent {
+	// the bidder pays the fee in main coin, and the asker in sub coin.
+	coins := strings.Split(cp, "/")
+	id, fee, feeCoin := t.BidID(), t.BidFee, coins[0]
+	if tp == order.Ask {
+		id, fee, feeCoin = t.AskID(), t.AskFee, coins[1]
+	}
+
+	return &pp.AccountEvent{
+		Type:     pp.PtrString("fill"),
+		CoinPair: pp.PtrString(cp),
+		Fill: &pp.Fill{
+			TradeId:   pp.PtrUint64(t.ID),
+			OrderId:   pp.PtrUint64(id),
+			Type:      pp.PtrString(tp.String()),
+			Maker:     pp.PtrBool(id == t.MakerID),
+			Price:     pp.PtrUint64(t.Price),
+			Amount:    pp.PtrUint64(t.Amount),
+			CreatedAt: pp.PtrInt64(t.CreatedAt),
+			Fee:       pp.PtrUint64(fee),
+			FeeCoin:   pp.PtrString(feeCoin),
+		},
+	}
+}
+
+// publishPostings publishes the balance changes of the committed postings to both accounts,
+// it's called inside the commit, so the balances can't be changed by other requests. Nobody
+// subscribes the system accounts, their events are dropped by the hub.
+func (serv *ExchangeServer) publishPostings(ps []account.Posting) {
+	// the balances before the postings.
+	bals := make(map[[2]string]int64)
+	for _, p := range ps {
+		bals[[2]string{p.Debit, p.CoinType}] += int64(p.Amount)
+		bals[[2]string{p.Credit, p.CoinType}] -= int64(p.Amount)
+	}
+	for k := range bals {
+		bals[k] += serv.Balance(k[0], k[1])
+	}
+
+	for _, p := range ps {
+		for _, id := range []string{p.Debit, p.Credit} {
+			amt := int64(p.Amount)
+			if id == p.Debit {
+				amt = -amt
+			}
+			k := [2]string{id, p.CoinType}
+			bals[k] += amt
+
+			serv.hub.Publish(accountTopic(id), &pp.AccountEvent{
+				Type:     pp.PtrString("balance"),
+				CoinType: pp.PtrString(p.CoinType),
+				Balance:  pp.PtrUint64(uint64(bals[k])),
+				History: &pp.AccountHistory{
+					Type:      pp.PtrString(string(p.Type)),
+					CoinType:  pp.PtrString(p.CoinType),
+					Amount:    pp.PtrInt64(amt),
+					Ref:       pp.PtrString(p.Ref),
+					CreatedAt: pp.PtrInt64(p.CreatedAt),
+				},
+			})
+		}
+	}
+}
+
+func makePriceLevels(pls []order.PriceLevel) []*pp.PriceLevel {
+	levels := make([]*pp.PriceLevel, len(pls))
+	for i := range pls {
+		levels[i] = &pp.PriceLevel{
+			Price:  pp.PtrUint64(pls[i].Price),
+			Amount: pp.PtrUint64(pls[i].Amount),
+		}
+	}
+	return levels
+}
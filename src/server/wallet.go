@@ -13,32 +13,27 @@ type wallets struct {
 	ids map[string]string // key wallet type, value wallet id.
 }
 
-type walletItem struct {
-	Type string // coin type
-	Seed string // seed
-}
-
 var initWalletOnce sync.Once
 
-func makeWallets(dir string, items []walletItem) (wallets, error) {
+func makeWallets(dir string) wallets {
 	f := func() {
 		logger.Debug("wallet dir:%s", dir)
 		wallet.InitDir(dir)
 	}
 	initWalletOnce.Do(f)
-	wlts := wallets{ids: make(map[string]string)}
-	// create wallets if not exist.
-	for _, item := range items {
-		id := wallet.MakeWltID(item.Type, item.Seed)
-		if !wallet.IsExist(id) {
-			_, err := wallet.New(item.Type, item.Seed)
-			if err != nil {
-				return wallets{}, err
-			}
+	return wallets{ids: make(map[string]string)}
+}
+
+// add creates the wallet of specific coin type if not exist.
+func (wlts *wallets) add(cp string, seed string) error {
+	id := wallet.MakeWltID(cp, seed)
+	if !wallet.IsExist(id) {
+		if _, err := wallet.New(cp, seed); err != nil {
+			return err
 		}
-		wlts.ids[item.Type] = id
 	}
-	return wlts, nil
+	wlts.ids[cp] = id
+	return nil
 }
 
 // NewAddresses create specific coin addresses.
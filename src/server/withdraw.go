@@ -0,0 +1,54 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+)
+
+// GetWithdrawPolicy returns the withdrawal rules of specific coin type,
+// the coins that are not configured have no fee and no limits.
+func (serv *ExchangeServer) GetWithdrawPolicy(ct string) account.WithdrawPolicy {
+	return serv.policies[ct]
+}
+
+// GetWithdrawPolicies returns the withdrawal rules of all bound coins.
+func (serv *ExchangeServer) GetWithdrawPolicies() map[string]account.WithdrawPolicy {
+	ps := make(map[string]account.WithdrawPolicy, len(serv.coins))
+	for ct := range serv.coins {
+		ps[ct] = serv.GetWithdrawPolicy(ct)
+	}
+	return ps
+}
+
+// HoldWithdrawal checks the withdrawal against the policy of the coin, then holds the amount and
+// the fee from the account's balance, and returns the fee. The check and the hold are done under
+// the commitMtx, so concurrent withdrawals of one account can't exceed the daily cap together.
+func (serv *ExchangeServer) HoldWithdrawal(id string, ct string, amt uint64) (uint64, error) {
+	if _, ok := serv.coins[ct]; !ok {
+		return 0, fmt.Errorf("%s coin is not supported", ct)
+	}
+
+	serv.commitMtx.Lock()
+	defer serv.commitMtx.Unlock()
+	a, err := serv.GetAccount(id)
+	if err != nil {
+		return 0, err
+	}
+
+	p := serv.GetWithdrawPolicy(ct)
+	since := time.Now().Add(-account.WithdrawPeriod).Unix()
+	if err := p.Check(amt, a.GetWithdrawn(ct, since)); err != nil {
+		return 0, err
+	}
+
+	total, err := p.Total(amt)
+	if err != nil {
+		return 0, err
+	}
+	if err := serv.Post(account.NewPosting(account.HistoryHold, id, account.HoldAccount, ct, total, "")); err != nil {
+		return 0, err
+	}
+	return p.Fee, nil
+}
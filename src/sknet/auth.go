@@ -10,7 +10,9 @@ import (
 )
 
 // Authorize will decrypt the request, and it's a buildin middleware for skynet.
+// The requests that are stale or have been received are rejected.
 func Authorize(servSeckey string) HandlerFunc {
+	cache := newReplayCache(ReplayWindow)
 	return func(c *Context) error {
 		var (
 			req pp.EncryptReq
@@ -42,18 +44,27 @@ func Authorize(servSeckey string) HandlerFunc {
 					break
 				}
 
-				key := cipher.ECDH(pubkey, seckey)
-				data, err := cipher.Chacha20Decrypt(req.GetEncryptdata(), key, req.GetNonce())
+				// the Version2 data is authenticated with the path, the legacy data can only be
+				// checked by its format.
+				ver, path := c.Request.Version, c.Request.GetPath()
+				data, err := decryptData(ver, path, req.GetEncryptdata(), req.GetNonce(), c.Pubkey, seckey.Hex())
 				if err != nil {
 					logger.Error(err.Error())
 					rlt = pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized)
 					break
 				}
 
-				ok, err := regexp.MatchString(`^\{.*\}$`, string(data))
-				if err != nil || !ok {
-					logger.Error(err.Error())
-					rlt = pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized)
+				if ver == Version1 {
+					if ok, _ := regexp.MatchString(`^\{.*\}$`, string(data)); !ok {
+						logger.Error("invalid request data from %s", c.Pubkey)
+						rlt = pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized)
+						break
+					}
+				}
+
+				if err := cache.check(c.Pubkey, data); err != nil {
+					logger.Error("%s request from %s: %v", c.Request.GetPath(), c.Pubkey, err)
+					rlt = &pp.EmptyRes{Result: pp.MakeResult(pp.ErrCode_ReplayedRequest, err.Error())}
 					break
 				}
 
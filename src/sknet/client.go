@@ -16,20 +16,24 @@ func init() {
 	gSeckey = s.Hex()
 }
 
-// Get send request to server, then read response and return.
+// Get send request to server in the frame of Version1, then read response and return.
 func Get(addr string, path string, v interface{}) (*Response, error) {
 	c, err := net.Dial("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
 	defer c.Close()
+	return send(c, Version1, path, v)
+}
 
+// send writes the request in the frame of the version to the connection, then reads the response.
+func send(c net.Conn, ver uint32, path string, v interface{}) (*Response, error) {
 	r, err := MakeRequest(path, v)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := Write(c, r); err != nil {
+	if err := WriteVersion(c, ver, r); err != nil {
 		return nil, err
 	}
 
@@ -40,24 +44,67 @@ func Get(addr string, path string, v interface{}) (*Response, error) {
 	return &rsp, nil
 }
 
-// EncryGet will encrypt the request and decrypt the response.
+// EncryGet will encrypt the request and decrypt the response, the request is sent on the
+// persistent connections of the shared client of the server address.
 func EncryGet(addr string, path string, req interface{}, res interface{}) error {
+	return GetClient(addr).EncryGet(path, req, res)
+}
+
+// Subscription is the connection that receives the events pushed by server.
+type Subscription struct {
+	c    net.Conn
+	path string // path of the subscribe request.
+}
+
+// Subscribe sends the encrypted subscribe request like EncryGet on a dedicated connection,
+// which is kept open for receiving the events of the subscribed topics.
+func Subscribe(addr string, path string, req interface{}, res interface{}) (*Subscription, error) {
 	if gSeckey == "" {
-		return errors.New("private key is empty")
+		return nil, errors.New("private key is empty")
 	}
 
-	encReq, err := encrypt(req, gPubkey, gSeckey)
+	c, ver, err := dial(addr)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := Get(addr, path, encReq)
+	encReq, err := encrypt(ver, path, req, gPubkey, gSeckey)
 	if err != nil {
-		return err
+		c.Close()
+		return nil, err
+	}
+
+	resp, err := send(c, ver, path, encReq)
+	if err != nil {
+		forgetVersion(addr)
+		c.Close()
+		return nil, err
 	}
 
-	// decode the response.
-	return decrypt(resp.Body, gPubkey, gSeckey, res)
+	if err := decrypt(resp.Version, path, resp.Body, gPubkey, gSeckey, res); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &Subscription{c: c, path: path}, nil
+}
+
+// Next blocks until the next event arrives, error will be returned once the connection is closed.
+func (s *Subscription) Next() (Event, error) {
+	rsp := Response{}
+	if err := Read(s.c, &rsp); err != nil {
+		return Event{}, err
+	}
+
+	ev := Event{}
+	if err := decrypt(rsp.Version, s.path, rsp.Body, gPubkey, gSeckey, &ev); err != nil {
+		return Event{}, err
+	}
+	return ev, nil
+}
+
+// Close closes the connection, the blocked Next will return.
+func (s *Subscription) Close() error {
+	return s.c.Close()
 }
 
 // SetPubkey updates the server's pubkey
@@ -0,0 +1,378 @@
+package sknet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+var (
+	// DialTimeout is the timeout of connecting to the server.
+	DialTimeout = 5 * time.Second
+	// RequestTimeout is the timeout of waiting for the response of a request.
+	RequestTimeout = 30 * time.Second
+	// PingInterval is the interval of pinging the idle connections, so the broken ones are closed early.
+	PingInterval = 30 * time.Second
+	// MaxConns is the max number of connections of a client to one server.
+	MaxConns = 4
+
+	minBackoff = 100 * time.Millisecond // the first delay of reconnecting after a failed dial.
+	maxBackoff = 10 * time.Second       // the delay doubles on each failure up to it.
+
+	// ErrTimeout will be returned if the response doesn't arrive in RequestTimeout.
+	ErrTimeout = errors.New("request timeout")
+	// ErrClientClosed will be returned if the client is closed.
+	ErrClientClosed = errors.New("client closed")
+)
+
+// Client keeps persistent connections to one server. The requests are multiplexed on the
+// connections of Version3 with request ids, and sent one by one on the older ones.
+type Client struct {
+	addr    string
+	ping    time.Duration // the PingInterval when the client is created.
+	conns   []*clientConn
+	backoff time.Duration // delay before the next dial after failures.
+	retryAt time.Time     // no dial before it.
+	dialErr error         // error of the last failed dial.
+	closing chan struct{}
+	closed  bool
+	mtx     sync.Mutex
+}
+
+// NewClient creates the client of the server address, the connections are created on demand.
+func NewClient(addr string) *Client {
+	cl := &Client{addr: addr, ping: PingInterval, closing: make(chan struct{})}
+	go cl.keepAlive()
+	return cl
+}
+
+var clients = struct {
+	m   map[string]*Client
+	mtx sync.Mutex
+}{m: make(map[string]*Client)}
+
+// GetClient returns the client shared by the requests to the server address, it's created on first use.
+func GetClient(addr string) *Client {
+	clients.mtx.Lock()
+	defer clients.mtx.Unlock()
+	cl, ok := clients.m[addr]
+	if !ok {
+		cl = NewClient(addr)
+		clients.m[addr] = cl
+	}
+	return cl
+}
+
+// EncryGet encrypts the request, and decrypts the response into res.
+func (cl *Client) EncryGet(path string, req interface{}, res interface{}) error {
+	if gSeckey == "" {
+		return errors.New("private key is empty")
+	}
+
+	cc, err := cl.getConn()
+	if err != nil {
+		return err
+	}
+
+	encReq, err := encrypt(cc.ver, path, req, gPubkey, gSeckey)
+	if err != nil {
+		return err
+	}
+
+	resp, err := cc.roundTrip(path, encReq, RequestTimeout)
+	if err != nil {
+		return err
+	}
+
+	// decode the response.
+	return decrypt(resp.Version, path, resp.Body, gPubkey, gSeckey, res)
+}
+
+// Close closes the connections, the requests in flight return with error.
+func (cl *Client) Close() error {
+	cl.mtx.Lock()
+	defer cl.mtx.Unlock()
+	if cl.closed {
+		return nil
+	}
+
+	cl.closed = true
+	close(cl.closing)
+	for _, cc := range cl.conns {
+		cc.close(ErrClientClosed)
+	}
+	cl.conns = nil
+	return nil
+}
+
+// getConn returns the connection of the least requests in flight, a new connection is
+// created if all are busy and there're less than MaxConns. The failed dials are retried
+// with exponential backoff.
+func (cl *Client) getConn() (*clientConn, error) {
+	cl.mtx.Lock()
+	defer cl.mtx.Unlock()
+	if cl.closed {
+		return nil, ErrClientClosed
+	}
+
+	// remove the broken connections.
+	conns := cl.conns[:0]
+	for _, cc := range cl.conns {
+		if cc.getErr() == nil {
+			conns = append(conns, cc)
+		}
+	}
+	if len(conns) < len(cl.conns) {
+		// the server may be upgraded or downgraded.
+		forgetVersion(cl.addr)
+	}
+	cl.conns = conns
+
+	var best *clientConn
+	var load int
+	for _, cc := range cl.conns {
+		if n := cc.getLoad(); best == nil || n < load {
+			best, load = cc, n
+		}
+	}
+
+	if best != nil && (load == 0 || len(cl.conns) >= MaxConns) {
+		return best, nil
+	}
+
+	if now := time.Now(); now.Before(cl.retryAt) {
+		if best != nil {
+			return best, nil
+		}
+		return nil, fmt.Errorf("connect %s failed: %v, retry in %v", cl.addr, cl.dialErr, cl.retryAt.Sub(now))
+	}
+
+	c, ver, err := dial(cl.addr)
+	if err != nil {
+		if cl.backoff == 0 {
+			cl.backoff = minBackoff
+		} else if cl.backoff *= 2; cl.backoff > maxBackoff {
+			cl.backoff = maxBackoff
+		}
+		cl.retryAt = time.Now().Add(cl.backoff)
+		cl.dialErr = err
+		logger.Error("connect %s failed: %v, retry in %v", cl.addr, err, cl.backoff)
+		if best != nil {
+			return best, nil
+		}
+		return nil, err
+	}
+
+	cl.backoff = 0
+	cc := newClientConn(c, ver)
+	cl.conns = append(cl.conns, cc)
+	return cc, nil
+}
+
+// keepAlive pings the idle connections of Version3 every PingInterval,
+// the connections that don't reply in time are closed.
+func (cl *Client) keepAlive() {
+	t := time.NewTicker(cl.ping)
+	defer t.Stop()
+	for {
+		select {
+		case <-cl.closing:
+			return
+		case <-t.C:
+			cl.mtx.Lock()
+			conns := append([]*clientConn{}, cl.conns...)
+			cl.mtx.Unlock()
+
+			for _, cc := range conns {
+				if cc.ver >= Version3 && cc.idle() >= cl.ping {
+					go cc.ping()
+				}
+			}
+		}
+	}
+}
+
+// clientConn is one connection of the client, the responses are read by the readLoop,
+// and dispatched to the requests by id.
+type clientConn struct {
+	c        net.Conn
+	ver      uint32
+	sem      chan struct{}             // one request at a time before Version3, as the responses have no id.
+	pending  map[uint32]chan *Response // request id => response channel.
+	nextID   uint32
+	load     int       // number of requests in flight or waiting.
+	lastUsed time.Time // time of the last response.
+	err      error     // set once the connection is broken.
+	done     chan struct{}
+	mtx      sync.Mutex
+	wmtx     sync.Mutex // the frames are written one by one.
+}
+
+func newClientConn(c net.Conn, ver uint32) *clientConn {
+	cc := &clientConn{
+		c:        c,
+		ver:      ver,
+		pending:  make(map[uint32]chan *Response),
+		lastUsed: time.Now(),
+		done:     make(chan struct{}),
+	}
+	if ver < Version3 {
+		cc.sem = make(chan struct{}, 1)
+	}
+	go cc.readLoop()
+	return cc
+}
+
+// roundTrip sends the request, and waits for its response until timeout.
+func (cc *clientConn) roundTrip(path string, v interface{}, timeout time.Duration) (*Response, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	cc.mtx.Lock()
+	if cc.err != nil {
+		cc.mtx.Unlock()
+		return nil, cc.err
+	}
+	cc.load++
+	cc.mtx.Unlock()
+	defer func() {
+		cc.mtx.Lock()
+		cc.load--
+		cc.mtx.Unlock()
+	}()
+
+	if cc.sem != nil {
+		select {
+		case cc.sem <- struct{}{}:
+			defer func() { <-cc.sem }()
+		case <-timer.C:
+			return nil, ErrTimeout
+		case <-cc.done:
+			return nil, cc.getErr()
+		}
+	}
+
+	r, err := MakeRequest(path, v)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *Response, 1)
+	cc.mtx.Lock()
+	var id uint32
+	if cc.sem == nil {
+		cc.nextID++
+		id = cc.nextID
+	}
+	cc.pending[id] = ch
+	cc.mtx.Unlock()
+
+	cc.wmtx.Lock()
+	cc.c.SetWriteDeadline(time.Now().Add(timeout))
+	err = writeFrame(cc.c, cc.ver, id, r)
+	cc.wmtx.Unlock()
+	if err != nil {
+		cc.close(err)
+		return nil, err
+	}
+
+	select {
+	case rsp := <-ch:
+		return rsp, nil
+	case <-timer.C:
+		cc.mtx.Lock()
+		delete(cc.pending, id)
+		cc.mtx.Unlock()
+		if cc.sem != nil {
+			// the late response would be taken as the next request's.
+			cc.close(ErrTimeout)
+		}
+		return nil, ErrTimeout
+	case <-cc.done:
+		// the response may arrive right before the connection is closed.
+		select {
+		case rsp := <-ch:
+			return rsp, nil
+		default:
+		}
+		return nil, cc.getErr()
+	}
+}
+
+// readLoop reads the responses until the connection is broken.
+func (cc *clientConn) readLoop() {
+	for {
+		rsp := &Response{}
+		if err := Read(cc.c, rsp); err != nil {
+			cc.close(err)
+			return
+		}
+
+		cc.mtx.Lock()
+		ch, ok := cc.pending[rsp.ID]
+		delete(cc.pending, rsp.ID)
+		cc.lastUsed = time.Now()
+		cc.mtx.Unlock()
+		if !ok {
+			logger.Debug("response of unknown request %d from %s", rsp.ID, cc.c.RemoteAddr())
+			continue
+		}
+		ch <- rsp
+	}
+}
+
+// ping sends the keep-alive ping, the connection is closed if it fails.
+func (cc *clientConn) ping() {
+	rsp, err := cc.roundTrip(pingPath, nil, RequestTimeout)
+	if err == nil {
+		res := pp.EmptyRes{}
+		if err = json.NewDecoder(rsp.Body).Decode(&res); err == nil && !res.Result.GetSuccess() {
+			err = fmt.Errorf("ping failed: %v", res.Result.GetReason())
+		}
+	}
+
+	if err != nil {
+		logger.Debug("ping %s failed: %v", cc.c.RemoteAddr(), err)
+		cc.close(err)
+	}
+}
+
+// close closes the connection, the waiting requests return with the error.
+func (cc *clientConn) close(err error) {
+	cc.mtx.Lock()
+	defer cc.mtx.Unlock()
+	if cc.err != nil {
+		return
+	}
+
+	cc.err = err
+	close(cc.done)
+	cc.c.Close()
+}
+
+func (cc *clientConn) getErr() error {
+	cc.mtx.Lock()
+	defer cc.mtx.Unlock()
+	return cc.err
+}
+
+func (cc *clientConn) getLoad() int {
+	cc.mtx.Lock()
+	defer cc.mtx.Unlock()
+	return cc.load
+}
+
+// idle returns how long the connection has no requests.
+func (cc *clientConn) idle() time.Duration {
+	cc.mtx.Lock()
+	defer cc.mtx.Unlock()
+	if cc.load > 0 {
+		return 0
+	}
+	return time.Since(cc.lastUsed)
+}
@@ -0,0 +1,241 @@
+package sknet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/stretchr/testify/assert"
+)
+
+type sleepReq struct {
+	A     string `json:"a"`
+	Sleep int    `json:"sleep"` // milliseconds.
+}
+
+// newSleepEngine creates the engine whose /sleep handler echoes the request after sleeping,
+// the returned function stops its workers.
+func newSleepEngine(opts Options) (*Engine, func()) {
+	pk, sk := cipher.GenerateKeyPair()
+	SetPubkey(pk.Hex())
+
+	quit := make(chan bool)
+	e := NewWithOptions(sk.Hex(), quit, opts)
+	e.Register("/sleep", func(c *Context) error {
+		var req sleepReq
+		if err := c.BindJSON(&req); err != nil {
+			return err
+		}
+		time.Sleep(time.Duration(req.Sleep) * time.Millisecond)
+		return c.SendJSON(&req)
+	})
+	return e, func() { close(quit) }
+}
+
+// startSleepEngine serves the sleep engine with the DefaultOptions.
+func startSleepEngine(t *testing.T) (string, func()) {
+	e, quit := newSleepEngine(DefaultOptions)
+	addr, stop := startTestEngine(t, e)
+	return addr, func() {
+		stop()
+		quit()
+		forgetVersion(addr)
+	}
+}
+
+func TestClientMultiplex(t *testing.T) {
+	defer SetPubkey(gPubkey)
+	defer func(n int) { MaxConns = n }(MaxConns)
+	MaxConns = 1
+
+	addr, stop := startSleepEngine(t)
+	defer stop()
+	cl := NewClient(addr)
+	defer cl.Close()
+
+	// the slow request doesn't block the others on the same connection.
+	slow := make(chan time.Time)
+	go func() {
+		var res sleepReq
+		assert.Nil(t, cl.EncryGet("/sleep", sleepReq{A: "slow", Sleep: 500}, &res))
+		assert.Equal(t, "slow", res.A)
+		slow <- time.Now()
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var res sleepReq
+			a := fmt.Sprintf("%d", i)
+			assert.Nil(t, cl.EncryGet("/sleep", sleepReq{A: a}, &res))
+			assert.Equal(t, a, res.A)
+		}(i)
+	}
+	wg.Wait()
+	fast := time.Now()
+	assert.True(t, fast.Before(<-slow))
+
+	cl.mtx.Lock()
+	assert.Equal(t, 1, len(cl.conns))
+	assert.Equal(t, Version3, cl.conns[0].ver)
+	cl.mtx.Unlock()
+}
+
+// startRawServer serves the frames of Version3 without encryption, the response of /slow is
+// delayed, so the timeouts are tested without the cost of the encryption.
+func startRawServer(t *testing.T) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				var mtx sync.Mutex
+				for {
+					r := &Request{}
+					if err := Read(c, r); err != nil {
+						return
+					}
+					go func() {
+						if r.GetPath() == "/slow" {
+							time.Sleep(200 * time.Millisecond)
+						}
+						mtx.Lock()
+						defer mtx.Unlock()
+						writeFrame(c, r.Version, r.ID, r.GetPath())
+					}()
+				}
+			}()
+		}
+	}()
+	return l.Addr().String(), func() { l.Close() }
+}
+
+func TestClientTimeout(t *testing.T) {
+	addr, stop := startRawServer(t)
+	defer stop()
+	c, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	cc := newClientConn(c, Version3)
+	defer cc.close(ErrClientClosed)
+
+	_, err = cc.roundTrip("/slow", nil, 100*time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+
+	// the connection is still usable, the late response is dropped.
+	time.Sleep(150 * time.Millisecond)
+	rsp, err := cc.roundTrip("/fast", nil, 100*time.Millisecond)
+	assert.Nil(t, err)
+	var path string
+	assert.Nil(t, json.NewDecoder(rsp.Body).Decode(&path))
+	assert.Equal(t, "/fast", path)
+	assert.Nil(t, cc.getErr())
+
+	// the connection before Version3 is closed, as the late response can't be told apart.
+	c, err = net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	cc = newClientConn(c, Version2)
+	defer cc.close(ErrClientClosed)
+	_, err = cc.roundTrip("/slow", nil, 100*time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+	assert.Equal(t, ErrTimeout, cc.getErr())
+}
+
+// TestClientVersion2 checks the requests are sent one by one on the connections of old version.
+func TestClientVersion2(t *testing.T) {
+	defer SetPubkey(gPubkey)
+	defer func(n int) { MaxConns = n }(MaxConns)
+	MaxConns = 1
+
+	addr, stop := startSleepEngine(t)
+	defer stop()
+
+	// negotiate as an old client.
+	c, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	ver, err := requestVersion(c, []uint32{Version1, Version2})
+	assert.Nil(t, err)
+	assert.Equal(t, Version2, ver)
+
+	cl := NewClient(addr)
+	defer cl.Close()
+	cl.conns = append(cl.conns, newClientConn(c, ver))
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var res sleepReq
+			a := fmt.Sprintf("%d", i)
+			assert.Nil(t, cl.EncryGet("/sleep", sleepReq{A: a, Sleep: 10}, &res))
+			assert.Equal(t, a, res.A)
+		}(i)
+	}
+	wg.Wait()
+	cl.mtx.Lock()
+	assert.Equal(t, Version2, cl.conns[0].ver)
+	cl.mtx.Unlock()
+}
+
+func TestClientBackoff(t *testing.T) {
+	defer func(tm time.Duration) { minBackoff = tm }(minBackoff)
+	minBackoff = 50 * time.Millisecond
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	cl := NewClient(addr)
+	defer cl.Close()
+	_, err = cl.getConn()
+	assert.NotNil(t, err)
+	assert.Equal(t, minBackoff, cl.backoff)
+
+	// no dial until the retry time.
+	_, err = cl.getConn()
+	assert.Contains(t, err.Error(), "retry in")
+	assert.Equal(t, minBackoff, cl.backoff)
+
+	// the backoff doubles on the next failure.
+	time.Sleep(minBackoff)
+	_, err = cl.getConn()
+	assert.NotNil(t, err)
+	assert.Equal(t, 2*minBackoff, cl.backoff)
+
+	cl.Close()
+	_, err = cl.getConn()
+	assert.Equal(t, ErrClientClosed, err)
+}
+
+func TestClientPing(t *testing.T) {
+	defer SetPubkey(gPubkey)
+	defer func(tm time.Duration) { PingInterval = tm }(PingInterval)
+	PingInterval = 20 * time.Millisecond
+
+	addr, stop := startSleepEngine(t)
+	defer stop()
+	cl := NewClient(addr)
+	defer cl.Close()
+
+	var res sleepReq
+	assert.Nil(t, cl.EncryGet("/sleep", sleepReq{A: "a"}, &res))
+	cc, err := cl.getConn()
+	assert.Nil(t, err)
+
+	// the pings keep the idle connection alive.
+	time.Sleep(100 * time.Millisecond)
+	assert.Nil(t, cc.getErr())
+	assert.True(t, cc.idle() < 100*time.Millisecond)
+}
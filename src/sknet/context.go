@@ -3,8 +3,6 @@ package sknet
 import (
 	"encoding/json"
 	"fmt"
-
-	"github.com/skycoin/skycoin-exchange/src/pp"
 )
 
 // ResponseWriter interface for writing response.
@@ -26,18 +24,12 @@ type Context struct {
 	Data       map[string]interface{} // data map, for transafer data between handlers.
 }
 
-// JSON encrypt the data and write response.
+// JSON encrypt the data in the format of the request's version and write response.
 func (c *Context) SendJSON(data interface{}) error {
-	encData, nonce, err := pp.Encrypt(data, c.Pubkey, c.ServSeckey)
+	res, err := encryptRes(c.Request.Version, c.Request.GetPath(), data, c.Pubkey, c.ServSeckey)
 	if err != nil {
 		return err
 	}
-
-	res := pp.EncryptRes{
-		Result:      pp.MakeResultWithCode(pp.ErrCode_Success),
-		Encryptdata: encData,
-		Nonce:       nonce,
-	}
 	return c.Resp.SendJSON(res)
 }
 
@@ -2,16 +2,25 @@ package sknet
 
 import (
 	"encoding/json"
-	"io"
-
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/skycoin/skycoin-exchange/src/pp"
 	"github.com/skycoin/skycoin/src/cipher"
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
-func encrypt(r interface{}, pubkey string, seckey string) (*pp.EncryptReq, error) {
-	encData, nonce, err := pp.Encrypt(r, pubkey, seckey)
+// encrypt stamps the request against replay, and encrypts it with the server's pubkey
+// in the format of the version, the path is authenticated since Version2.
+func encrypt(ver uint32, path string, r interface{}, pubkey string, seckey string) (*pp.EncryptReq, error) {
+	sr, err := stampReq(r)
+	if err != nil {
+		return nil, err
+	}
+
+	encData, nonce, err := encryptData(ver, path, sr, pubkey, seckey)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +38,22 @@ func encrypt(r interface{}, pubkey string, seckey string) (*pp.EncryptReq, error
 	}, nil
 }
 
-func decrypt(r io.Reader, pubkey string, seckey string, v interface{}) error {
+// encryptRes encrypts the data with the client's pubkey, and wraps it in the success response.
+func encryptRes(ver uint32, path string, data interface{}, pubkey string, seckey string) (*pp.EncryptRes, error) {
+	encData, nonce, err := encryptData(ver, path, data, pubkey, seckey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pp.EncryptRes{
+		Result:      pp.MakeResultWithCode(pp.ErrCode_Success),
+		Encryptdata: encData,
+		Nonce:       nonce,
+	}, nil
+}
+
+// decrypt reads the response of the version, and decrypts the data into v.
+func decrypt(ver uint32, path string, r io.Reader, pubkey string, seckey string, v interface{}) error {
 	res := pp.EncryptRes{}
 	if err := json.NewDecoder(r).Decode(&res); err != nil {
 		return err
@@ -37,9 +61,15 @@ func decrypt(r io.Reader, pubkey string, seckey string, v interface{}) error {
 
 	// handle the response
 	if !res.Result.GetSuccess() {
+		if res.Result.GetErrcode() == int32(pp.ErrCode_RateLimited) {
+			return &RateLimitError{
+				Reason:     res.Result.GetReason(),
+				RetryAfter: time.Duration(res.Result.GetRetryAfter()) * time.Millisecond,
+			}
+		}
 		return fmt.Errorf("%v", res.Result.GetReason())
 	}
-	d, err := pp.Decrypt(res.Encryptdata, res.GetNonce(), pubkey, seckey)
+	d, err := decryptData(ver, path, res.Encryptdata, res.GetNonce(), pubkey, seckey)
 	if err != nil {
 		return err
 	}
@@ -49,3 +79,89 @@ func decrypt(r io.Reader, pubkey string, seckey string, v interface{}) error {
 	}
 	return nil
 }
+
+// encryptData marshals the value into json, and encrypts it in the format of the version.
+func encryptData(ver uint32, path string, v interface{}, pubkey string, seckey string) ([]byte, []byte, error) {
+	if ver == Version1 {
+		return pp.Encrypt(v, pubkey, seckey)
+	}
+
+	d, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := sharedKey(pubkey, seckey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := cipher.RandByte(chacha20poly1305.NonceSize)
+	data, err := seal(key, nonce, []byte(path), d)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, nonce, nil
+}
+
+// decryptData decrypts the data in the format of the version, the Version2 data fails
+// to decrypt if it was modified, or sent to another path.
+func decryptData(ver uint32, path string, data []byte, nonce []byte, pubkey string, seckey string) ([]byte, error) {
+	if ver == Version1 {
+		return pp.Decrypt(data, nonce, pubkey, seckey)
+	}
+
+	key, err := sharedKey(pubkey, seckey)
+	if err != nil {
+		return nil, err
+	}
+	return open(key, nonce, []byte(path), data)
+}
+
+// sharedKey returns the ECDH key of the pubkey and seckey.
+func sharedKey(pubkey string, seckey string) ([]byte, error) {
+	if err := validatePubkey(pubkey); err != nil {
+		return nil, err
+	}
+
+	p, err := cipher.PubKeyFromHex(pubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := cipher.SecKeyFromHex(seckey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Verify(); err != nil {
+		return nil, err
+	}
+	return cipher.ECDH(p, s), nil
+}
+
+// seal encrypts and authenticates the data, and authenticates the ad, by chacha20-poly1305.
+func seal(key []byte, nonce []byte, ad []byte, data []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+	return aead.Seal(nil, nonce, data, ad), nil
+}
+
+// open decrypts the data sealed with the same key, nonce and ad.
+func open(key []byte, nonce []byte, ad []byte, data []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+	return aead.Open(nil, nonce, data, ad)
+}
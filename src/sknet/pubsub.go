@@ -0,0 +1,146 @@
+package sknet
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+var subQueueSize = 256 // number of events that can be queued for one subscriber.
+
+// Event is pushed to the subscribers of the topic, Data is the json of the published value.
+type Event struct {
+	Topic string          `json:"topic"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Hub dispatches the events published on topics to the connections that subscribed them,
+// the events are encrypted for each connection, and pushed on the same connection.
+type Hub struct {
+	subs map[string]map[*subscriber]bool // topic -> subscribers.
+	mtx  sync.Mutex
+}
+
+// subscriber is one subscription of a connection.
+type subscriber struct {
+	w      *Response
+	ver    uint32 // protocol version of the subscribe request.
+	path   string // path of the subscribe request, the events are encrypted with it.
+	pubkey string // client pubkey.
+	seckey string // server seckey.
+	topics []string
+	events chan Event
+}
+
+// NewHub creates an empty hub.
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string]map[*subscriber]bool)}
+}
+
+// Subscribe sends the response, then pushes the events of the topics on the connection of the
+// context until it's closed, the events published before the response is sent are queued.
+func (h *Hub) Subscribe(c *Context, res interface{}, topics ...string) error {
+	w, ok := c.Resp.(*Response)
+	if !ok {
+		return errors.New("the response writer does not support subscription")
+	}
+
+	s := &subscriber{
+		w:      w,
+		ver:    c.Request.Version,
+		path:   c.Request.GetPath(),
+		pubkey: c.Pubkey,
+		seckey: c.ServSeckey,
+		topics: topics,
+		events: make(chan Event, subQueueSize),
+	}
+
+	h.mtx.Lock()
+	for _, t := range topics {
+		if h.subs[t] == nil {
+			h.subs[t] = make(map[*subscriber]bool)
+		}
+		h.subs[t][s] = true
+	}
+	h.mtx.Unlock()
+
+	// the connection is kept open for the events, though no requests come.
+	if w.sc != nil {
+		w.sc.stream()
+	}
+
+	if err := c.SendJSON(res); err != nil {
+		h.unsubscribe(s)
+		return err
+	}
+
+	go h.push(s)
+	return nil
+}
+
+// Publish queues the value to the subscribers of the topic. The subscriber whose queue
+// is full can't catch up with the events, its connection will be closed.
+func (h *Hub) Publish(topic string, v interface{}) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	subs := h.subs[topic]
+	if len(subs) == 0 {
+		return
+	}
+
+	d, err := json.Marshal(v)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	ev := Event{Topic: topic, Data: d}
+	for s := range subs {
+		select {
+		case s.events <- ev:
+		default:
+			logger.Warning("subscriber %s is too slow, close the connection", s.w.c.RemoteAddr())
+			h.remove(s)
+			s.w.c.Close()
+		}
+	}
+}
+
+// push writes the queued events of the subscriber until the connection is closed.
+func (h *Hub) push(s *subscriber) {
+	defer h.unsubscribe(s)
+	for {
+		select {
+		case <-s.w.done:
+			return
+		case ev := <-s.events:
+			res, err := encryptRes(s.ver, s.path, ev, s.pubkey, s.seckey)
+			if err != nil {
+				logger.Error(err.Error())
+				return
+			}
+
+			if err := s.w.SendJSON(res); err != nil {
+				logger.Debug("push event to %s failed: %v", s.w.c.RemoteAddr(), err)
+				s.w.c.Close()
+				return
+			}
+		}
+	}
+}
+
+func (h *Hub) unsubscribe(s *subscriber) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.remove(s)
+}
+
+// remove removes the subscriber from its topics, the caller must hold the mtx.
+func (h *Hub) remove(s *subscriber) {
+	for _, t := range s.topics {
+		delete(h.subs[t], s)
+		if len(h.subs[t]) == 0 {
+			delete(h.subs, t)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package sknet
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/stretchr/testify/assert"
+)
+
+// startTestEngine serves the engine on a random local port, the returned function stops it.
+func startTestEngine(t *testing.T, e *Engine) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go e.Serve(l)
+	return l.Addr().String(), func() { l.Close() }
+}
+
+func TestSubscribe(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	defer SetPubkey(gPubkey)
+	SetPubkey(pk.Hex())
+
+	quit := make(chan bool)
+	defer close(quit)
+	e := New(sk.Hex(), quit)
+	hub := NewHub()
+	e.Register("/subscribe", func(c *Context) error {
+		return hub.Subscribe(c, map[string]bool{"ok": true}, "book:bitcoin/skycoin")
+	})
+	addr, stop := startTestEngine(t, e)
+	defer stop()
+
+	res := map[string]bool{}
+	sub, err := Subscribe(addr, "/subscribe", struct{}{}, &res)
+	assert.Nil(t, err)
+	assert.True(t, res["ok"])
+
+	// only the events of the subscribed topic are pushed.
+	hub.Publish("trades:bitcoin/skycoin", map[string]int{"price": 1})
+	hub.Publish("book:bitcoin/skycoin", map[string]int{"price": 2})
+	ev, err := sub.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "book:bitcoin/skycoin", ev.Topic)
+	assert.Equal(t, `{"price":2}`, string(ev.Data))
+
+	// the subscriber is removed once the connection is closed.
+	sub.Close()
+	for i := 0; ; i++ {
+		hub.mtx.Lock()
+		n := len(hub.subs)
+		hub.mtx.Unlock()
+		if n == 0 {
+			break
+		}
+		if i == 100 {
+			t.Fatal("subscriber is not removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,194 @@
+package sknet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+// purgeInterval is the interval of removing the buckets that are full again.
+var purgeInterval = time.Minute
+
+// Quota is the token bucket of a route, each account pubkey and each ip has its own bucket, which
+// is refilled by the rate per second up to the burst. Zero rate means no limit.
+type Quota struct {
+	Rate    float64 `json:"rate"`     // requests per second of one account pubkey.
+	Burst   int     `json:"burst"`    // max requests of one account pubkey at once.
+	IPRate  float64 `json:"ip_rate"`  // requests per second of one ip.
+	IPBurst int     `json:"ip_burst"` // max requests of one ip at once.
+}
+
+// Validate checks the rates are not negative, and the limited buckets can hold a request.
+func (q Quota) Validate() error {
+	if q.Rate < 0 || q.IPRate < 0 {
+		return errors.New("negative rate")
+	}
+
+	if q.Rate > 0 && q.Burst < 1 {
+		return errors.New("burst must be at least 1")
+	}
+
+	if q.IPRate > 0 && q.IPBurst < 1 {
+		return errors.New("ip_burst must be at least 1")
+	}
+	return nil
+}
+
+// RateLimitError is returned by the client if the request is rejected by the rate limit.
+type RateLimitError struct {
+	Reason     string
+	RetryAfter time.Duration // time to wait before retrying.
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Reason
+}
+
+// RateLimit is the middleware that limits the requests of each account pubkey and ip by the quota
+// of the path, the paths without quota are not limited. It must be used after Authorize, so the
+// request is decrypted. The limited requests are rejected with the RateLimited error, whose
+// retry_after is the milliseconds to wait for the next token.
+func RateLimit(quotas map[string]Quota) HandlerFunc {
+	l := newLimiter(quotas)
+	return func(c *Context) error {
+		ip := remoteIP(c)
+		pubkey := accountPubkey(c)
+		wait := l.take(c.Request.GetPath(), pubkey, ip, time.Now())
+		if wait == 0 {
+			return c.Next()
+		}
+
+		logger.Warning("%s request from %s of %s is rate limited", c.Request.GetPath(), pubkey, ip)
+		ms := (wait + time.Millisecond - 1) / time.Millisecond
+		res := pp.MakeResult(pp.ErrCode_RateLimited, fmt.Sprintf("rate limited, retry after %v", ms*time.Millisecond))
+		res.RetryAfter = pp.PtrUint64(uint64(ms))
+		return c.Error(&pp.EmptyRes{Result: res})
+	}
+}
+
+// accountPubkey returns the account pubkey in the decrypted request, the pubkey of the connection
+// is only the client's transport key, which is shared by the accounts of the client. The requests
+// without account pubkey are limited by the transport key.
+func accountPubkey(c *Context) string {
+	var req struct {
+		Pubkey string `json:"pubkey"`
+	}
+	if err := json.Unmarshal(c.Raw, &req); err == nil && req.Pubkey != "" {
+		return req.Pubkey
+	}
+	return c.Pubkey
+}
+
+// remoteIP returns the ip of the connection that the request comes from.
+func remoteIP(c *Context) string {
+	if w, ok := c.Resp.(*Response); ok && w.sc != nil {
+		return w.sc.ip
+	}
+	return ""
+}
+
+// bucket is the token bucket of one account pubkey or ip on one path.
+type bucket struct {
+	tokens float64
+	rate   float64
+	burst  float64
+	last   time.Time // time of the last refill.
+}
+
+// refill adds the tokens of the time passed since the last refill.
+func (b *bucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+}
+
+// limiter keeps the buckets of the quotas.
+type limiter struct {
+	quotas  map[string]Quota
+	buckets map[string]*bucket // path pubkey:key or path ip:ip => bucket.
+	purged  time.Time
+	mtx     sync.Mutex
+}
+
+func newLimiter(quotas map[string]Quota) *limiter {
+	qs := make(map[string]Quota, len(quotas))
+	for p, q := range quotas {
+		qs[p] = q
+	}
+	return &limiter{
+		quotas:  qs,
+		buckets: make(map[string]*bucket),
+		purged:  time.Now(),
+	}
+}
+
+// take takes a token from the buckets of the pubkey and the ip on the path, the time to wait
+// for the next token is returned if any of them is empty, and no token is taken then.
+func (l *limiter) take(path, pubkey, ip string, now time.Time) time.Duration {
+	q, ok := l.quotas[path]
+	if !ok {
+		return 0
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if now.Sub(l.purged) > purgeInterval {
+		l.purge(now)
+	}
+
+	var (
+		bs   []*bucket
+		wait time.Duration
+	)
+	check := func(key string, rate float64, burst int) {
+		if rate <= 0 {
+			return
+		}
+
+		b, ok := l.buckets[key]
+		if !ok {
+			b = &bucket{tokens: float64(burst), rate: rate, burst: float64(burst), last: now}
+			l.buckets[key] = b
+		}
+		b.refill(now)
+		if b.tokens < 1 {
+			if w := time.Duration((1 - b.tokens) / rate * float64(time.Second)); w > wait {
+				wait = w
+			}
+		}
+		bs = append(bs, b)
+	}
+
+	if pubkey != "" {
+		check(path+" pubkey:"+pubkey, q.Rate, q.Burst)
+	}
+	if ip != "" {
+		check(path+" ip:"+ip, q.IPRate, q.IPBurst)
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	for _, b := range bs {
+		b.tokens--
+	}
+	return 0
+}
+
+// purge removes the buckets that are full, they're the same as the new ones. The caller must hold the mtx.
+func (l *limiter) purge(now time.Time) {
+	for k, b := range l.buckets {
+		if b.refill(now); b.tokens >= b.burst {
+			delete(l.buckets, k)
+		}
+	}
+	l.purged = now
+}
@@ -0,0 +1,101 @@
+package sknet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaValidate(t *testing.T) {
+	assert.Nil(t, Quota{}.Validate())
+	assert.Nil(t, Quota{Rate: 0.5, Burst: 1, IPRate: 2, IPBurst: 10}.Validate())
+	assert.NotNil(t, Quota{Rate: -1}.Validate())
+	assert.NotNil(t, Quota{Rate: 1}.Validate())
+	assert.NotNil(t, Quota{IPRate: 1}.Validate())
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+
+	// the paths without quota are not limited.
+	l := newLimiter(map[string]Quota{"/order": {Rate: 1, Burst: 2}})
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, time.Duration(0), l.take("/ticker", "a", "1.1.1.1", now))
+	}
+
+	// the burst is allowed at once, then it waits for the next token.
+	assert.Equal(t, time.Duration(0), l.take("/order", "a", "1.1.1.1", now))
+	assert.Equal(t, time.Duration(0), l.take("/order", "a", "1.1.1.1", now))
+	assert.Equal(t, time.Second, l.take("/order", "a", "1.1.1.1", now))
+	assert.Equal(t, 500*time.Millisecond, l.take("/order", "a", "1.1.1.1", now.Add(500*time.Millisecond)))
+	assert.Equal(t, time.Duration(0), l.take("/order", "a", "1.1.1.1", now.Add(time.Second)))
+	assert.Equal(t, time.Duration(0), l.take("/order", "b", "1.1.1.1", now))
+
+	// the ip is shared by the pubkeys.
+	l = newLimiter(map[string]Quota{"/order": {IPRate: 1, IPBurst: 2}})
+	assert.Equal(t, time.Duration(0), l.take("/order", "a", "1.1.1.1", now))
+	assert.Equal(t, time.Duration(0), l.take("/order", "b", "1.1.1.1", now))
+	assert.Equal(t, time.Second, l.take("/order", "c", "1.1.1.1", now))
+	assert.Equal(t, time.Duration(0), l.take("/order", "c", "2.2.2.2", now))
+
+	// no token is taken from the ip if the pubkey is limited.
+	l = newLimiter(map[string]Quota{"/order": {Rate: 1, Burst: 1, IPRate: 1, IPBurst: 2}})
+	assert.Equal(t, time.Duration(0), l.take("/order", "a", "1.1.1.1", now))
+	assert.Equal(t, time.Second, l.take("/order", "a", "1.1.1.1", now))
+	assert.Equal(t, time.Duration(0), l.take("/order", "b", "1.1.1.1", now))
+	assert.Equal(t, time.Second, l.take("/order", "c", "1.1.1.1", now))
+
+	// the full buckets are purged.
+	assert.Equal(t, 4, len(l.buckets))
+	l.take("/order", "a", "1.1.1.1", now.Add(purgeInterval+time.Second))
+	assert.Equal(t, 2, len(l.buckets))
+}
+
+func TestRateLimit(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	defer SetPubkey(gPubkey)
+	SetPubkey(pk.Hex())
+
+	quit := make(chan bool)
+	defer close(quit)
+	e := New(sk.Hex(), quit)
+	e.Use(RateLimit(map[string]Quota{"/echo": {Rate: 0.1, Burst: 2}}))
+	e.Register("/echo", func(c *Context) error {
+		var req struct {
+			A string `json:"a"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			return err
+		}
+		return c.SendJSON(&req)
+	})
+	addr, stop := startTestEngine(t, e)
+	defer stop()
+	defer forgetVersion(addr)
+
+	for i := 0; i < 2; i++ {
+		res := map[string]string{}
+		assert.Nil(t, EncryGet(addr, "/echo", map[string]string{"a": "b"}, &res))
+		assert.Equal(t, "b", res["a"])
+	}
+
+	res := map[string]string{}
+	err := EncryGet(addr, "/echo", map[string]string{"a": "b"}, &res)
+	rerr, ok := err.(*RateLimitError)
+	if assert.True(t, ok, "%v", err) {
+		assert.True(t, rerr.RetryAfter > 0 && rerr.RetryAfter <= 10*time.Second, "%v", rerr.RetryAfter)
+		assert.Contains(t, rerr.Reason, "retry after")
+	}
+
+	// the accounts behind the same transport key have their own buckets.
+	for _, pubkey := range []string{"account1", "account2"} {
+		for i := 0; i < 2; i++ {
+			assert.Nil(t, EncryGet(addr, "/echo", map[string]string{"a": "b", "pubkey": pubkey}, &res))
+		}
+		err = EncryGet(addr, "/echo", map[string]string{"a": "b", "pubkey": pubkey}, &res)
+		_, ok = err.(*RateLimitError)
+		assert.True(t, ok, "%v", err)
+	}
+}
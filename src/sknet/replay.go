@@ -0,0 +1,158 @@
+package sknet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// ReplayWindow is the max clock skew between the client and server, the requests whose
+// time is out of the window are rejected as stale, and the nonces are remembered within it.
+var ReplayWindow = 30 * time.Second
+
+var (
+	errStaleRequest    = errors.New("stale request")
+	errReplayedRequest = errors.New("replayed request")
+)
+
+// stamp is put into the encrypted payload by the client, so the captured requests can't be replayed.
+type stamp struct {
+	Time  int64  `json:"req_time"`  // unix time in nanoseconds.
+	Nonce string `json:"req_nonce"` // random hex string, unique in the window.
+}
+
+// NewStamp creates the replay stamp of current time, the stamp that's already in the request
+// is sent instead of a new one, so the client can sign over it before sending the request.
+func NewStamp() (int64, string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return 0, "", err
+	}
+	return time.Now().UnixNano(), hex.EncodeToString(b), nil
+}
+
+// proofHash returns the hash that the account key signs to prove the ownership in the request
+// of the path, it's bound to the replay stamp, which can only be used once.
+func proofHash(path string, tm int64, nonce string) cipher.SHA256 {
+	return cipher.SumSHA256([]byte(fmt.Sprintf("%s:%d:%s", path, tm, nonce)))
+}
+
+// SignProof signs the replay stamp of the request with the account's seckey.
+func SignProof(path string, tm int64, nonce string, seckey string) (string, error) {
+	s, err := cipher.SecKeyFromHex(seckey)
+	if err != nil {
+		return "", err
+	}
+	return cipher.SignHash(proofHash(path, tm, nonce), s).Hex(), nil
+}
+
+// VerifyProof checks the sig is signed by the pubkey over the replay stamp of the request,
+// the stamp must be the one checked by Authorize, so the proof can't be replayed.
+func VerifyProof(path string, tm int64, nonce string, pubkey string, sig string) error {
+	if nonce == "" {
+		return errors.New("empty request nonce")
+	}
+
+	if err := validatePubkey(pubkey); err != nil {
+		return err
+	}
+	p, err := cipher.PubKeyFromHex(pubkey)
+	if err != nil {
+		return err
+	}
+
+	s, err := cipher.SigFromHex(sig)
+	if err != nil {
+		return err
+	}
+	return cipher.VerifySignature(p, s, proofHash(path, tm, nonce))
+}
+
+// stampReq adds the current time and a random nonce into the json object of the request,
+// the stamp created by NewStamp in the request is kept.
+func stampReq(r interface{}) (map[string]json.RawMessage, error) {
+	d, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]json.RawMessage)
+	if r != nil {
+		if err := json.Unmarshal(d, &m); err != nil {
+			return nil, err
+		}
+	}
+
+	_, hasTime := m["req_time"]
+	_, hasNonce := m["req_nonce"]
+	if hasTime && hasNonce {
+		return m, nil
+	}
+
+	tm, nonce, err := NewStamp()
+	if err != nil {
+		return nil, err
+	}
+	m["req_time"], _ = json.Marshal(tm)
+	m["req_nonce"], _ = json.Marshal(nonce)
+	return m, nil
+}
+
+// replayCache remembers the nonces of each pubkey in the sliding window.
+type replayCache struct {
+	window time.Duration
+	seen   map[string]time.Time // pubkey:nonce => request time.
+	purged time.Time
+	mtx    sync.Mutex
+}
+
+func newReplayCache(window time.Duration) *replayCache {
+	return &replayCache{
+		window: window,
+		seen:   make(map[string]time.Time),
+		purged: time.Now(),
+	}
+}
+
+// check verifies the stamp of the decrypted payload, and remembers the nonce. The nonces out
+// of the window are forgotten, their requests are rejected as stale anyway.
+func (rc *replayCache) check(pubkey string, data []byte) error {
+	var s stamp
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if s.Nonce == "" {
+		return errors.New("empty request nonce")
+	}
+
+	now := time.Now()
+	tm := time.Unix(0, s.Time)
+	if tm.Before(now.Add(-rc.window)) || tm.After(now.Add(rc.window)) {
+		return errStaleRequest
+	}
+
+	rc.mtx.Lock()
+	defer rc.mtx.Unlock()
+	if now.Sub(rc.purged) > rc.window {
+		for k, t := range rc.seen {
+			if t.Before(now.Add(-rc.window)) {
+				delete(rc.seen, k)
+			}
+		}
+		rc.purged = now
+	}
+
+	k := pubkey + ":" + s.Nonce
+	if _, ok := rc.seen[k]; ok {
+		return errReplayedRequest
+	}
+	rc.seen[k] = tm
+	return nil
+}
@@ -0,0 +1,118 @@
+package sknet
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayProtection(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	defer SetPubkey(gPubkey)
+	SetPubkey(pk.Hex())
+
+	quit := make(chan bool)
+	defer close(quit)
+	e := New(sk.Hex(), quit)
+	e.Register("/echo", func(c *Context) error {
+		var req struct {
+			A string `json:"a"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			return err
+		}
+		return c.SendJSON(&req)
+	})
+	addr, stop := startTestEngine(t, e)
+	defer stop()
+
+	// the same request is stamped with different values each time.
+	for i := 0; i < 2; i++ {
+		res := map[string]string{}
+		assert.Nil(t, EncryGet(addr, "/echo", map[string]string{"a": "b"}, &res))
+		assert.Equal(t, "b", res["a"])
+	}
+
+	// the captured request is rejected.
+	encReq, err := encrypt(Version1, "/echo", map[string]string{"a": "b"}, gPubkey, gSeckey)
+	assert.Nil(t, err)
+	_, err = Get(addr, "/echo", encReq)
+	assert.Nil(t, err)
+	assertErrCode(t, addr, encReq, pp.ErrCode_ReplayedRequest)
+
+	// stale request.
+	sr, err := stampReq(map[string]string{"a": "b"})
+	assert.Nil(t, err)
+	sr["req_time"], _ = json.Marshal(time.Now().Add(-2 * ReplayWindow).UnixNano())
+	encData, nonce, err := pp.Encrypt(sr, gPubkey, gSeckey)
+	assert.Nil(t, err)
+	encReq.Encryptdata, encReq.Nonce = encData, nonce
+	assertErrCode(t, addr, encReq, pp.ErrCode_ReplayedRequest)
+
+	// request without stamp.
+	encData, nonce, err = pp.Encrypt(map[string]string{"a": "b"}, gPubkey, gSeckey)
+	assert.Nil(t, err)
+	encReq.Encryptdata, encReq.Nonce = encData, nonce
+	assertErrCode(t, addr, encReq, pp.ErrCode_ReplayedRequest)
+}
+
+func assertErrCode(t *testing.T, addr string, req *pp.EncryptReq, code pp.ErrCode) {
+	resp, err := Get(addr, "/echo", req)
+	assert.Nil(t, err)
+	res := pp.EmptyRes{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, int32(code), res.Result.GetErrcode())
+}
+
+func TestReplayCacheWindow(t *testing.T) {
+	rc := newReplayCache(20 * time.Millisecond)
+	data, err := json.Marshal(stamp{Time: time.Now().UnixNano(), Nonce: "1"})
+	assert.Nil(t, err)
+	assert.Nil(t, rc.check("pk", data))
+	assert.Equal(t, errReplayedRequest, rc.check("pk", data))
+
+	// the nonces of different pubkeys are independent.
+	assert.Nil(t, rc.check("pk2", data))
+
+	// the nonces out of the window are forgotten, the requests are stale.
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, errStaleRequest, rc.check("pk", data))
+	data, err = json.Marshal(stamp{Time: time.Now().UnixNano(), Nonce: "2"})
+	assert.Nil(t, err)
+	assert.Nil(t, rc.check("pk", data))
+	assert.Equal(t, 1, len(rc.seen))
+}
+
+func TestProof(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	_, sk2 := cipher.GenerateKeyPair()
+	tm, nonce, err := NewStamp()
+	assert.Nil(t, err)
+
+	// the stamp in the request is kept.
+	sr, err := stampReq(map[string]interface{}{"req_time": tm, "req_nonce": nonce})
+	assert.Nil(t, err)
+	var s stamp
+	d, err := json.Marshal(sr)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(d, &s))
+	assert.Equal(t, stamp{Time: tm, Nonce: nonce}, s)
+
+	sig, err := SignProof("/subscribe", tm, nonce, sk.Hex())
+	assert.Nil(t, err)
+	assert.Nil(t, VerifyProof("/subscribe", tm, nonce, pk.Hex(), sig))
+
+	// the proof is bound to the key, the path and the stamp.
+	sig2, err := SignProof("/subscribe", tm, nonce, sk2.Hex())
+	assert.Nil(t, err)
+	assert.NotNil(t, VerifyProof("/subscribe", tm, nonce, pk.Hex(), sig2))
+	assert.NotNil(t, VerifyProof("/orders", tm, nonce, pk.Hex(), sig))
+	assert.NotNil(t, VerifyProof("/subscribe", tm+1, nonce, pk.Hex(), sig))
+	assert.NotNil(t, VerifyProof("/subscribe", tm, "", pk.Hex(), sig))
+	assert.NotNil(t, VerifyProof("/subscribe", tm, nonce, "bad", sig))
+	assert.NotNil(t, VerifyProof("/subscribe", tm, nonce, pk.Hex(), ""))
+}
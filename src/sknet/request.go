@@ -7,7 +7,9 @@ import (
 )
 
 type Request struct {
-	pp.Request // constructed request.
+	pp.Request        // constructed request.
+	Version    uint32 `json:"-"` // protocol version of the frame.
+	ID         uint32 `json:"-"` // request id, carried by the frames since Version3.
 }
 
 func (r *Request) Reset() {
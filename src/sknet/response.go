@@ -3,20 +3,61 @@ package sknet
 import (
 	"io"
 	"net"
+	"sync"
+	"time"
 )
 
 // Response concrete response writer.
 type Response struct {
-	c    net.Conn
-	Body io.Reader
+	c       net.Conn
+	Body    io.Reader
+	Version uint32        // protocol version of the frame that's read, or to be written.
+	ID      uint32        // id of the request, carried by the frames since Version3.
+	mtx     *sync.Mutex   // the responses and pushed events of one connection are written one by one.
+	done    chan struct{} // closed when the connection is closed.
+	sc      *serverConn   // the accepted connection, nil on the client side.
+	timeout time.Duration // the write timeout, zero means no limit.
+}
+
+// newResponse creates the response writer of the connection.
+func newResponse(sc *serverConn, timeout time.Duration) *Response {
+	return &Response{
+		c:       sc.c,
+		Version: Version1,
+		mtx:     &sync.Mutex{},
+		done:    make(chan struct{}),
+		sc:      sc,
+		timeout: timeout,
+	}
+}
+
+// reply returns the response writer of the request, which writes on the same connection
+// in the request's version and id.
+func (res *Response) reply(r *Request) *Response {
+	rsp := *res
+	rsp.Version, rsp.ID = r.Version, r.ID
+	return &rsp
+}
+
+// setDeadline sets the write deadline of the timeout, the caller must hold the mtx.
+func (res *Response) setDeadline() {
+	if res.timeout > 0 {
+		res.c.SetWriteDeadline(time.Now().Add(res.timeout))
+	}
 }
 
 // Write write data directly.
 func (res *Response) Write(p []byte) (n int, err error) {
+	res.mtx.Lock()
+	defer res.mtx.Unlock()
+	res.setDeadline()
 	return res.c.Write(p)
 }
 
-// SendJSON marshal the data into json, and then send.
+// SendJSON marshal the data into json, and then send in the frame of the response's version and id.
 func (res *Response) SendJSON(data interface{}) error {
-	return Write(res.c, data)
+	res.mtx.Lock()
+	defer res.mtx.Unlock()
+	res.setDeadline()
+	return writeFrame(res.c, res.Version, res.ID, data)
 }
@@ -0,0 +1,220 @@
+package sknet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+var (
+	// ErrEngineClosed is returned by Serve and Run after Shutdown is called.
+	ErrEngineClosed = errors.New("engine closed")
+
+	errServerBusy   = errors.New("server busy")
+	errTooManyConns = errors.New("too many connections from the ip")
+)
+
+// serverConn is an accepted connection, its state is shared by the responses on it.
+type serverConn struct {
+	c         net.Conn
+	ip        string
+	idle      bool // waiting for the next request.
+	streaming bool // events are pushed on it, so it's not closed when idle.
+	closing   bool // the engine is shutting down.
+	mtx       sync.Mutex
+}
+
+// Run listens on the address and serves the connections, it returns ErrEngineClosed after Shutdown.
+func (engine *Engine) Run(ip string, port int) error {
+	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", ip, port))
+	if err != nil {
+		return err
+	}
+	return engine.Serve(l)
+}
+
+// Serve accepts the connections on the listener, and dispatches them to the workers. The
+// connections are rejected with the ServerBusy error if all workers are busy, or their ip
+// has MaxConnsPerIP connections. The temporary accept errors are retried with backoff.
+func (engine *Engine) Serve(l net.Listener) error {
+	engine.mtx.Lock()
+	if engine.isClosing() {
+		engine.mtx.Unlock()
+		l.Close()
+		return ErrEngineClosed
+	}
+	engine.listeners[l] = true
+	engine.mtx.Unlock()
+
+	defer func() {
+		engine.mtx.Lock()
+		delete(engine.listeners, l)
+		engine.mtx.Unlock()
+		l.Close()
+	}()
+
+	var delay time.Duration
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			if engine.isClosing() {
+				return ErrEngineClosed
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				if delay == 0 {
+					delay = 5 * time.Millisecond
+				} else if delay *= 2; delay > time.Second {
+					delay = time.Second
+				}
+				logger.Error("accept error: %v, retry in %v", err, delay)
+				time.Sleep(delay)
+				continue
+			}
+			return err
+		}
+		delay = 0
+		logger.Debug("new connection:%s", c.RemoteAddr())
+
+		sc, err := engine.track(c)
+		if err != nil {
+			logger.Warning("reject %s: %v", c.RemoteAddr(), err)
+			go reject(c, err)
+			continue
+		}
+
+		// there're at most Workers tracked connections, so it won't block.
+		engine.connPool <- sc
+	}
+}
+
+// Shutdown stops accepting connections, closes the idle ones, and waits for the requests in
+// flight to be replied. The connections that are still open when the ctx is done are closed.
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	engine.mtx.Lock()
+	atomic.StoreInt32(&engine.closing, 1)
+	for l := range engine.listeners {
+		l.Close()
+	}
+	for sc := range engine.conns {
+		sc.mtx.Lock()
+		sc.closing = true
+		if sc.idle {
+			sc.c.SetReadDeadline(time.Now())
+		}
+		sc.mtx.Unlock()
+	}
+	engine.mtx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		engine.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		engine.mtx.Lock()
+		for sc := range engine.conns {
+			sc.c.Close()
+		}
+		engine.mtx.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (engine *Engine) isClosing() bool {
+	return atomic.LoadInt32(&engine.closing) == 1
+}
+
+// track records the accepted connection, error is returned if all workers are busy,
+// or its ip has too many connections.
+func (engine *Engine) track(c net.Conn) (*serverConn, error) {
+	ip, _, err := net.SplitHostPort(c.RemoteAddr().String())
+	if err != nil {
+		return nil, err
+	}
+
+	engine.mtx.Lock()
+	defer engine.mtx.Unlock()
+	if engine.isClosing() {
+		return nil, ErrEngineClosed
+	}
+	if len(engine.conns) >= engine.opts.Workers {
+		return nil, errServerBusy
+	}
+	if max := engine.opts.MaxConnsPerIP; max > 0 && engine.ips[ip] >= max {
+		return nil, errTooManyConns
+	}
+
+	sc := &serverConn{c: c, ip: ip}
+	engine.conns[sc] = true
+	engine.ips[ip]++
+	engine.wg.Add(1)
+	return sc, nil
+}
+
+// untrack removes the closed connection.
+func (engine *Engine) untrack(sc *serverConn) {
+	engine.mtx.Lock()
+	defer engine.mtx.Unlock()
+	delete(engine.conns, sc)
+	if engine.ips[sc.ip]--; engine.ips[sc.ip] <= 0 {
+		delete(engine.ips, sc.ip)
+	}
+	engine.wg.Done()
+}
+
+// setIdle marks the connection as waiting for the next request, and sets the read deadline
+// of IdleTimeout, false is returned if the engine is shutting down.
+func (engine *Engine) setIdle(sc *serverConn) bool {
+	sc.mtx.Lock()
+	defer sc.mtx.Unlock()
+	if engine.isClosing() {
+		return false
+	}
+
+	sc.idle = true
+	var deadline time.Time
+	if engine.opts.IdleTimeout > 0 && !sc.streaming {
+		deadline = time.Now().Add(engine.opts.IdleTimeout)
+	}
+	sc.c.SetReadDeadline(deadline)
+	return true
+}
+
+// setBusy marks the connection as reading a request, and sets the read deadline of ReadTimeout.
+func (engine *Engine) setBusy(sc *serverConn) {
+	sc.mtx.Lock()
+	defer sc.mtx.Unlock()
+	sc.idle = false
+	var deadline time.Time
+	if engine.opts.ReadTimeout > 0 {
+		deadline = time.Now().Add(engine.opts.ReadTimeout)
+	}
+	sc.c.SetReadDeadline(deadline)
+}
+
+// stream marks the connection as pushing events, so it's not closed when idle.
+func (sc *serverConn) stream() {
+	sc.mtx.Lock()
+	defer sc.mtx.Unlock()
+	sc.streaming = true
+	if sc.idle && !sc.closing {
+		sc.c.SetReadDeadline(time.Time{})
+	}
+}
+
+// reject replies the error to the connection that's not served, and closes it.
+func reject(c net.Conn, err error) {
+	defer c.Close()
+	c.SetWriteDeadline(time.Now().Add(time.Second))
+	Write(c, pp.EmptyRes{Result: pp.MakeResult(pp.ErrCode_ServerBusy, err.Error())})
+}
@@ -0,0 +1,216 @@
+package sknet
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRawEngine creates the engine without the Authorize middleware, so the timeouts are tested
+// without the cost of the encryption. Its /wait handler replies the path after sleeping the
+// milliseconds in the request.
+func newRawEngine(opts Options) (*Engine, func()) {
+	quit := make(chan bool)
+	e := NewWithOptions("", quit, opts)
+	e.handlers = nil
+	e.Register("/wait", func(c *Context) error {
+		var ms int
+		if err := c.UnmarshalReq(&ms); err != nil {
+			return err
+		}
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+		return c.Error(c.Request.GetPath())
+	})
+	return e, func() { close(quit) }
+}
+
+// dialRaw connects to the engine with the frames of Version3.
+func dialRaw(t *testing.T, addr string) *clientConn {
+	c, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	return newClientConn(c, Version3)
+}
+
+// readClosed checks the server closes the connection in the timeout.
+func readClosed(t *testing.T, c net.Conn, timeout time.Duration) {
+	c.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, 1024)
+	for {
+		if _, err := c.Read(buf); err != nil {
+			ne, ok := err.(net.Error)
+			assert.False(t, ok && ne.Timeout(), "connection is not closed in %v", timeout)
+			return
+		}
+	}
+}
+
+func TestServeTimeouts(t *testing.T) {
+	opts := DefaultOptions
+	opts.IdleTimeout = 100 * time.Millisecond
+	opts.ReadTimeout = 100 * time.Millisecond
+	e, quit := newRawEngine(opts)
+	defer quit()
+	addr, stop := startTestEngine(t, e)
+	defer stop()
+
+	// the idle connection is closed.
+	c, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	defer c.Close()
+	readClosed(t, c, time.Second)
+
+	// the connection that stops in the middle of a frame is closed.
+	c, err = net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	defer c.Close()
+	_, err = c.Write([]byte{0, 0, 0, 1})
+	assert.Nil(t, err)
+	readClosed(t, c, time.Second)
+
+	// the connection is not idle while its request is in flight.
+	cc := dialRaw(t, addr)
+	defer cc.close(ErrClientClosed)
+	rsp, err := cc.roundTrip("/wait", 300, time.Second)
+	assert.Nil(t, err)
+	var path string
+	assert.Nil(t, json.NewDecoder(rsp.Body).Decode(&path))
+	assert.Equal(t, "/wait", path)
+}
+
+func TestServeLimits(t *testing.T) {
+	for _, opts := range []Options{
+		{Workers: 1},
+		{Workers: 10, MaxConnsPerIP: 1},
+	} {
+		e, quit := newRawEngine(opts)
+		addr, stop := startTestEngine(t, e)
+
+		c, err := net.Dial("tcp", addr)
+		assert.Nil(t, err)
+		_, err = requestVersion(c, []uint32{Version})
+		assert.Nil(t, err)
+
+		// the second connection is rejected.
+		c2, err := net.Dial("tcp", addr)
+		assert.Nil(t, err)
+		rsp := Response{}
+		assert.Nil(t, Read(c2, &rsp))
+		res := pp.EmptyRes{}
+		assert.Nil(t, json.NewDecoder(rsp.Body).Decode(&res))
+		assert.Equal(t, int32(pp.ErrCode_ServerBusy), res.Result.GetErrcode())
+		readClosed(t, c2, time.Second)
+		c2.Close()
+
+		// accepted again once the first is closed.
+		c.Close()
+		for i := 0; ; i++ {
+			e.mtx.Lock()
+			n := len(e.conns)
+			e.mtx.Unlock()
+			if n == 0 {
+				break
+			}
+			if i == 100 {
+				t.Fatal("connection is not untracked")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		c, err = net.Dial("tcp", addr)
+		assert.Nil(t, err)
+		_, err = requestVersion(c, []uint32{Version})
+		assert.Nil(t, err)
+		c.Close()
+
+		stop()
+		quit()
+	}
+}
+
+func TestServeSubscription(t *testing.T) {
+	defer SetPubkey(gPubkey)
+	opts := DefaultOptions
+	opts.IdleTimeout = time.Second
+	e, quit := newSleepEngine(opts)
+	defer quit()
+	hub := NewHub()
+	e.Register("/subscribe", func(c *Context) error {
+		return hub.Subscribe(c, map[string]bool{"ok": true}, "t")
+	})
+	addr, stop := startTestEngine(t, e)
+	defer stop()
+
+	res := map[string]bool{}
+	sub, err := Subscribe(addr, "/subscribe", struct{}{}, &res)
+	assert.Nil(t, err)
+	defer sub.Close()
+
+	// the subscription is kept open though it's idle.
+	time.Sleep(1500 * time.Millisecond)
+	hub.Publish("t", 1)
+	ev, err := sub.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "1", string(ev.Data))
+}
+
+func TestShutdown(t *testing.T) {
+	e, quit := newRawEngine(DefaultOptions)
+	defer quit()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := l.Addr().String()
+	served := make(chan error, 1)
+	go func() { served <- e.Serve(l) }()
+
+	// the request in flight is replied before the connection is closed.
+	cc := dialRaw(t, addr)
+	defer cc.close(ErrClientClosed)
+	done := make(chan error, 1)
+	go func() {
+		_, err := cc.roundTrip("/wait", 300, 5*time.Second)
+		done <- err
+	}()
+
+	idle, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	defer idle.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, e.Shutdown(ctx))
+	assert.Nil(t, <-done)
+	assert.Equal(t, ErrEngineClosed, <-served)
+	readClosed(t, idle, time.Second)
+	readClosed(t, cc.c, time.Second)
+
+	_, err = net.Dial("tcp", addr)
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrEngineClosed, e.Serve(l))
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	e, quit := newRawEngine(DefaultOptions)
+	defer quit()
+	addr, stop := startTestEngine(t, e)
+	defer stop()
+
+	cc := dialRaw(t, addr)
+	defer cc.close(ErrClientClosed)
+	done := make(chan error, 1)
+	go func() {
+		_, err := cc.roundTrip("/wait", 1000, 5*time.Second)
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// the connections are closed once the ctx is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, e.Shutdown(ctx))
+	assert.NotNil(t, <-done)
+}
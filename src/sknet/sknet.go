@@ -9,17 +9,58 @@ import (
 	"io"
 	"net"
 	"strings"
+	"sync"
+	"time"
 
 	logging "github.com/op/go-logging"
 )
 
+// The protocol versions, the version is in the head of each frame.
+const (
+	// Version1 is the legacy format, the payload is encrypted by chacha20 without authentication.
+	Version1 uint32 = 1
+	// Version2 encrypts the payload by chacha20-poly1305, with the request path as the associated data.
+	Version2 uint32 = 2
+	// Version3 adds the request id into the head, the requests of one connection are handled
+	// concurrently, and the responses are matched by the id.
+	Version3 uint32 = 3
+)
+
 var (
 	logger               = logging.MustGetLogger("exchange.net")
-	queueSize            = 1000
-	version       uint32 = 1
 	maxReqPkgSize uint32 = 32 * 1024 // set max request package size: 32kb
+	maxInFlight          = 64        // max number of requests handled concurrently on one connection.
+
+	// Version is the latest protocol version.
+	Version = Version3
+	// MinVersion is the oldest version that's accepted, raise it once all the clients are upgraded.
+	MinVersion = Version1
 )
 
+// Options configures how the engine serves the connections, the zero timeouts and limits mean
+// no limit, except that zero Workers means DefaultOptions.Workers.
+type Options struct {
+	Workers       int           // number of connections served at the same time, the others are rejected.
+	IdleTimeout   time.Duration // max time waiting for the next request, the subscriptions are not limited.
+	ReadTimeout   time.Duration // max time reading a request once its first byte arrives.
+	WriteTimeout  time.Duration // max time writing a response or pushed event.
+	MaxConnsPerIP int           // max number of connections from one ip.
+}
+
+// DefaultOptions is used by New.
+var DefaultOptions = Options{
+	Workers:       1000,
+	IdleTimeout:   5 * time.Minute,
+	ReadTimeout:   30 * time.Second,
+	WriteTimeout:  30 * time.Second,
+	MaxConnsPerIP: 100,
+}
+
+// supported checks whether the version is accepted.
+func supported(ver uint32) bool {
+	return ver >= MinVersion && ver <= Version
+}
+
 // HandlerFunc important element for implementing the middleware function.
 type HandlerFunc func(c *Context) error
 
@@ -28,20 +69,40 @@ type Engine struct {
 	handlers      []HandlerFunc
 	handlerFunc   map[string]HandlerFunc
 	groupHandlers map[string]*Group
-	connPool      chan net.Conn
+	opts          Options
+	connPool      chan *serverConn
+	conns         map[*serverConn]bool  // the accepted connections that are not closed.
+	ips           map[string]int        // ip => number of connections.
+	listeners     map[net.Listener]bool // the listeners being served.
+	closing       int32                 // set to 1 once Shutdown is called.
+	wg            sync.WaitGroup        // waits for the accepted connections to be closed.
+	mtx           sync.Mutex
 }
 
-// New create an engine.
+// New create an engine with the DefaultOptions.
 func New(seckey string, quit chan bool) *Engine {
+	return NewWithOptions(seckey, quit, DefaultOptions)
+}
+
+// NewWithOptions creates an engine, which starts opts.Workers workers to serve the connections.
+func NewWithOptions(seckey string, quit chan bool, opts Options) *Engine {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultOptions.Workers
+	}
+
 	e := &Engine{
 		handlerFunc:   make(map[string]HandlerFunc),
 		groupHandlers: make(map[string]*Group),
-		connPool:      make(chan net.Conn, queueSize),
+		opts:          opts,
+		connPool:      make(chan *serverConn, opts.Workers),
+		conns:         make(map[*serverConn]bool),
+		ips:           make(map[string]int),
+		listeners:     make(map[net.Listener]bool),
 	}
 
 	e.Use(Authorize(seckey))
 
-	for i := 0; i < queueSize; i++ {
+	for i := 0; i < opts.Workers; i++ {
 		w := &Worker{
 			ID:   i,
 			Enge: e,
@@ -90,22 +151,6 @@ func (engine *Engine) Group(path string, handlers ...HandlerFunc) *Group {
 	return gp
 }
 
-// Run start the engine.
-func (engine *Engine) Run(ip string, port int) {
-	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", ip, port))
-	if err != nil {
-		panic(err)
-	}
-	for {
-		c, err := l.Accept()
-		if err != nil {
-			panic(err)
-		}
-		logger.Debug("new connection:%s", c.RemoteAddr())
-		engine.connPool <- c
-	}
-}
-
 // Logger middleware
 func Logger() HandlerFunc {
 	return func(c *Context) error {
@@ -114,37 +159,65 @@ func Logger() HandlerFunc {
 	}
 }
 
+// Write writes the frame of Version1, which can be read by all versions.
 func Write(w io.Writer, v interface{}) error {
+	return WriteVersion(w, Version1, v)
+}
+
+// WriteVersion marshals the value into json, and writes it in the frame of the version.
+func WriteVersion(w io.Writer, ver uint32, v interface{}) error {
+	return writeFrame(w, ver, 0, v)
+}
+
+// writeFrame writes the value in the frame of the version, the id is written since Version3.
+// The frame is written at once, so it won't be interleaved with others on the connection.
+func writeFrame(w io.Writer, ver uint32, id uint32, v interface{}) error {
 	d, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
 
-	var data = []interface{}{
-		version,        // protocol version
+	var data = []interface{}{ver} // protocol version
+	if ver >= Version3 {
+		data = append(data, id) // request id
+	}
+	data = append(data,
 		uint32(len(d)), // payload len
 		d,              // payload
-	}
+	)
 
+	var buf bytes.Buffer
 	for _, dt := range data {
-		if err := binary.Write(w, binary.BigEndian, dt); err != nil {
+		if err := binary.Write(&buf, binary.BigEndian, dt); err != nil {
 			return err
 		}
 	}
-	return nil
+	_, err = w.Write(buf.Bytes())
+	return err
 }
 
-// Read read data from reader and unmarshal to specific struct.
+// Read read data from reader and unmarshal to specific struct, the version and id of
+// the frame are set into the Request or Response.
 // |  4 bytes | 4 bytes | .........
 // |  version |   len   | payload |
+// the frame since Version3:
+// |  4 bytes | 4 bytes | 4 bytes | .........
+// |  version |    id   |   len   | payload |
 func Read(r io.Reader, v interface{}) error {
 	// read prefix head version
 	var ver uint32
 	if err := binary.Read(r, binary.BigEndian, &ver); err != nil {
 		return err
 	}
-	if ver != version {
-		return fmt.Errorf("invalid request")
+	if !supported(ver) {
+		return fmt.Errorf("unsupported version %d", ver)
+	}
+
+	var id uint32
+	if ver >= Version3 {
+		if err := binary.Read(r, binary.BigEndian, &id); err != nil {
+			return err
+		}
 	}
 
 	var len uint32
@@ -166,9 +239,11 @@ func Read(r io.Reader, v interface{}) error {
 			logger.Error(err.Error())
 			return err
 		}
+		r.Version, r.ID = ver, id
 		return nil
 	case *Response:
 		r.Body = bytes.NewBuffer(d)
+		r.Version, r.ID = ver, id
 		return nil
 	default:
 		return errors.New("unknow read type")
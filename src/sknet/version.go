@@ -0,0 +1,147 @@
+package sknet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+const (
+	// versionPath is the path of the version handshake, it's sent in the frame of Version1,
+	// so the old servers can read it, and reply the no handler error.
+	versionPath = "/version"
+	// pingPath is the path of the keep-alive ping, the servers of Version3 reply it.
+	pingPath = "/ping"
+)
+
+// negotiate chooses the highest version that both sides support, 0 is returned if there's none.
+func negotiate(versions []uint32) uint32 {
+	var ver uint32
+	for _, v := range versions {
+		if supported(v) && v > ver {
+			ver = v
+		}
+	}
+	return ver
+}
+
+// handshake replies the version request, the chosen version is returned.
+func handshake(c *Context) (uint32, error) {
+	var req pp.VersionReq
+	if err := c.UnmarshalReq(&req); err != nil {
+		return 0, c.Error(pp.MakeErrRes(err))
+	}
+
+	ver := negotiate(req.GetVersions())
+	if ver == 0 {
+		res := pp.MakeErrRes(fmt.Errorf("no supported version in %v, the server supports %d to %d", req.GetVersions(), MinVersion, Version))
+		return 0, c.Error(res)
+	}
+
+	return ver, c.Error(&pp.VersionRes{
+		Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+		Version: pp.PtrUint32(ver),
+	})
+}
+
+// versions caches the negotiated version of each server address.
+var versions = struct {
+	vers map[string]uint32
+	mtx  sync.Mutex
+}{vers: make(map[string]uint32)}
+
+// dial connects to the server, and returns the negotiated version. The version is negotiated
+// on the first connection to the address, the old servers that don't know the handshake
+// close the connection, so it's connected again with Version1.
+func dial(addr string) (net.Conn, uint32, error) {
+	c, err := net.DialTimeout("tcp", addr, DialTimeout)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	versions.mtx.Lock()
+	ver, ok := versions.vers[addr]
+	versions.mtx.Unlock()
+	if ok {
+		return c, ver, nil
+	}
+
+	vs := []uint32{}
+	for v := MinVersion; v <= Version; v++ {
+		vs = append(vs, v)
+	}
+
+	ver, err = requestVersion(c, vs)
+	if err != nil {
+		c.Close()
+		return nil, 0, err
+	}
+
+	if ver == 0 {
+		c.Close()
+		if !supported(Version1) {
+			return nil, 0, fmt.Errorf("%s does not support version %d to %d", addr, MinVersion, Version)
+		}
+
+		if c, err = net.DialTimeout("tcp", addr, DialTimeout); err != nil {
+			return nil, 0, err
+		}
+		ver = Version1
+	}
+
+	versions.mtx.Lock()
+	versions.vers[addr] = ver
+	versions.mtx.Unlock()
+	return c, ver, nil
+}
+
+// forgetVersion removes the cached version of the address, so it's negotiated again,
+// in case the server is upgraded or downgraded.
+func forgetVersion(addr string) {
+	versions.mtx.Lock()
+	defer versions.mtx.Unlock()
+	delete(versions.vers, addr)
+}
+
+// requestVersion sends the version handshake, 0 is returned if the server doesn't support it.
+func requestVersion(c net.Conn, vs []uint32) (uint32, error) {
+	c.SetDeadline(time.Now().Add(RequestTimeout))
+	defer c.SetDeadline(time.Time{})
+
+	r, err := MakeRequest(versionPath, &pp.VersionReq{Versions: vs})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := Write(c, r); err != nil {
+		return 0, err
+	}
+
+	rsp := Response{}
+	if err := Read(c, &rsp); err != nil {
+		return 0, err
+	}
+
+	res := pp.VersionRes{}
+	if err := json.NewDecoder(rsp.Body).Decode(&res); err != nil {
+		return 0, err
+	}
+
+	if !res.Result.GetSuccess() {
+		if res.Result.GetErrcode() == int32(pp.ErrCode_ServerError) {
+			// no handler for the path in old servers.
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%v", res.Result.GetReason())
+	}
+
+	ver := res.GetVersion()
+	if !supported(ver) {
+		return 0, fmt.Errorf("server chose unsupported version %d", ver)
+	}
+	return ver, nil
+}
@@ -0,0 +1,207 @@
+package sknet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(strings.Replace(s, " ", "", -1))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// TestSealVector checks the aead with the vector in RFC 8439 section 2.8.2.
+func TestSealVector(t *testing.T) {
+	key := mustDecodeHex("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	nonce := mustDecodeHex("070000004041424344454647")
+	ad := mustDecodeHex("50515253c0c1c2c3c4c5c6c7")
+	plain := []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+	expect := mustDecodeHex("d31a8d34648e60db7b86afbc53ef7ec2 a4aded51296e08fea9e2b5a736ee62d6 3dbea45e8ca9671282fafb69da92728b" +
+		"1a71de0a9e060b2905d6a5b67ecd3b36 92ddbd7f2d778b8c9803aee328091b58 fab324e4fad675945585808b4831d7bc" +
+		"3ff4def08e4b7a9de576d26586cec64b 6116 1ae10b594f09e26a7e902ecbd0600691")
+
+	d, err := seal(key, nonce, ad, plain)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, d)
+
+	p, err := open(key, nonce, ad, d)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, p)
+
+	// the data and the associated data are authenticated.
+	d[0] ^= 1
+	_, err = open(key, nonce, ad, d)
+	assert.NotNil(t, err)
+	d[0] ^= 1
+	_, err = open(key, nonce, []byte("other"), d)
+	assert.NotNil(t, err)
+}
+
+// TestFrameVector checks the frame of Version2 request with fixed keys and nonce.
+func TestFrameVector(t *testing.T) {
+	cp, cs := cipher.GenerateDeterministicKeyPair([]byte("client"))
+	sp, ss := cipher.GenerateDeterministicKeyPair([]byte("server"))
+	key, err := sharedKey(sp.Hex(), cs.Hex())
+	assert.Nil(t, err)
+
+	nonce := make([]byte, 12)
+	path := "/get/balance"
+	payload := []byte(`{"coin_type":"bitcoin","req_nonce":"00","req_time":0}`)
+	data, err := seal(key, nonce, []byte(path), payload)
+	assert.Nil(t, err)
+	assert.Equal(t, sealedVector, hex.EncodeToString(data))
+
+	r, err := MakeRequest(path, &pp.EncryptReq{
+		Pubkey:      pp.PtrString(cp.Hex()),
+		Nonce:       nonce,
+		Encryptdata: data,
+	})
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteVersion(&buf, Version2, r))
+	assert.Equal(t, frameHeadVector, hex.EncodeToString(buf.Bytes()[:8]))
+	assert.Equal(t, framePayloadVector, string(buf.Bytes()[8:]))
+
+	// the server reads the version and decrypts the payload with its seckey.
+	req := Request{}
+	assert.Nil(t, Read(&buf, &req))
+	assert.Equal(t, Version2, req.Version)
+	assert.Equal(t, path, req.GetPath())
+
+	d, err := decryptData(req.Version, req.GetPath(), data, nonce, cp.Hex(), ss.Hex())
+	assert.Nil(t, err)
+	assert.Equal(t, payload, d)
+
+	// the payload can't be sent to another path.
+	_, err = decryptData(req.Version, "/withdrawl", data, nonce, cp.Hex(), ss.Hex())
+	assert.NotNil(t, err)
+
+	// the head of Version3 has the request id.
+	buf.Reset()
+	assert.Nil(t, writeFrame(&buf, Version3, 7, r))
+	assert.Equal(t, "000000030000000700000141", hex.EncodeToString(buf.Bytes()[:12]))
+	assert.Equal(t, framePayloadVector, string(buf.Bytes()[12:]))
+	req = Request{}
+	assert.Nil(t, Read(&buf, &req))
+	assert.Equal(t, Version3, req.Version)
+	assert.Equal(t, uint32(7), req.ID)
+
+	// unsupported version.
+	buf.Reset()
+	assert.Nil(t, WriteVersion(&buf, Version+1, r))
+	assert.NotNil(t, Read(&buf, &req))
+}
+
+// The vectors of TestFrameVector, the head is the version and the length of the payload, the data
+// of the payload is the json of the EncryptReq, whose encryptdata is the sealed data.
+var (
+	sealedVector = "cf0e30e75728e2e9940c1efeeb44727debb121dcef51e138192964d3b181454c5113ca6cf03e3bf4d1e20db3fedcad9" +
+		"1371b3c5d046b750bc29a7191a507b5a7114e841c2e"
+	frameHeadVector    = "0000000200000141"
+	framePayloadVector = `{"path":"/get/balance","data":"eyJwdWJrZXkiOiIwMjMyNTc1NzVmOTg2MDJiZTg4MjQ4ZGI2YjcwNmU4MDEwZTBhN2ZiN2YyNzI5NTY5Y2RkZDhiYTZiZDE3OWU3NzMiLCJub25jZSI6IkFBQUFBQUFBQUFBQUFBQUEiLCJlbmNyeXB0ZGF0YSI6Inp3NHc1MWNvNHVtVURCNys2MFJ5ZmV1eElkenZVZUU0R1NsazA3R0JSVXhSRThwczhENDc5TkhpRGJQKzNLMlJOeHM4WFFScmRRdkNtbkdScFFlMXB4Rk9oQnd1In0="}`
+)
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, Version3, negotiate([]uint32{1, 2, 3, 4}))
+	assert.Equal(t, Version2, negotiate([]uint32{1, 2}))
+	assert.Equal(t, Version1, negotiate([]uint32{1}))
+	assert.Equal(t, uint32(0), negotiate([]uint32{0, 4}))
+
+	defer func(v uint32) { MinVersion = v }(MinVersion)
+	MinVersion = Version2
+	assert.Equal(t, uint32(0), negotiate([]uint32{1}))
+}
+
+func TestVersionHandshake(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	defer SetPubkey(gPubkey)
+	SetPubkey(pk.Hex())
+
+	quit := make(chan bool)
+	defer close(quit)
+	e := New(sk.Hex(), quit)
+	e.Register("/echo", func(c *Context) error {
+		var req struct {
+			A string `json:"a"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			return err
+		}
+		return c.SendJSON(&req)
+	})
+	addr, stop := startTestEngine(t, e)
+	defer stop()
+	defer forgetVersion(addr)
+
+	res := map[string]string{}
+	assert.Nil(t, EncryGet(addr, "/echo", map[string]string{"a": "b"}, &res))
+	assert.Equal(t, "b", res["a"])
+	assert.Equal(t, Version, versions.vers[addr])
+
+	// the old clients send Version1 requests without handshake.
+	encReq, err := encrypt(Version1, "/echo", map[string]string{"a": "c"}, gPubkey, gSeckey)
+	assert.Nil(t, err)
+	resp, err := Get(addr, "/echo", encReq)
+	assert.Nil(t, err)
+	assert.Equal(t, Version1, resp.Version)
+	assert.Nil(t, decrypt(resp.Version, "/echo", resp.Body, gPubkey, gSeckey, &res))
+	assert.Equal(t, "c", res["a"])
+
+	// the version can't be downgraded after handshake.
+	c, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	defer c.Close()
+	ver, err := requestVersion(c, []uint32{Version1, Version2})
+	assert.Nil(t, err)
+	assert.Equal(t, Version2, ver)
+	encReq, err = encrypt(Version1, "/echo", map[string]string{"a": "d"}, gPubkey, gSeckey)
+	assert.Nil(t, err)
+	resp, err = send(c, Version1, "/echo", encReq)
+	assert.Nil(t, err)
+	assert.NotNil(t, decrypt(resp.Version, "/echo", resp.Body, gPubkey, gSeckey, &res))
+}
+
+// TestVersionFallback checks the client falls back to Version1 with the old servers,
+// which close the connection of unknown path.
+func TestVersionFallback(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			r := Request{}
+			if err := Read(c, &r); err == nil && r.GetPath() == versionPath {
+				Write(c, pp.MakeErrResWithCode(pp.ErrCode_ServerError))
+			}
+			c.Close()
+		}
+	}()
+
+	addr := l.Addr().String()
+	defer forgetVersion(addr)
+	c, ver, err := dial(addr)
+	assert.Nil(t, err)
+	c.Close()
+	assert.Equal(t, Version1, ver)
+
+	// the version is cached.
+	c, ver, err = dial(addr)
+	assert.Nil(t, err)
+	c.Close()
+	assert.Equal(t, Version1, ver)
+}
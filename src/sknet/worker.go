@@ -1,9 +1,12 @@
 package sknet
 
 import (
+	"bufio"
+	"io"
 	"net"
 	"runtime/debug"
 	"strings"
+	"sync"
 
 	"github.com/skycoin/skycoin-exchange/src/pp"
 )
@@ -19,8 +22,8 @@ func (wk *Worker) Start(quit chan bool) {
 	go func() {
 		for {
 			select {
-			case c := <-wk.Enge.connPool:
-				process(wk.ID, c, wk.Enge)
+			case sc := <-wk.Enge.connPool:
+				process(wk.ID, sc, wk.Enge)
 			case <-quit:
 				return
 			}
@@ -29,11 +32,17 @@ func (wk *Worker) Start(quit chan bool) {
 }
 
 // process handle the incoming connection, will read request from conn, setup the middle,
-// and dispatch the request.
-func process(id int, c net.Conn, engine *Engine) {
+// and dispatch the request. The requests before Version3 are handled one by one, while the
+// requests of Version3 are handled concurrently, at most maxInFlight at a time. The connection
+// is closed once it's idle for IdleTimeout or the engine shuts down, after the requests in
+// flight are replied.
+func process(id int, sc *serverConn, engine *Engine) {
 	logger.Debug("[%d] working", id)
-	r := &Request{}
-	w := &Response{c: c}
+	c := sc.c
+	br := bufio.NewReader(c)
+	w := newResponse(sc, engine.opts.WriteTimeout)
+	inFlight := make(chan struct{}, maxInFlight)
+	var wg sync.WaitGroup
 
 	defer func() {
 		// catch panic
@@ -42,46 +51,134 @@ func process(id int, c net.Conn, engine *Engine) {
 			debug.PrintStack()
 		}
 
+		wg.Wait()
 		c.Close()
+		close(w.done)
+		engine.untrack(sc)
 		logger.Debug("[%d] worker done", id)
 	}()
 
-	var err error
-	var context Context
+	var connVer uint32 // the negotiated version of the connection.
 	for {
-		r.Reset()
-		context.Reset()
-		context.Resp = w
-		if err = Read(c, r); err != nil {
-			if err.Error() != "EOF" {
+		if !waitRequest(engine, sc, br, inFlight) {
+			return
+		}
+
+		engine.setBusy(sc)
+		r := &Request{}
+		if err := Read(br, r); err != nil {
+			if err != io.EOF {
 				logger.Error("%v", err)
-				context.Error(pp.MakeErrRes(err))
+				w.SendJSON(pp.MakeErrRes(err))
 			}
 			return
 		}
 
-		context.Request = r
-
-		// check if the path belongs to group.
-		hds, find := engine.findGroupHandlers(r.GetPath())
-		if find {
-			context.handlers = append(engine.handlers, hds...)
-		} else {
-			if h, ok := engine.handlerFunc[r.GetPath()]; ok {
-				context.handlers = append(engine.handlers, h)
-			} else {
-				logger.Error("no handler for path: %s", r.GetPath())
-				res := pp.MakeErrResWithCode(pp.ErrCode_ServerError)
-				context.Error(res)
+		context := &Context{
+			Request: r,
+			Resp:    w.reply(r),
+			Data:    make(map[string]interface{}),
+		}
+
+		switch r.GetPath() {
+		case versionPath:
+			var err error
+			if connVer, err = handshake(context); err != nil || connVer == 0 {
 				return
 			}
+			continue
+		case pingPath:
+			if err := context.Error(&pp.EmptyRes{Result: pp.MakeResultWithCode(pp.ErrCode_Success)}); err != nil {
+				return
+			}
+			continue
 		}
 
-		if err := context.handlers[0](&context); err != nil {
-			logger.Error(err.Error())
+		// the version can't be downgraded once negotiated.
+		if connVer != 0 && r.Version != connVer {
+			logger.Error("version %d request on the connection of version %d", r.Version, connVer)
+			context.Error(pp.MakeErrResWithCode(pp.ErrCode_WrongRequest))
 			return
 		}
+
+		handlers, ok := engine.route(r.GetPath())
+		if !ok {
+			logger.Error("no handler for path: %s", r.GetPath())
+			res := pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+			context.Error(res)
+			return
+		}
+		context.handlers = handlers
+
+		if r.Version < Version3 {
+			if err := context.handlers[0](context); err != nil {
+				logger.Error(err.Error())
+				return
+			}
+			continue
+		}
+
+		// stop reading once too many requests are in flight.
+		inFlight <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Critical("%s", r)
+					debug.PrintStack()
+					c.Close()
+				}
+				<-inFlight
+				wg.Done()
+			}()
+
+			if err := context.handlers[0](context); err != nil {
+				logger.Error(err.Error())
+				c.Close()
+			}
+		}()
+	}
+}
+
+// waitRequest waits for the first byte of the next request, false is returned if the connection
+// is closed, idle for IdleTimeout, or the engine is shutting down. The connection is not idle
+// while its requests are in flight.
+func waitRequest(engine *Engine, sc *serverConn, br *bufio.Reader, inFlight chan struct{}) bool {
+	for {
+		if !engine.setIdle(sc) {
+			return false
+		}
+
+		_, err := br.Peek(1)
+		if err == nil {
+			return true
+		}
+
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			if len(inFlight) > 0 && !engine.isClosing() {
+				continue
+			}
+			logger.Debug("close idle connection %s", sc.c.RemoteAddr())
+		} else if err != io.EOF {
+			logger.Debug("read %s failed: %v", sc.c.RemoteAddr(), err)
+		}
+		return false
+	}
+}
+
+// route returns the middlewares and the handler of the path.
+func (engine *Engine) route(path string) ([]HandlerFunc, bool) {
+	handlers := append([]HandlerFunc{}, engine.handlers...)
+
+	// check if the path belongs to group.
+	if hds, find := engine.findGroupHandlers(path); find {
+		return append(handlers, hds...), true
+	}
+
+	if h, ok := engine.handlerFunc[path]; ok {
+		return append(handlers, h), true
 	}
+	return nil, false
 }
 
 // findGroupHandlers find group of specific path.
@@ -92,7 +189,7 @@ func (engine *Engine) findGroupHandlers(path string) (handlers []HandlerFunc, fi
 			if !ok {
 				return
 			}
-			handlers = append(gp.preHandlers, h)
+			handlers = append(append([]HandlerFunc{}, gp.preHandlers...), h)
 			find = true
 			break
 		}
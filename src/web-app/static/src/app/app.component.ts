@@ -18,6 +18,7 @@ declare var _: any;
 export class AppComponent implements OnInit {
     bidList: Array<any>;
     askList: Array<any>;
+    ticker:any;
     depositList: Array<any>;
     accountList: Array<any>;
     eventList: Array<any>;
@@ -40,6 +41,7 @@ export class AppComponent implements OnInit {
     ngOnInit() {
         this.bidList = [];
         this.askList = [];
+        this.ticker = {};
         this.depositList = [];
         this.accountList = [];
         this.eventList = [];
@@ -84,8 +86,8 @@ export class AppComponent implements OnInit {
                 }
               }
               this.getWalletBalance();
-              this.loadBidList();
-              this.loadAskList();
+              this.loadDepth();
+              this.loadTicker();
               this.getBalance();
               this.getDepositList();
               this.getEventList();
@@ -98,8 +100,8 @@ export class AppComponent implements OnInit {
                   if (data.result.success) {
                     this.pubkey = data.pubkey;
                     this.wallets = [];
-                    this.loadBidList();
-                    this.loadAskList();
+                    this.loadDepth();
+                    this.loadTicker();
                     this.getBalance();
                     this.getDepositList();
                     this.getEventList();
@@ -112,35 +114,35 @@ export class AppComponent implements OnInit {
           }, err => console.log("Error on load outputs: " + err), () => console.log('Connection load done'));
     }
 
-    loadBidList() {
+    loadDepth() {
         var self = this;
         var headers = new Headers();
         headers.append('Content-Type', 'application/x-www-form-urlencoded');
-        var url = '/api/v1/orders/bid?coin_pair=bitcoin/skycoin&pubkey=' + this.pubkey + '&start=1&end=10';
+        var url = '/api/v1/depth?coin_pair=bitcoin/skycoin&levels=10';
         this.http.get(url, { headers: headers })
             .map((res) => res.json())
             .subscribe(data => {
-              console.log("get bid list", url, data);
+              console.log("get depth", url, data);
               if (data.result.success) {
-                self.bidList = data.orders;
+                self.bidList = data.bids || [];
+                self.askList = data.asks || [];
               } else {
                 return;
               }
-
             }, err => console.log("Error on load outputs: " + err), () => console.log('Connection load done'));
     }
 
-    loadAskList() {
+    loadTicker() {
         var self = this;
         var headers = new Headers();
         headers.append('Content-Type', 'application/x-www-form-urlencoded');
-        var url = '/api/v1/orders/ask?coin_pair=bitcoin/skycoin&pubkey=' + this.pubkey + '&start=1&end=10';
+        var url = '/api/v1/ticker?coin_pair=bitcoin/skycoin';
         this.http.get(url, { headers: headers })
             .map((res) => res.json())
             .subscribe(data => {
-              console.log("get ask list", url, data);
+              console.log("get ticker", url, data);
               if (data.result.success) {
-                self.askList = data.orders;
+                self.ticker = data;
               } else {
                 return;
               }
@@ -270,11 +272,8 @@ export class AppComponent implements OnInit {
           .subscribe(data => {
           console.log("create order", data);
             if (data.result.success) {
-              if (type === 1) {
-                self.loadBidList();
-              } else {
-                self.loadAskList();
-              }
+              self.loadDepth();
+              self.loadTicker();
             } else {
               alert(data.result.reason);
             }